@@ -3,16 +3,19 @@ package app
 import (
 	"caviar/internal/config"
 	"caviar/internal/controller/rest"
+	"caviar/internal/models"
 	"caviar/internal/service"
 	"caviar/internal/storage"
+	minio_db "caviar/pkg/db/minio"
 	"caviar/pkg/db/pgsql"
+	"caviar/pkg/payment"
+	"caviar/pkg/sms"
 	"caviar/pkg/telegram"
 	"context"
 	"log"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 	"go.uber.org/zap"
 )
 
@@ -32,39 +35,167 @@ func MustRun(ctx context.Context) {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	minioClient, err := minio.New(cfg.Minio.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.Minio.AccessKeyID, cfg.Minio.SecretAccessKey, ""),
-		Secure: cfg.Minio.UseSSL,
-	})
+	minioCtx, cancelMinio := context.WithTimeout(ctx, cfg.Minio.StartupTimeout)
+	objectStorage, err := minio_db.NewMinio(minioCtx, cfg.Minio)
+	cancelMinio()
 	if err != nil {
-		log.Fatalf("Failed to connect to minio: %v", err)
+		log.Fatalf("Failed to set up minio: %v", err)
 	}
 
 	userStorage := storage.NewUserStorage(gormClient)
 
+	exchangeRateStorage := storage.NewExchangeRateStorage(gormClient)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateStorage, logger)
+
 	productStorage := storage.NewProductStorage(gormClient)
-	productService := service.NewProductService(productStorage, minioClient, logger)
+	productService := service.NewProductService(
+		productStorage,
+		objectStorage,
+		service.ImageConfig{
+			MaxSize:       cfg.Minio.MaxImageSize,
+			PublicBaseURL: cfg.Minio.PublicBaseURL,
+			URLExpiry:     cfg.Minio.PresignExpiry,
+			Quality:       cfg.Minio.ImageQuality,
+		},
+		exchangeRateStorage,
+		service.PricingConfig{
+			BaseRegion: cfg.Currency.BaseRegion,
+			Regions:    cfg.Currency.Regions,
+		},
+		logger,
+	)
 
-	telegramService, err := telegram.NewService(cfg.Telegram.Token, userStorage, logger)
+	var telegramWebhook *telegram.WebhookConfig
+	if cfg.Telegram.Mode == "webhook" {
+		if cfg.Telegram.WebhookPath == "" {
+			log.Fatalf("TELEGRAM_WEBHOOK_PATH is required in webhook mode")
+		}
+		telegramWebhook = &telegram.WebhookConfig{
+			PublicURL:   strings.TrimRight(cfg.Telegram.WebhookBaseURL, "/") + "/telegram/webhook/" + cfg.Telegram.WebhookPath,
+			SecretToken: cfg.Telegram.WebhookSecretToken,
+			Register:    cfg.Telegram.WebhookRegister,
+		}
+	}
+
+	telegramService, err := telegram.NewService(
+		cfg.Telegram.Token,
+		telegram.LimiterConfig{
+			GlobalRate:   cfg.Telegram.GlobalRate,
+			GlobalBurst:  cfg.Telegram.GlobalBurst,
+			PerChatRate:  cfg.Telegram.PerChatRate,
+			PerChatBurst: cfg.Telegram.PerChatBurst,
+			MaxRetries:   cfg.Telegram.MaxRetries,
+		},
+		telegramWebhook,
+		userStorage,
+		logger,
+	)
 	if err != nil {
 		log.Fatalf("Failed to create telegram service: %v", err)
 	}
 
+	smsClient, err := sms.New(cfg.SMS, logger)
+	if err != nil {
+		log.Fatalf("Failed to create sms client: %v", err)
+	}
+
+	templateStorage := storage.NewTemplateStorage(gormClient)
+	templateService := service.NewTemplateService(templateStorage, cfg.Notification.DefaultLocale, logger)
+
 	// Create notification service
-	notificationService := service.NewNotificationService(userStorage, telegramService, logger)
+	notificationService := service.NewNotificationService(userStorage, telegramService, smsClient, templateService, logger)
+	if cfg.SMS.Enabled {
+		notificationService.SetEnabledChannels([]service.NotificationChannel{
+			service.ChannelTelegram,
+			service.ChannelSMS,
+		})
+	}
+
+	stockAlertService := service.NewStockAlertService(productStorage, notificationService, cfg.Catalog.ExpiryAlertDays, logger)
+
+	warehouseStorage := storage.NewWarehouseStorage(gormClient)
+	warehouseService := service.NewWarehouseService(warehouseStorage, logger)
+
+	promoStorage := storage.NewPromoStorage(gormClient)
+	promoService := service.NewPromoService(promoStorage, logger)
+
+	packaging := models.Packaging{
+		BoxWeight:       cfg.Shipping.BoxWeight,
+		IcePackWeight:   cfg.Shipping.IcePackWeight,
+		IcePackPerGrams: cfg.Shipping.IcePackPerGrams,
+	}
+	shippingStorage := storage.NewShippingStorage(gormClient)
+	shippingService := service.NewShippingService(shippingStorage, productStorage, packaging, logger)
+
+	taxStorage := storage.NewTaxStorage(gormClient)
+	taxService := service.NewTaxService(taxStorage, logger)
 
 	orderStorage := storage.NewOrderStorage(gormClient)
-	orderService := service.NewOrderService(orderStorage, productStorage, notificationService, logger)
+	orderService := service.NewOrderService(
+		orderStorage,
+		productStorage,
+		warehouseStorage,
+		promoStorage,
+		shippingStorage,
+		taxStorage,
+		exchangeRateStorage,
+		notificationService,
+		service.OrderHoldConfig{
+			PostOffice:   cfg.Order.HoldPostOffice,
+			Courier:      cfg.Order.HoldCourier,
+			Address:      cfg.Order.HoldAddress,
+			BankTransfer: cfg.Order.HoldBankTransfer,
+		},
+		models.CODFees{
+			Fixed:   cfg.Order.CODFeeFixed,
+			Percent: cfg.Order.CODFeePercent,
+		},
+		packaging,
+		cfg.Currency.Reporting,
+		logger,
+	)
+
+	paymentProvider, err := payment.New(cfg.Payment, cfg.IsProd)
+	if err != nil {
+		log.Fatalf("Failed to create payment provider: %v", err)
+	}
+	paymentStorage := storage.NewPaymentStorage(gormClient)
+	paymentService := service.NewPaymentService(
+		paymentStorage,
+		orderStorage,
+		paymentProvider,
+		service.PaymentConfig{
+			ResultURL:       cfg.Payment.ResultURL,
+			CallbackBaseURL: cfg.Payment.CallbackBaseURL,
+			CheckoutHold:    cfg.Order.HoldCheckout,
+		},
+		notificationService,
+		logger,
+	)
 
 	handler := rest.NewHandler(
 		cfg.Server.Port, 
 		productService,
 		orderService,
+		templateService,
+		warehouseService,
+		paymentService,
+		promoService,
+		shippingService,
+		taxService,
+		exchangeRateService,
 		logger,
 		cfg.IsProd,
 	)
 
+	if h := telegramService.WebhookHandler(); h != nil {
+		handler.SetTelegramWebhook(cfg.Telegram.WebhookPath, h)
+	}
+
 	go telegramService.Start(ctx)
+	go productService.RunPublishScheduler(ctx, cfg.Catalog.PublishCheckInterval)
+	go stockAlertService.Run(ctx, cfg.Catalog.StockAlertInterval)
+	go orderService.RunHoldSweeper(ctx, cfg.Order.HoldSweepInterval)
 	
 	handler.RegisterAndRun(gin.Default())
 }
\ No newline at end of file
@@ -13,6 +13,13 @@ type Config struct {
 	HTTP            HTTP            `envPrefix:"HTTP_"`
 	RateLimiter     RateLimiter     `envPrefix:"RATE_LIMITER_"`
 	Telegram        Telegram        `envPrefix:"TELEGRAM_"`
+	SMS             SMS             `envPrefix:"SMS_"`
+	Notification    Notification    `envPrefix:"NOTIFICATION_"`
+	Catalog         Catalog         `envPrefix:"CATALOG_"`
+	Order           Order           `envPrefix:"ORDER_"`
+	Payment         Payment         `envPrefix:"PAYMENT_"`
+	Shipping        Shipping        `envPrefix:"SHIPPING_"`
+	Currency        Currency        `envPrefix:"CURRENCY_"`
 	IsProd          bool            `env:"IS_PROD" envDefault:"false"`
 }
 
@@ -59,8 +66,114 @@ type Minio struct {
 	SecretAccessKey string `env:"SECRET_ACCESS_KEY,required"`
 	BucketName      string `env:"BUCKET_NAME,required"`
 	UseSSL          bool   `env:"USE_SSL" envDefault:"false"`
+
+	MaxImageSize  int64         `env:"MAX_IMAGE_SIZE" envDefault:"20971520"`
+	PublicBaseURL string        `env:"PUBLIC_BASE_URL"`
+	PresignExpiry time.Duration `env:"PRESIGN_EXPIRY" envDefault:"1h"`
+	ImageQuality  int           `env:"IMAGE_QUALITY" envDefault:"80"`
+
+	PublicRead            bool          `env:"PUBLIC_READ" envDefault:"false"`
+	PublicReadPrefix      string        `env:"PUBLIC_READ_PREFIX" envDefault:"products/"`
+	AbortUploadsAfterDays int           `env:"ABORT_UPLOADS_AFTER_DAYS" envDefault:"1"`
+	StartupTimeout        time.Duration `env:"STARTUP_TIMEOUT" envDefault:"15s"`
 }
 
 type Telegram struct {
-	Token string `env:"TOKEN,required"`
+	Token        string  `env:"TOKEN,required"`
+	GlobalRate   float64 `env:"GLOBAL_RATE" envDefault:"30"`
+	GlobalBurst  int     `env:"GLOBAL_BURST" envDefault:"30"`
+	PerChatRate  float64 `env:"PER_CHAT_RATE" envDefault:"1"`
+	PerChatBurst int     `env:"PER_CHAT_BURST" envDefault:"3"`
+	MaxRetries   int     `env:"MAX_RETRIES" envDefault:"3"`
+
+	// Mode is "polling" or "webhook". In webhook mode updates are served
+	// on the HTTP server at /telegram/webhook/{WebhookPath}.
+	Mode               string `env:"MODE" envDefault:"polling"`
+	WebhookBaseURL     string `env:"WEBHOOK_BASE_URL"`
+	WebhookPath        string `env:"WEBHOOK_PATH"`
+	WebhookSecretToken string `env:"WEBHOOK_SECRET_TOKEN"`
+	WebhookRegister    bool   `env:"WEBHOOK_REGISTER" envDefault:"false"`
+}
+
+type SMS struct {
+	Enabled       bool          `env:"ENABLED" envDefault:"false"`
+	Provider      string        `env:"PROVIDER" envDefault:"log"`
+	APIURL        string        `env:"API_URL" envDefault:"https://api.turbosms.ua"`
+	Token         string        `env:"TOKEN"`
+	Sender        string        `env:"SENDER"`
+	LogPath       string        `env:"LOG_PATH"`
+	Transliterate bool          `env:"TRANSLITERATE" envDefault:"false"`
+	MaxSegments   int           `env:"MAX_SEGMENTS" envDefault:"2"`
+	Timeout       time.Duration `env:"TIMEOUT" envDefault:"10s"`
+}
+
+type Notification struct {
+	DefaultLocale string `env:"DEFAULT_LOCALE" envDefault:"uk"`
+}
+
+type Catalog struct {
+	// How often scheduled products are checked for publication; 0
+	// disables scheduled publishing
+	PublishCheckInterval time.Duration `env:"PUBLISH_CHECK_INTERVAL" envDefault:"1m"`
+	// How often stock is checked for low-stock and expiry alerts; 0
+	// disables the alerts
+	StockAlertInterval time.Duration `env:"STOCK_ALERT_INTERVAL" envDefault:"15m"`
+	// How many days before expiry a lot is reported
+	ExpiryAlertDays int `env:"EXPIRY_ALERT_DAYS" envDefault:"14"`
+}
+
+type Order struct {
+	// How long stock is held for an unpaid pending order, per delivery
+	// type; 0 holds it until the order is confirmed or cancelled
+	HoldPostOffice time.Duration `env:"HOLD_POST_OFFICE" envDefault:"72h"`
+	HoldCourier    time.Duration `env:"HOLD_COURIER" envDefault:"24h"`
+	HoldAddress    time.Duration `env:"HOLD_ADDRESS" envDefault:"48h"`
+	// How long a bank transfer order waits for the invoice to be paid
+	HoldBankTransfer time.Duration `env:"HOLD_BANK_TRANSFER" envDefault:"120h"`
+	// How long a held card order is kept at least once its customer opens
+	// the payment page, so the hold does not run out mid-payment
+	HoldCheckout time.Duration `env:"HOLD_CHECKOUT" envDefault:"1h"`
+	// Cash on delivery fee: a fixed amount per currency plus a percentage
+	// of the total, e.g. "UAH:20,EUR:1". Cash on delivery is only offered
+	// in the currencies listed
+	CODFeeFixed   map[string]int `env:"COD_FEE_FIXED" envDefault:"UAH:20"`
+	CODFeePercent int            `env:"COD_FEE_PERCENT" envDefault:"2"`
+	// How often expired holds are released; 0 disables the sweeper
+	HoldSweepInterval time.Duration `env:"HOLD_SWEEP_INTERVAL" envDefault:"5m"`
+}
+
+// Shipping describes how parcels are packed, for their weight. Masses
+// are in grams.
+type Shipping struct {
+	// Weight of the box and its insulation
+	BoxWeight int `env:"BOX_WEIGHT" envDefault:"150"`
+	// Weight of one ice pack, and how many grams of goods one keeps cold
+	IcePackWeight   int `env:"ICE_PACK_WEIGHT" envDefault:"200"`
+	IcePackPerGrams int `env:"ICE_PACK_PER_GRAMS" envDefault:"250"`
+}
+
+type Currency struct {
+	// Currency statistics convert revenue to
+	Reporting string `env:"REPORTING" envDefault:"UAH"`
+	// Variant prices of Regions without a price of their own are
+	// converted from the price of BaseRegion, e.g. "EU:EUR,US:USD"
+	BaseRegion string            `env:"BASE_REGION" envDefault:"UA"`
+	Regions    map[string]string `env:"REGIONS"`
+}
+
+type Payment struct {
+	// Provider is "liqpay" or "fake"; fake is refused in production
+	Provider string `env:"PROVIDER,required"`
+	// Where customers return after paying
+	ResultURL string `env:"RESULT_URL"`
+	// Public base URL of this API; callbacks are sent to
+	// {CallbackBaseURL}/api/v1/payments/callback/{provider}
+	CallbackBaseURL string `env:"CALLBACK_BASE_URL"`
+
+	LiqPayPublicKey  string `env:"LIQPAY_PUBLIC_KEY"`
+	LiqPayPrivateKey string `env:"LIQPAY_PRIVATE_KEY"`
+	LiqPaySandbox    bool   `env:"LIQPAY_SANDBOX" envDefault:"false"`
+
+	// Anyone with FakeSecret can mark orders paid
+	FakeSecret string `env:"FAKE_SECRET"`
 }
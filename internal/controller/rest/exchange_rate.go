@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+	"caviar/internal/types"
+	"caviar/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) initExchangeRateRoutes(api *gin.RouterGroup) {
+	rates := api.Group("/exchange-rates", h.AuthMiddleware())
+	rates.GET("", h.listExchangeRates)
+	rates.POST("", h.setExchangeRate)
+	rates.POST("/import", h.importExchangeRates)
+	rates.DELETE("/:id", h.deleteExchangeRate)
+}
+
+// ListExchangeRates godoc
+// @Summary List exchange rates
+// @Description List the dated exchange rates, latest first
+// @Tags exchange-rates
+// @Produce json
+// @Security BearerAuth
+// @Param currency query string false "Only rates with this currency as base or quote"
+// @Param effective_by query string false "Only rates effective on or before this date (YYYY-MM-DD)"
+// @Success 200 {array} dto.ExchangeRateResponseDTO "Exchange rates"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/exchange-rates [get]
+func (h *Handler) listExchangeRates(c *gin.Context) {
+	filter := &types.ExchangeRateFilter{
+		Currency: c.Query("currency"),
+	}
+	if effectiveBy := c.Query("effective_by"); effectiveBy != "" {
+		date, err := time.Parse(models.DateLayout, effectiveBy)
+		if err != nil {
+			h.handleError(c, apperror.New(apperror.CodeInvalidInput, "effective_by must be a date in YYYY-MM-DD format"))
+			return
+		}
+		filter.EffectiveBy = date
+	}
+
+	rates, err := h.exchangeRateService.List(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Exchange.ToResponseDTOs(rates))
+}
+
+// SetExchangeRate godoc
+// @Summary Set an exchange rate
+// @Description Set how many units of quote one unit of base buys from effective_on, today if omitted, until the pair's next rate. A rate already set for the pair on that day is replaced. The reverse pair is derived when it has no rate of its own.
+// @Tags exchange-rates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rate body dto.ExchangeRateCreateDTO true "Exchange rate"
+// @Success 201 {object} dto.ExchangeRateResponseDTO "Stored exchange rate"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/exchange-rates [post]
+func (h *Handler) setExchangeRate(c *gin.Context) {
+	var input dto.ExchangeRateCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	rate, err := h.exchangeRateService.Set(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Exchange.ToResponseDTO(rate), "Exchange rate set successfully")
+}
+
+// ImportExchangeRates godoc
+// @Summary Import exchange rates
+// @Description Import rates from a CSV file with a header row naming its date (or effective_on), base, quote and rate columns, e.g. "date,base,quote,rate" then "2026-01-05,EUR,UAH,44.1". Rates already set for a pair on a day are replaced. Nothing is imported if a row is invalid.
+// @Tags exchange-rates
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV file"
+// @Success 201 {object} dto.ExchangeRateImportResponseDTO "Imported exchange rates"
+// @Failure 400 {object} map[string]interface{} "Invalid file"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/exchange-rates/import [post]
+func (h *Handler) importExchangeRates(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.handleError(c, apperror.Wrap(err, apperror.CodeInvalidInput, "multipart field \"file\" is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.handleError(c, apperror.Wrap(err, apperror.CodeInvalidInput, "failed to open uploaded file"))
+		return
+	}
+	defer file.Close()
+
+	rates, err := h.exchangeRateService.Import(c.Request.Context(), file)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Exchange.ToImportResponseDTO(rates), "Exchange rates imported successfully")
+}
+
+// DeleteExchangeRate godoc
+// @Summary Remove an exchange rate
+// @Description Remove a rate; the pair's previous rate applies again from its date.
+// @Tags exchange-rates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exchange rate ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Exchange rate not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/exchange-rates/{id} [delete]
+func (h *Handler) deleteExchangeRate(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	if err := h.exchangeRateService.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleDeleted(c, "Exchange rate deleted successfully")
+}
@@ -3,6 +3,7 @@ package rest
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"time"
@@ -21,9 +22,30 @@ import (
 
 type ProductService interface {
 	Create(ctx context.Context, input *dto.ProductCreateDTO) error
+	GetByID(ctx context.Context, isAuthenticated bool, id string) (*models.Product, error)
+	GetBySlug(ctx context.Context, isAuthenticated bool, slug string) (*models.Product, error)
 	List(ctx context.Context, isAuthenticated bool, filter *types.ProductFilter) ([]*models.Product, error)
 	Update(ctx context.Context, input *dto.ProductUpdateDTO) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*models.Product, error)
+	LookupVariant(ctx context.Context, sku, barcode string) (*models.Product, *models.Variant, error)
+	MoveStock(ctx context.Context, productID, variantID string, input *dto.StockMovementCreateDTO) ([]*models.StockMovement, error)
+	ListStockMovements(ctx context.Context, productID string, filter *types.StockMovementFilter) ([]*models.StockMovement, int64, error)
+	ReconcileStock(ctx context.Context) ([]models.StockDiscrepancy, error)
+	ReceiveLot(ctx context.Context, productID, variantID string, input *dto.StockLotCreateDTO) (*models.StockLot, error)
+	ListLots(ctx context.Context, productID, variantID string, includeEmpty bool) ([]*models.StockLot, error)
+	ListExpiringLots(ctx context.Context, days int) ([]*models.StockLot, error)
+	TransferStock(ctx context.Context, productID, variantID string, input *dto.StockTransferCreateDTO) ([]*models.StockMovement, error)
+	CreateVariant(ctx context.Context, productID string, input *dto.VariantCreateDTO) (*models.Variant, error)
+	UpdateVariant(ctx context.Context, productID, variantID string, input *dto.VariantPatchDTO) (*models.Variant, error)
+	DeleteVariant(ctx context.Context, productID, variantID string) error
+	Publish(ctx context.Context, id string, publishAt *time.Time) (*models.Product, error)
+	Unpublish(ctx context.Context, id string) (*models.Product, error)
+	Archive(ctx context.Context, id string) (*models.Product, error)
+	UploadImage(ctx context.Context, productID string, file io.Reader) (*models.ProductImage, error)
+	ReorderImages(ctx context.Context, productID string, imageIDs []string) (models.ProductImages, error)
+	SetPrimaryImage(ctx context.Context, productID, imageID string) (models.ProductImages, error)
+	DeleteImage(ctx context.Context, productID, imageID string) error
 }
 
 type OrderService interface {
@@ -31,38 +53,121 @@ type OrderService interface {
 	GetByID(ctx context.Context, id string) (*models.Order, error)
 	GetByOrderNumber(ctx context.Context, orderNumber string) (*models.Order, error)
 	List(ctx context.Context, filter *types.OrderFilter) ([]*models.Order, int64, error)
-	UpdateStatus(ctx context.Context, id string, status models.OrderStatus) error
+	UpdateStatus(ctx context.Context, id string, input *dto.OrderStatusUpdateDTO) error
+	MarkPaid(ctx context.Context, id string) (*models.Order, error)
+	Refund(ctx context.Context, id string, input *dto.OrderRefundCreateDTO) (*models.Refund, error)
 	Delete(ctx context.Context, id string) error
 	GetStatistics(ctx context.Context) (map[string]any, error)
 }
 
+type TemplateService interface {
+	List(ctx context.Context) ([]*models.NotificationTemplate, error)
+	Upsert(ctx context.Context, input *dto.NotificationTemplateUpsertDTO) (*models.NotificationTemplate, error)
+	Delete(ctx context.Context, event, channel, locale string) error
+}
+
+type WarehouseService interface {
+	List(ctx context.Context) ([]*models.Warehouse, error)
+	Create(ctx context.Context, input *dto.WarehouseCreateDTO) (*models.Warehouse, error)
+	Update(ctx context.Context, id string, input *dto.WarehousePatchDTO) (*models.Warehouse, error)
+}
+
+type PaymentService interface {
+	StartCheckout(ctx context.Context, orderID string) (*models.Payment, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Payment, error)
+	HandleCallback(ctx context.Context, provider string, body []byte, header http.Header) error
+}
+
+type PromoService interface {
+	List(ctx context.Context) ([]*models.PromoCode, error)
+	Create(ctx context.Context, input *dto.PromoCodeCreateDTO) (*models.PromoCode, error)
+	Update(ctx context.Context, id string, input *dto.PromoCodePatchDTO) (*models.PromoCode, error)
+}
+
+type ShippingService interface {
+	ListZones(ctx context.Context) ([]*models.ShippingZone, error)
+	CreateZone(ctx context.Context, input *dto.ShippingZoneCreateDTO) (*models.ShippingZone, error)
+	UpdateZone(ctx context.Context, id string, input *dto.ShippingZonePatchDTO) (*models.ShippingZone, error)
+	ListRates(ctx context.Context, zoneID string) ([]*models.ShippingRate, error)
+	CreateRate(ctx context.Context, input *dto.ShippingRateCreateDTO) (*models.ShippingRate, error)
+	UpdateRate(ctx context.Context, id string, input *dto.ShippingRatePatchDTO) (*models.ShippingRate, error)
+	DeleteRate(ctx context.Context, id string) error
+	Quote(ctx context.Context, input *dto.ShippingQuoteRequestDTO) (*models.ShippingQuote, error)
+}
+
+type TaxService interface {
+	List(ctx context.Context) ([]*models.TaxRate, error)
+	Create(ctx context.Context, input *dto.TaxRateCreateDTO) (*models.TaxRate, error)
+	Update(ctx context.Context, id string, input *dto.TaxRatePatchDTO) (*models.TaxRate, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type ExchangeRateService interface {
+	List(ctx context.Context, filter *types.ExchangeRateFilter) ([]*models.ExchangeRate, error)
+	Set(ctx context.Context, input *dto.ExchangeRateCreateDTO) (*models.ExchangeRate, error)
+	Import(ctx context.Context, r io.Reader) ([]*models.ExchangeRate, error)
+	Delete(ctx context.Context, id string) error
+}
+
 type Handler struct {
-	port            string
-	authSecret      string
-	productService  ProductService
-	orderService    OrderService
-	logger          *zap.Logger
-	converter       *converter.Converter
-	isProd          bool
+	port                string
+	authSecret          string
+	productService      ProductService
+	orderService        OrderService
+	templateService     TemplateService
+	warehouseService    WarehouseService
+	paymentService      PaymentService
+	promoService        PromoService
+	shippingService     ShippingService
+	taxService          TaxService
+	exchangeRateService ExchangeRateService
+	logger              *zap.Logger
+	converter           *converter.Converter
+	isProd              bool
+
+	telegramWebhookPath    string
+	telegramWebhookHandler http.Handler
 }
 
 func NewHandler(
 	port string, 
 	productService ProductService,
 	orderService OrderService,
+	templateService TemplateService,
+	warehouseService WarehouseService,
+	paymentService PaymentService,
+	promoService PromoService,
+	shippingService ShippingService,
+	taxService TaxService,
+	exchangeRateService ExchangeRateService,
 	logger *zap.Logger,
 	isProd bool,
 ) *Handler {
 	return &Handler{
-		port:            port,
-		productService:  productService,
-		orderService:    orderService,
-		logger:          logger,
-		converter:       converter.NewConverter(),
-		isProd:          isProd,
+		port:                port,
+		productService:      productService,
+		orderService:        orderService,
+		templateService:     templateService,
+		warehouseService:    warehouseService,
+		paymentService:      paymentService,
+		promoService:        promoService,
+		shippingService:     shippingService,
+		taxService:          taxService,
+		exchangeRateService: exchangeRateService,
+		logger:              logger,
+		converter:           converter.NewConverter(),
+		isProd:              isProd,
 	}
 }
 
+// SetTelegramWebhook mounts the Telegram webhook handler at
+// /telegram/webhook/{path}. Telegram's secret token header is checked
+// by the handler itself.
+func (h *Handler) SetTelegramWebhook(path string, handler http.Handler) {
+	h.telegramWebhookPath = path
+	h.telegramWebhookHandler = handler
+}
+
 func (h *Handler) RegisterAndRun(r *gin.Engine) {
 	r.Use(h.LoggerMiddleware())
 	r.Use(h.ErrorMiddleware())
@@ -80,6 +185,18 @@ func (h *Handler) RegisterAndRun(r *gin.Engine) {
 	
 	h.initProductRoutes(api)
 	h.initOrderRoutes(api)
+	h.initTemplateRoutes(api)
+	h.initInventoryRoutes(api)
+	h.initWarehouseRoutes(api)
+	h.initPaymentRoutes(api)
+	h.initPromoRoutes(api)
+	h.initShippingRoutes(api)
+	h.initTaxRoutes(api)
+	h.initExchangeRateRoutes(api)
+
+	if h.telegramWebhookHandler != nil {
+		r.POST("/telegram/webhook/"+h.telegramWebhookPath, gin.WrapH(h.telegramWebhookHandler))
+	}
 
 	if err := r.Run(":" + h.port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
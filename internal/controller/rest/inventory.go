@@ -0,0 +1,305 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/types"
+	"caviar/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) initInventoryRoutes(api *gin.RouterGroup) {
+	inventory := api.Group("/inventory", h.AuthMiddleware())
+	inventory.GET("/reconciliation", h.reconcileStock)
+	inventory.GET("/lots/expiring", h.listExpiringLots)
+}
+
+// CreateStockMovement godoc
+// @Summary Record a stock movement
+// @Description Record a receipt (positive delta), write-off (negative delta) or adjustment for a variant. Stock never goes below zero. Write-offs and adjustments need a note. Send X-Actor to record who made the change. Without lot_id, stock coming in goes to the unlabelled lot of the warehouse (the default warehouse when warehouse_id is not set) and stock going out is taken from the lots expiring first, in the given warehouse or any, so one request may record a movement per lot.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param X-Actor header string false "Name of the staff member"
+// @Param movement body dto.StockMovementCreateDTO true "Stock movement"
+// @Success 201 {array} dto.StockMovementResponseDTO "Recorded movements with the resulting balance"
+// @Failure 400 {object} map[string]interface{} "Bad request or insufficient stock"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant, lot or warehouse not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants/{variantId}/stock-movements [post]
+func (h *Handler) createStockMovement(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	variantID, ok := h.getPathParam(c, "variantId", true)
+	if !ok {
+		return
+	}
+
+	var input dto.StockMovementCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	movements, err := h.productService.MoveStock(c.Request.Context(), id, variantID, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Inventory.ToMovementResponseDTOs(movements), "Stock movement recorded successfully")
+}
+
+// TransferStock godoc
+// @Summary Transfer stock between warehouses
+// @Description Move stock of a variant from one warehouse to another. Without lot_id the lots expiring first are moved. Each lot keeps its number and dates in the destination warehouse. One movement out and one in is recorded per lot, both with reason transfer.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param X-Actor header string false "Name of the staff member"
+// @Param transfer body dto.StockTransferCreateDTO true "Stock transfer"
+// @Success 201 {array} dto.StockMovementResponseDTO "Recorded movements"
+// @Failure 400 {object} map[string]interface{} "Bad request or insufficient stock"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant, lot or warehouse not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants/{variantId}/transfers [post]
+func (h *Handler) transferStock(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	variantID, ok := h.getPathParam(c, "variantId", true)
+	if !ok {
+		return
+	}
+
+	var input dto.StockTransferCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	movements, err := h.productService.TransferStock(c.Request.Context(), id, variantID, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Inventory.ToMovementResponseDTOs(movements), "Stock transferred successfully")
+}
+
+// ListStockMovements godoc
+// @Summary Stock movement history of a variant
+// @Description List the ledger entries of a variant, newest first
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param reason query string false "Filter by reason"
+// @Param warehouse_id query string false "Filter by warehouse"
+// @Param lot_id query string false "Filter by stock lot"
+// @Param from query string false "Created from (RFC3339)"
+// @Param to query string false "Created to (RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(50)
+// @Success 200 {object} dto.StockMovementListResponseDTO "Stock movements"
+// @Failure 400 {object} map[string]interface{} "Invalid filter"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants/{variantId}/stock-movements [get]
+func (h *Handler) listStockMovements(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	variantID, ok := h.getPathParam(c, "variantId", true)
+	if !ok {
+		return
+	}
+
+	filter := &types.StockMovementFilter{
+		VariantID:   variantID,
+		WarehouseID: c.Query("warehouse_id"),
+		LotID:       c.Query("lot_id"),
+		Reason:      c.Query("reason"),
+	}
+	for _, key := range []string{"warehouse_id", "lot_id"} {
+		if id := c.Query(key); id != "" {
+			if _, err := uuid.Parse(id); err != nil {
+				h.handleError(c, apperror.New(apperror.CodeInvalidInput, key+" must be a UUID"))
+				return
+			}
+		}
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			h.handleError(c, apperror.New(apperror.CodeInvalidInput, "from must be a time in RFC3339 format"))
+			return
+		}
+		filter.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			h.handleError(c, apperror.New(apperror.CodeInvalidInput, "to must be a time in RFC3339 format"))
+			return
+		}
+		filter.To = t
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	filter.Offset = (page - 1) * limit
+	filter.Limit = limit
+
+	movements, total, err := h.productService.ListStockMovements(c.Request.Context(), id, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Inventory.ToMovementListResponseDTO(movements, page, limit, total))
+}
+
+// ReconcileStock godoc
+// @Summary Reconcile stock with the ledger
+// @Description List variants whose stock differs from the sum of their stock movements. An empty list means the ledger is consistent.
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.StockDiscrepancyResponseDTO "Variants with discrepancies"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/inventory/reconciliation [get]
+func (h *Handler) reconcileStock(c *gin.Context) {
+	discrepancies, err := h.productService.ReconcileStock(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Inventory.ToDiscrepancyResponseDTOs(discrepancies))
+}
+
+// ReceiveLot godoc
+// @Summary Receive a lot
+// @Description Receive a lot (batch) of a variant with its packing and expiry date. The quantity is added to stock as a receipt. Orders take stock from the lots expiring first.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param X-Actor header string false "Name of the staff member"
+// @Param lot body dto.StockLotCreateDTO true "Lot"
+// @Success 201 {object} dto.StockLotResponseDTO "Received lot"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant not found"
+// @Failure 409 {object} map[string]interface{} "Lot number already used for this variant in the warehouse"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants/{variantId}/lots [post]
+func (h *Handler) receiveLot(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	variantID, ok := h.getPathParam(c, "variantId", true)
+	if !ok {
+		return
+	}
+
+	var input dto.StockLotCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	lot, err := h.productService.ReceiveLot(c.Request.Context(), id, variantID, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Inventory.ToLotResponseDTO(lot, time.Now()), "Lot received successfully")
+}
+
+// ListLots godoc
+// @Summary Lots of a variant
+// @Description List the lots of a variant with stock left, in the order they are allocated to orders (first expired, first out)
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param all query bool false "Include used-up lots"
+// @Success 200 {array} dto.StockLotResponseDTO "Lots"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants/{variantId}/lots [get]
+func (h *Handler) listLots(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	variantID, ok := h.getPathParam(c, "variantId", true)
+	if !ok {
+		return
+	}
+
+	lots, err := h.productService.ListLots(c.Request.Context(), id, variantID, c.Query("all") == "true")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Inventory.ToLotResponseDTOs(lots, time.Now()))
+}
+
+// ListExpiringLots godoc
+// @Summary Lots nearing expiry
+// @Description List lots with stock left that expire within the given number of days, including lots that have already expired, soonest first. Expired lots are not allocated to orders and should be written off.
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days ahead" default(14)
+// @Success 200 {array} dto.StockLotResponseDTO "Expiring lots"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/inventory/lots/expiring [get]
+func (h *Handler) listExpiringLots(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "14"))
+	if err != nil || days < 0 || days > 365 {
+		days = 14
+	}
+
+	lots, err := h.productService.ListExpiringLots(c.Request.Context(), days)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Inventory.ToLotResponseDTOs(lots, time.Now()))
+}
@@ -2,7 +2,11 @@ package rest
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"caviar/internal/models"
 	"caviar/pkg/apperror"
 
 	"github.com/gin-gonic/gin"
@@ -20,6 +24,11 @@ func (h *Handler) LoggerMiddleware() gin.HandlerFunc {
 	})
 }
 
+// authenticatedKey marks requests that carried a valid admin token.
+const authenticatedKey = "authenticated"
+
+const maxActorName = 50
+
 func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -29,34 +38,100 @@ func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if len(authHeader) < 7 || authHeader[:7] != "Bearer " {
-			h.handleError(c, apperror.New(apperror.CodeUnauthorized, "Invalid authorization format. Use 'Bearer <token>'"))
+		if err := h.authenticate(authHeader); err != nil {
+			h.handleError(c, err)
 			c.Abort()
 			return
 		}
 
-		token := authHeader[7:]
-		
-		// Debug logging for development
-		if !h.isProd {
-			h.logger.Debug("Auth validation",
-				zap.String("provided_token", token),
-				zap.String("expected_secret", h.authSecret),
-				zap.Bool("tokens_match", token == h.authSecret),
-				zap.Int("provided_length", len(token)),
-				zap.Int("expected_length", len(h.authSecret)))
+		h.markAuthenticated(c)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware lets anonymous requests through but rejects an
+// invalid token, so a misconfigured admin client does not silently see
+// the public view.
+func (h *Handler) OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
+			return
 		}
-		
-		if token != h.authSecret {
-			h.handleError(c, apperror.New(apperror.CodeUnauthorized, "Invalid authorization token"))
+
+		if err := h.authenticate(authHeader); err != nil {
+			h.handleError(c, err)
 			c.Abort()
 			return
 		}
 
+		h.markAuthenticated(c)
 		c.Next()
 	}
 }
 
+// markAuthenticated flags the request and records the actor for audit
+// trails. Staff share one token, so the optional X-Actor header is the
+// only way to tell them apart.
+func (h *Handler) markAuthenticated(c *gin.Context) {
+	c.Set(authenticatedKey, true)
+
+	actor := "admin"
+	if name := actorName(c.GetHeader("X-Actor")); name != "" {
+		actor += ":" + name
+	}
+	c.Request = c.Request.WithContext(models.ContextWithActor(c.Request.Context(), actor))
+}
+
+// actorName cleans up an X-Actor header for the audit trail: invalid
+// UTF-8 and control characters are dropped and the name is cut to
+// maxActorName characters, which keeps "admin:" plus the name within the
+// 64-character actor columns.
+func actorName(header string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(header, ""))
+	name = strings.TrimSpace(name)
+
+	if runes := []rune(name); len(runes) > maxActorName {
+		name = strings.TrimSpace(string(runes[:maxActorName]))
+	}
+	return name
+}
+
+func (h *Handler) authenticate(authHeader string) error {
+	if len(authHeader) < 7 || authHeader[:7] != "Bearer " {
+		return apperror.New(apperror.CodeUnauthorized, "Invalid authorization format. Use 'Bearer <token>'")
+	}
+
+	token := authHeader[7:]
+	
+	// Debug logging for development
+	if !h.isProd {
+		h.logger.Debug("Auth validation",
+			zap.String("provided_token", token),
+			zap.String("expected_secret", h.authSecret),
+			zap.Bool("tokens_match", token == h.authSecret),
+			zap.Int("provided_length", len(token)),
+			zap.Int("expected_length", len(h.authSecret)))
+	}
+	
+	if token != h.authSecret {
+		return apperror.New(apperror.CodeUnauthorized, "Invalid authorization token")
+	}
+
+	return nil
+}
+
+// isAuthenticated reports whether an auth middleware accepted the request.
+func isAuthenticated(c *gin.Context) bool {
+	return c.GetBool(authenticatedKey)
+}
+
 func (h *Handler) ErrorMiddleware() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
 		if err, ok := recovered.(error); ok {
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestActorName(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"plain", "olena", "olena"},
+		{"trimmed", "  olena \t", "olena"},
+		{"empty", "", ""},
+		{"only spaces", "   ", ""},
+		{"invalid UTF-8 dropped", "ole\xffna", "olena"},
+		{"control characters dropped", "ole\nna\x00", "olena"},
+		{"cut by characters", strings.Repeat("ї", 60), strings.Repeat("ї", maxActorName)},
+		{"no trailing space after the cut", strings.Repeat("a", maxActorName-1) + " b", strings.Repeat("a", maxActorName-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := actorName(tt.header)
+			if got != tt.want {
+				t.Errorf("actorName(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("actorName(%q) is not valid UTF-8", tt.header)
+			}
+		})
+	}
+}
@@ -3,10 +3,10 @@ package rest
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"caviar/internal/dto"
-	"caviar/internal/models"
 	"caviar/internal/types"
 
 	"github.com/gin-gonic/gin"
@@ -22,11 +22,13 @@ func (h *Handler) initOrderRoutes(api *gin.RouterGroup) {
 	ordersProtected.GET("/:id", h.getOrder)
 	ordersProtected.GET("/number/:orderNumber", h.getOrderByNumber)
 	ordersProtected.PUT("/:id/status", h.updateOrderStatus)
+	ordersProtected.POST("/:id/mark-paid", h.markOrderPaid)
+	ordersProtected.POST("/:id/refunds", h.refundOrder)
 	ordersProtected.DELETE("/:id", h.deleteOrder)
 }
 
 // @Summary Create a new order
-// @Description Create a new order with customer and delivery information
+// @Description Create a new order with customer and delivery information. The payment method decides how the order is paid: cod adds the cash on delivery fee to the total and is not available for courier delivery abroad, card is paid online via POST /orders/{id}/payments, and bank_transfer stays pending until staff mark it paid. An optional promoCode takes its discount off the items it applies to. Shipping is then charged by the rate of the delivery zone and parcel weight, see POST /shipping/quote, and the cash on delivery fee is added. Last, VAT at the delivery country's rate is carved out of the total, or added on top of it where prices exclude tax.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -122,6 +124,8 @@ func (h *Handler) getOrderByNumber(c *gin.Context) {
 // @Param status query string false "Filter by order status"
 // @Param customer_phone query string false "Filter by customer phone"
 // @Param country query string false "Filter by delivery country"
+// @Param lot_id query string false "Filter by stock lot the items were allocated from (for recalls)"
+// @Param warehouse_id query string false "Filter by fulfilling warehouse"
 // @Param created_from query string false "Filter by creation date from (RFC3339 format)"
 // @Param created_to query string false "Filter by creation date to (RFC3339 format)"
 // @Param page query int false "Page number" default(1)
@@ -142,7 +146,15 @@ func (h *Handler) listOrders(c *gin.Context) {
 	}
 
 	if country := c.Query("country"); country != "" {
-		filter.Country = country
+		filter.Country = strings.ToUpper(strings.TrimSpace(country))
+	}
+
+	if lotID := c.Query("lot_id"); lotID != "" {
+		filter.LotID = lotID
+	}
+
+	if warehouseID := c.Query("warehouse_id"); warehouseID != "" {
+		filter.WarehouseID = warehouseID
 	}
 
 	if createdFrom := c.Query("created_from"); createdFrom != "" {
@@ -182,7 +194,7 @@ func (h *Handler) listOrders(c *gin.Context) {
 }
 
 // @Summary Update order status
-// @Description Update the status of an existing order
+// @Description Update the status of an existing order. Unpaid bank transfer orders can only be cancelled. Cancelling here releases the whole order; to cancel or return some items, or to record a refund, use POST /orders/{id}/refunds.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -191,6 +203,7 @@ func (h *Handler) listOrders(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Status changed meanwhile"
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/orders/{id}/status [put]
 func (h *Handler) updateOrderStatus(c *gin.Context) {
@@ -216,8 +229,7 @@ func (h *Handler) updateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	status := models.OrderStatus(input.Status)
-	err := h.orderService.UpdateStatus(c.Request.Context(), id, status)
+	err := h.orderService.UpdateStatus(c.Request.Context(), id, &input)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -263,7 +275,7 @@ func (h *Handler) deleteOrder(c *gin.Context) {
 }
 
 // @Summary Get order statistics
-// @Description Get order statistics including counts by status and country
+// @Description Get order statistics including counts by status and country, and the net, tax and gross revenue of orders that were not cancelled per currency (revenue), per delivery country and currency (tax_by_country), and converted to the reporting currency at the exchange rate of the day each order was placed (reporting_revenue; currencies without a rate are listed as unconverted and left out)
 // @Tags orders
 // @Produce json
 // @Success 200 {object} map[string]interface{}
@@ -279,3 +291,66 @@ func (h *Handler) getOrderStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// MarkOrderPaid godoc
+// @Summary Mark an order paid
+// @Description Record a payment received outside the payment provider, such as a bank transfer or cash collected on delivery. A pending order is confirmed.
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} dto.OrderResponseDTO "Paid order"
+// @Failure 400 {object} map[string]interface{} "Order is cancelled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order is already paid"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/orders/{id}/mark-paid [post]
+func (h *Handler) markOrderPaid(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.MarkPaid(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Order.ToResponseDTO(order), "Order marked paid")
+}
+
+// RefundOrder godoc
+// @Summary Cancel or return order items
+// @Description Take items out of an order and record the refund. Before the order ships this is a cancellation: the items go back to stock, and cancelling every item cancels the order and refunds the payment fee. After it ships this is a return: items go back to stock only with restock set, so damaged returns are written off. The order total is recalculated; a paid order's refund amount is booked against its online payment.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param refund body dto.OrderRefundCreateDTO true "Items to take out"
+// @Success 201 {object} dto.RefundResponseDTO "Recorded refund"
+// @Failure 400 {object} map[string]interface{} "Invalid quantities or order is cancelled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Order or item not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/orders/{id}/refunds [post]
+func (h *Handler) refundOrder(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.OrderRefundCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	refund, err := h.orderService.Refund(c.Request.Context(), id, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Order.ToRefundResponseDTO(refund), "Order refunded")
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+
+	"caviar/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCallbackSize limits the body of payment provider callbacks.
+const maxCallbackSize = 1 << 20
+
+func (h *Handler) initPaymentRoutes(api *gin.RouterGroup) {
+	api.POST("/orders/:id/payments", h.createPayment)
+	api.GET("/orders/:id/payments", h.AuthMiddleware(), h.listPayments)
+
+	api.POST("/payments/callback/:provider", h.paymentCallback)
+}
+
+// CreatePayment godoc
+// @Summary Pay for an order
+// @Description Start a payment of the order's total and return the hosted checkout page to send the customer to. Only pending orders whose stock hold has not expired can be paid. The order is confirmed once the provider reports the payment as succeeded.
+// @Tags payments
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 201 {object} dto.PaymentResponseDTO "Payment with checkoutUrl"
+// @Failure 400 {object} map[string]interface{} "Order cannot be paid"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order is already paid"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/orders/{id}/payments [post]
+func (h *Handler) createPayment(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	payment, err := h.paymentService.StartCheckout(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Order.ToPaymentResponseDTO(payment), "Payment started successfully")
+}
+
+// ListPayments godoc
+// @Summary Payments of an order
+// @Description List every payment attempt of an order, oldest first
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {array} dto.PaymentResponseDTO "Payments"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/orders/{id}/payments [get]
+func (h *Handler) listPayments(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	payments, err := h.paymentService.ListByOrder(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Order.ToPaymentResponseDTOs(payments))
+}
+
+// PaymentCallback godoc
+// @Summary Payment provider callback
+// @Description Receive a signed payment status callback from a provider (liqpay posts form-encoded data and signature; the fake provider posts JSON signed in X-Fake-Signature). Requests with an invalid signature are rejected.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider (liqpay, fake)"
+// @Success 200 {object} map[string]interface{} "Callback applied"
+// @Failure 400 {object} map[string]interface{} "Malformed callback"
+// @Failure 401 {object} map[string]interface{} "Invalid signature"
+// @Failure 404 {object} map[string]interface{} "Unknown provider or payment"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/payments/callback/{provider} [post]
+func (h *Handler) paymentCallback(c *gin.Context) {
+	provider, ok := h.getPathParam(c, "provider", true)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCallbackSize))
+	if err != nil {
+		h.handleError(c, apperror.Wrap(err, apperror.CodeInvalidInput, "failed to read callback body"))
+		return
+	}
+
+	if err := h.paymentService.HandleCallback(c.Request.Context(), provider, body, c.Request.Header); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, map[string]any{"message": "Callback applied"})
+}
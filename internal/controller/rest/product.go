@@ -2,7 +2,6 @@ package rest
 
 import (
 	"caviar/internal/dto"
-	"caviar/internal/models"
 	"caviar/internal/types"
 	"context"
 	"io"
@@ -15,12 +14,30 @@ func (h *Handler) initProductRoutes(r *gin.RouterGroup) {
 	products := r.Group("/products")
 	
 	products.POST("/search", h.listProducts)
-	products.GET("/:id", h.getProduct) // Get single product by ID
+	products.GET("/slug/:slug", h.OptionalAuthMiddleware(), h.getProductBySlug)
+	products.GET("/:id", h.OptionalAuthMiddleware(), h.getProduct)
 
 	productsProtected := products.Group("/", h.AuthMiddleware())
 	productsProtected.POST("/", h.createProduct)
 	productsProtected.PUT("/:id", h.updateProduct)
 	productsProtected.DELETE("/:id", h.deleteProduct)
+	productsProtected.POST("/:id/restore", h.restoreProduct)
+	productsProtected.POST("/:id/publish", h.publishProduct)
+	productsProtected.POST("/:id/unpublish", h.unpublishProduct)
+	productsProtected.POST("/:id/archive", h.archiveProduct)
+	productsProtected.GET("/variants/lookup", h.lookupVariant)
+	productsProtected.POST("/:id/variants", h.createVariant)
+	productsProtected.PATCH("/:id/variants/:variantId", h.updateVariant)
+	productsProtected.DELETE("/:id/variants/:variantId", h.deleteVariant)
+	productsProtected.POST("/:id/variants/:variantId/stock-movements", h.createStockMovement)
+	productsProtected.GET("/:id/variants/:variantId/stock-movements", h.listStockMovements)
+	productsProtected.POST("/:id/variants/:variantId/lots", h.receiveLot)
+	productsProtected.GET("/:id/variants/:variantId/lots", h.listLots)
+	productsProtected.POST("/:id/variants/:variantId/transfers", h.transferStock)
+	productsProtected.POST("/:id/images", h.uploadProductImage)
+	productsProtected.PUT("/:id/images/order", h.reorderProductImages)
+	productsProtected.PUT("/:id/images/:imageId/primary", h.setPrimaryProductImage)
+	productsProtected.DELETE("/:id/images/:imageId", h.deleteProductImage)
 }
 
 // ListProducts godoc
@@ -76,7 +93,7 @@ func (h *Handler) createProduct(c *gin.Context) {
 		return
 	}
 
-	err := h.productService.Create(context.Background(), &product)
+	err := h.productService.Create(c.Request.Context(), &product)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -87,7 +104,7 @@ func (h *Handler) createProduct(c *gin.Context) {
 
 // UpdateProduct godoc
 // @Summary Update an existing product
-// @Description Update product details, variants, and other information
+// @Description Update product details. Variants are managed through the /products/{id}/variants endpoints.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -118,7 +135,7 @@ func (h *Handler) updateProduct(c *gin.Context) {
 
 // DeleteProduct godoc
 // @Summary Delete a product
-// @Description Delete a product and all its variants
+// @Description Soft-delete a product. It disappears from the catalog but past orders still resolve it, and it can be restored.
 // @Tags products
 // @Produce json
 // @Security BearerAuth
@@ -140,12 +157,43 @@ func (h *Handler) deleteProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
 }
 
+// RestoreProduct godoc
+// @Summary Restore a deleted product
+// @Description Undo a product deletion. Fails if another product has taken its slug.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ProductResponseDTO "Restored product"
+// @Failure 400 {object} map[string]interface{} "Slug is taken"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Deleted product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/restore [post]
+func (h *Handler) restoreProduct(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.Restore(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Product.ToResponseDTO(product), "Product restored successfully")
+}
+
+// GetProduct godoc
 // @Summary Get product by ID
-// @Description Get a single product by its ID
+// @Description Get a single product by its ID. Inactive products are only returned to authenticated callers.
 // @Tags products
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Product ID"
 // @Success 200 {object} dto.ProductResponseDTO "Product data"
+// @Failure 401 {object} map[string]interface{} "Invalid token"
 // @Failure 404 {object} map[string]interface{} "Product not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/products/{id} [get]
@@ -155,32 +203,38 @@ func (h *Handler) getProduct(c *gin.Context) {
 		return
 	}
 
-	// We need to create a temporary method since the service doesn't have GetByID exposed
-	// For now, let's use a filter to get the product
-	filter := types.DefaultProductFilter()
-	filter.ShowAll = true
-	filter.Limit = 1
-	
-	products, err := h.productService.List(context.Background(), false, filter)
+	product, err := h.productService.GetByID(c.Request.Context(), isAuthenticated(c), id)
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
-	
-	// Find the product with matching ID
-	var foundProduct *models.Product
-	for _, product := range products {
-		if product.ID == id {
-			foundProduct = product
-			break
-		}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Product.ToResponseDTO(product))
+}
+
+// GetProductBySlug godoc
+// @Summary Get product by slug
+// @Description Get a single product by its URL slug. Inactive products are only returned to authenticated callers.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Product slug"
+// @Success 200 {object} dto.ProductResponseDTO "Product data"
+// @Failure 401 {object} map[string]interface{} "Invalid token"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/slug/{slug} [get]
+func (h *Handler) getProductBySlug(c *gin.Context) {
+	slug, ok := h.getPathParam(c, "slug", true)
+	if !ok {
+		return
 	}
-	
-	if foundProduct == nil {
-		h.handleNotFound(c, "Product")
+
+	product, err := h.productService.GetBySlug(c.Request.Context(), isAuthenticated(c), slug)
+	if err != nil {
+		h.handleError(c, err)
 		return
 	}
 
-	response := h.converter.Product.ToResponseDTO(foundProduct)
-	h.handleSuccess(c, http.StatusOK, response)
-}
\ No newline at end of file
+	h.handleSuccess(c, http.StatusOK, h.converter.Product.ToResponseDTO(product))
+}
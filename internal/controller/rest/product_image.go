@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadProductImage godoc
+// @Summary Upload a product image
+// @Description Upload a JPEG, PNG or WebP image as multipart field "file". The type is detected from the content, not the file name. The first image of a product becomes primary.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param file formData file true "Image file"
+// @Success 201 {object} dto.ProductImageResponseDTO "Uploaded image"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/images [post]
+func (h *Handler) uploadProductImage(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.handleError(c, apperror.Wrap(err, apperror.CodeInvalidInput, "multipart field \"file\" is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.handleError(c, apperror.Wrap(err, apperror.CodeInvalidInput, "failed to open uploaded file"))
+		return
+	}
+	defer file.Close()
+
+	image, err := h.productService.UploadImage(c.Request.Context(), id, file)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Product.ToImageResponseDTO(*image), "Image uploaded successfully")
+}
+
+// ReorderProductImages godoc
+// @Summary Reorder product images
+// @Description Set the display order of product images. The list must contain every image ID of the product exactly once.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param order body dto.ProductImageReorderDTO true "Image IDs in display order"
+// @Success 200 {array} dto.ProductImageResponseDTO "Images in new order"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product or image not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/images/order [put]
+func (h *Handler) reorderProductImages(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.ProductImageReorderDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	images, err := h.productService.ReorderImages(c.Request.Context(), id, input.ImageIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Product.ToImageResponseDTOs(images), "Images reordered successfully")
+}
+
+// SetPrimaryProductImage godoc
+// @Summary Set the primary product image
+// @Description Mark an image as the primary image of the product
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {array} dto.ProductImageResponseDTO "Product images"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product or image not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/images/{imageId}/primary [put]
+func (h *Handler) setPrimaryProductImage(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	imageID, ok := h.getPathParam(c, "imageId", true)
+	if !ok {
+		return
+	}
+
+	images, err := h.productService.SetPrimaryImage(c.Request.Context(), id, imageID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Product.ToImageResponseDTOs(images), "Primary image updated successfully")
+}
+
+// DeleteProductImage godoc
+// @Summary Delete a product image
+// @Description Remove an image from the product and from storage. If it was primary, the next image becomes primary.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product or image not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/images/{imageId} [delete]
+func (h *Handler) deleteProductImage(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	imageID, ok := h.getPathParam(c, "imageId", true)
+	if !ok {
+		return
+	}
+
+	if err := h.productService.DeleteImage(c.Request.Context(), id, imageID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleDeleted(c, "Image deleted successfully")
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"errors"
+	"io"
+
+	"caviar/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublishProduct godoc
+// @Summary Publish a product
+// @Description Publish a product now, or schedule it when publish_at is given. The product needs at least one variant with a price and stock, and at least one image.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param publish body dto.ProductPublishDTO false "Optional scheduled publish time"
+// @Success 200 {object} dto.ProductResponseDTO "Published or scheduled product"
+// @Failure 400 {object} map[string]interface{} "Product fails pre-publish checks"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/publish [post]
+func (h *Handler) publishProduct(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.ProductPublishDTO
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		h.handleValidationError(c, err)
+		return
+	}
+
+	product, err := h.productService.Publish(c.Request.Context(), id, input.PublishAt)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	message := "Product published successfully"
+	if input.PublishAt != nil {
+		message = "Product scheduled for publication"
+	}
+	h.handleUpdated(c, h.converter.Product.ToResponseDTO(product), message)
+}
+
+// UnpublishProduct godoc
+// @Summary Unpublish a product
+// @Description Return a published or scheduled product to draft
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ProductResponseDTO "Draft product"
+// @Failure 400 {object} map[string]interface{} "Product is not published"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/unpublish [post]
+func (h *Handler) unpublishProduct(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.Unpublish(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Product.ToResponseDTO(product), "Product unpublished successfully")
+}
+
+// ArchiveProduct godoc
+// @Summary Archive a product
+// @Description Hide a product from the storefront for good. Past orders keep referencing it.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ProductResponseDTO "Archived product"
+// @Failure 400 {object} map[string]interface{} "Product is already archived"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/archive [post]
+func (h *Handler) archiveProduct(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.Archive(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Product.ToResponseDTO(product), "Product archived successfully")
+}
@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"net/http"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LookupVariant godoc
+// @Summary Find a variant by SKU or barcode
+// @Description Look up a variant and its product by SKU or EAN-13 barcode. Exactly one of the two must be given.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param sku query string false "SKU"
+// @Param barcode query string false "EAN-13 barcode"
+// @Success 200 {object} dto.VariantLookupResponseDTO "Variant with its product"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/variants/lookup [get]
+func (h *Handler) lookupVariant(c *gin.Context) {
+	sku, barcode := c.Query("sku"), c.Query("barcode")
+	if (sku == "") == (barcode == "") {
+		h.handleError(c, apperror.New(apperror.CodeInvalidInput, "provide either sku or barcode"))
+		return
+	}
+
+	product, variant, err := h.productService.LookupVariant(c.Request.Context(), sku, barcode)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, dto.VariantLookupResponseDTO{
+		Product: h.converter.Product.ToResponseDTO(product),
+		Variant: h.converter.Product.ToVariantResponseDTO(*variant),
+	})
+}
+
+// CreateVariant godoc
+// @Summary Add a product variant
+// @Description Add a variant with its mass, stock, prices and optional SKU and EAN-13 barcode to a product
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variant body dto.VariantCreateDTO true "Variant data"
+// @Success 201 {object} dto.VariantResponseDTO "Created variant"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 409 {object} map[string]interface{} "SKU or barcode already used"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants [post]
+func (h *Handler) createVariant(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.VariantCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	variant, err := h.productService.CreateVariant(c.Request.Context(), id, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Product.ToVariantResponseDTO(*variant), "Variant created successfully")
+}
+
+// UpdateVariant godoc
+// @Summary Update a product variant
+// @Description Change the mass, SKU, barcode, stock or prices of a variant. The version from the last read must be sent; if the variant changed since (for example, an order took stock), the request fails with 409 and should be retried on fresh data.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param variant body dto.VariantPatchDTO true "Fields to change and the expected version"
+// @Success 200 {object} dto.VariantResponseDTO "Updated variant"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant not found"
+// @Failure 409 {object} map[string]interface{} "Version mismatch, or SKU or barcode already used"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants/{variantId} [patch]
+func (h *Handler) updateVariant(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	variantID, ok := h.getPathParam(c, "variantId", true)
+	if !ok {
+		return
+	}
+
+	var input dto.VariantPatchDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	variant, err := h.productService.UpdateVariant(c.Request.Context(), id, variantID, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Product.ToVariantResponseDTO(*variant), "Variant updated successfully")
+}
+
+// DeleteVariant godoc
+// @Summary Remove a product variant
+// @Description Remove a variant. Refused while unfinished orders reference it, or if it is the product's last variant. Its remaining stock is written off; past orders keep showing it.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]interface{} "Last variant of the product"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Variant not found"
+// @Failure 409 {object} map[string]interface{} "Variant is in open orders"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/products/{id}/variants/{variantId} [delete]
+func (h *Handler) deleteVariant(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+	variantID, ok := h.getPathParam(c, "variantId", true)
+	if !ok {
+		return
+	}
+
+	if err := h.productService.DeleteVariant(c.Request.Context(), id, variantID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleDeleted(c, "Variant deleted successfully")
+}
@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"net/http"
+
+	"caviar/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) initPromoRoutes(api *gin.RouterGroup) {
+	promos := api.Group("/promo-codes", h.AuthMiddleware())
+	promos.GET("", h.listPromoCodes)
+	promos.POST("", h.createPromoCode)
+	promos.PATCH("/:id", h.updatePromoCode)
+}
+
+// ListPromoCodes godoc
+// @Summary List promo codes
+// @Description List all promo codes, inactive and expired ones included, newest first
+// @Tags promo-codes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.PromoCodeResponseDTO "Promo codes"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/promo-codes [get]
+func (h *Handler) listPromoCodes(c *gin.Context) {
+	promos, err := h.promoService.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Promo.ToResponseDTOs(promos))
+}
+
+// CreatePromoCode godoc
+// @Summary Create a promo code
+// @Description Add a promo code. Percentage and fixed codes take an amount off the items they apply to: all items, or those of the listed products and variants. Free shipping codes mark the order for free delivery and take nothing off the items. Codes can be limited to a time window, a minimum order amount, a number of orders and a number of orders per customer phone; cancelled orders give their use back.
+// @Tags promo-codes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param promo body dto.PromoCodeCreateDTO true "Promo code"
+// @Success 201 {object} dto.PromoCodeResponseDTO "Created promo code"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Promo code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/promo-codes [post]
+func (h *Handler) createPromoCode(c *gin.Context) {
+	var input dto.PromoCodeCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	promo, err := h.promoService.Create(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Promo.ToResponseDTO(promo), "Promo code created successfully")
+}
+
+// UpdatePromoCode godoc
+// @Summary Update a promo code
+// @Description Change the fields that are set. The code and its type cannot be changed; deactivate it and create another instead.
+// @Tags promo-codes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promo code ID"
+// @Param promo body dto.PromoCodePatchDTO true "Changes"
+// @Success 200 {object} dto.PromoCodeResponseDTO "Updated promo code"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Promo code not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/promo-codes/{id} [patch]
+func (h *Handler) updatePromoCode(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.PromoCodePatchDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	promo, err := h.promoService.Update(c.Request.Context(), id, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Promo.ToResponseDTO(promo), "Promo code updated successfully")
+}
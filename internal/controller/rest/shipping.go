@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"net/http"
+
+	"caviar/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) initShippingRoutes(api *gin.RouterGroup) {
+	shipping := api.Group("/shipping")
+	shipping.POST("/quote", h.quoteShipping)
+
+	shippingProtected := shipping.Group("/", h.AuthMiddleware())
+	shippingProtected.GET("/zones", h.listShippingZones)
+	shippingProtected.POST("/zones", h.createShippingZone)
+	shippingProtected.PATCH("/zones/:id", h.updateShippingZone)
+	shippingProtected.GET("/rates", h.listShippingRates)
+	shippingProtected.POST("/rates", h.createShippingRate)
+	shippingProtected.PATCH("/rates/:id", h.updateShippingRate)
+	shippingProtected.DELETE("/rates/:id", h.deleteShippingRate)
+}
+
+// QuoteShipping godoc
+// @Summary Quote shipping
+// @Description Price delivering the items before placing an order. The parcel weight is the mass of the items plus the box and ice packs. Zones listing the country are used before the catch-all zone, and the lightest rate the parcel fits wins. Delivery is free when the items are worth at least the rate's free shipping threshold; promo codes are not taken into account here.
+// @Tags shipping
+// @Accept json
+// @Produce json
+// @Param quote body dto.ShippingQuoteRequestDTO true "Items and destination"
+// @Success 200 {object} dto.ShippingQuoteResponseDTO "Shipping quote"
+// @Failure 400 {object} map[string]interface{} "Delivery not available or parcel too heavy"
+// @Failure 404 {object} map[string]interface{} "Variant not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/quote [post]
+func (h *Handler) quoteShipping(c *gin.Context) {
+	var input dto.ShippingQuoteRequestDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	quote, err := h.shippingService.Quote(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Shipping.ToQuoteResponseDTO(quote))
+}
+
+// ListShippingZones godoc
+// @Summary List shipping zones
+// @Description List the delivery zones by code
+// @Tags shipping
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.ShippingZoneResponseDTO "Shipping zones"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/zones [get]
+func (h *Handler) listShippingZones(c *gin.Context) {
+	zones, err := h.shippingService.ListZones(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Shipping.ToZoneResponseDTOs(zones))
+}
+
+// CreateShippingZone godoc
+// @Summary Create a shipping zone
+// @Description Add a delivery zone of countries sharing shipping rates. A zone without countries covers every country no other zone lists.
+// @Tags shipping
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param zone body dto.ShippingZoneCreateDTO true "Shipping zone"
+// @Success 201 {object} dto.ShippingZoneResponseDTO "Created shipping zone"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Zone code already used"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/zones [post]
+func (h *Handler) createShippingZone(c *gin.Context) {
+	var input dto.ShippingZoneCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	zone, err := h.shippingService.CreateZone(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Shipping.ToZoneResponseDTO(zone), "Shipping zone created successfully")
+}
+
+// UpdateShippingZone godoc
+// @Summary Update a shipping zone
+// @Description Change the name or countries of a zone. The code cannot be changed.
+// @Tags shipping
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Shipping zone ID"
+// @Param zone body dto.ShippingZonePatchDTO true "Changes"
+// @Success 200 {object} dto.ShippingZoneResponseDTO "Updated shipping zone"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Shipping zone not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/zones/{id} [patch]
+func (h *Handler) updateShippingZone(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.ShippingZonePatchDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	zone, err := h.shippingService.UpdateZone(c.Request.Context(), id, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Shipping.ToZoneResponseDTO(zone), "Shipping zone updated successfully")
+}
+
+// ListShippingRates godoc
+// @Summary List shipping rates
+// @Description List the shipping rates by zone, delivery type and weight, inactive ones included
+// @Tags shipping
+// @Produce json
+// @Security BearerAuth
+// @Param zone_id query string false "Only the rates of this zone"
+// @Success 200 {array} dto.ShippingRateResponseDTO "Shipping rates"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/rates [get]
+func (h *Handler) listShippingRates(c *gin.Context) {
+	rates, err := h.shippingService.ListRates(c.Request.Context(), c.Query("zone_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Shipping.ToRateResponseDTOs(rates))
+}
+
+// CreateShippingRate godoc
+// @Summary Create a shipping rate
+// @Description Add the price of a delivery type within a zone for parcels of up to max_weight grams; 0 means no limit. Orders worth at least free_from after discounts ship free; 0 means never.
+// @Tags shipping
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rate body dto.ShippingRateCreateDTO true "Shipping rate"
+// @Success 201 {object} dto.ShippingRateResponseDTO "Created shipping rate"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Shipping zone not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/rates [post]
+func (h *Handler) createShippingRate(c *gin.Context) {
+	var input dto.ShippingRateCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	rate, err := h.shippingService.CreateRate(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Shipping.ToRateResponseDTO(rate), "Shipping rate created successfully")
+}
+
+// UpdateShippingRate godoc
+// @Summary Update a shipping rate
+// @Description Change the fields that are set. Existing orders keep the shipping they were charged.
+// @Tags shipping
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Shipping rate ID"
+// @Param rate body dto.ShippingRatePatchDTO true "Changes"
+// @Success 200 {object} dto.ShippingRateResponseDTO "Updated shipping rate"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Shipping rate not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/rates/{id} [patch]
+func (h *Handler) updateShippingRate(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.ShippingRatePatchDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	rate, err := h.shippingService.UpdateRate(c.Request.Context(), id, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Shipping.ToRateResponseDTO(rate), "Shipping rate updated successfully")
+}
+
+// DeleteShippingRate godoc
+// @Summary Remove a shipping rate
+// @Description Remove a shipping rate. Existing orders keep the shipping they were charged.
+// @Tags shipping
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Shipping rate ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Shipping rate not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/shipping/rates/{id} [delete]
+func (h *Handler) deleteShippingRate(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	if err := h.shippingService.DeleteRate(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleDeleted(c, "Shipping rate deleted successfully")
+}
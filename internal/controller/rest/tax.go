@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"net/http"
+
+	"caviar/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) initTaxRoutes(api *gin.RouterGroup) {
+	rates := api.Group("/tax-rates", h.AuthMiddleware())
+	rates.GET("", h.listTaxRates)
+	rates.POST("", h.createTaxRate)
+	rates.PATCH("/:id", h.updateTaxRate)
+	rates.DELETE("/:id", h.deleteTaxRate)
+}
+
+// ListTaxRates godoc
+// @Summary List tax rates
+// @Description List the VAT rates by delivery country
+// @Tags tax-rates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.TaxRateResponseDTO "Tax rates"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/tax-rates [get]
+func (h *Handler) listTaxRates(c *gin.Context) {
+	rates, err := h.taxService.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Tax.ToResponseDTOs(rates))
+}
+
+// CreateTaxRate godoc
+// @Summary Create a tax rate
+// @Description Set the VAT rate of a delivery country in basis points, 2000 being 20%. Inclusive rates are carved out of the prices; exclusive ones are added on top of them. Orders to countries without a rate are not taxed. Existing orders keep the rate they were placed with.
+// @Tags tax-rates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rate body dto.TaxRateCreateDTO true "Tax rate"
+// @Success 201 {object} dto.TaxRateResponseDTO "Created tax rate"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Country already has a rate"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/tax-rates [post]
+func (h *Handler) createTaxRate(c *gin.Context) {
+	var input dto.TaxRateCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	rate, err := h.taxService.Create(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Tax.ToResponseDTO(rate), "Tax rate created successfully")
+}
+
+// UpdateTaxRate godoc
+// @Summary Update a tax rate
+// @Description Change the fields that are set. Existing orders keep the rate they were placed with.
+// @Tags tax-rates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax rate ID"
+// @Param rate body dto.TaxRatePatchDTO true "Changes"
+// @Success 200 {object} dto.TaxRateResponseDTO "Updated tax rate"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Tax rate not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/tax-rates/{id} [patch]
+func (h *Handler) updateTaxRate(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.TaxRatePatchDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	rate, err := h.taxService.Update(c.Request.Context(), id, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Tax.ToResponseDTO(rate), "Tax rate updated successfully")
+}
+
+// DeleteTaxRate godoc
+// @Summary Remove a tax rate
+// @Description Remove the rate of a country; new orders to it are not taxed. Existing orders keep the rate they were placed with.
+// @Tags tax-rates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax rate ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Tax rate not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/tax-rates/{id} [delete]
+func (h *Handler) deleteTaxRate(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	if err := h.taxService.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleDeleted(c, "Tax rate deleted successfully")
+}
@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"net/http"
+
+	"caviar/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) initTemplateRoutes(api *gin.RouterGroup) {
+	templates := api.Group("/notification-templates", h.AuthMiddleware())
+	templates.GET("", h.listTemplates)
+	templates.PUT("/:event/:channel/:locale", h.upsertTemplate)
+	templates.DELETE("/:event/:channel/:locale", h.deleteTemplate)
+}
+
+// ListTemplates godoc
+// @Summary List notification templates
+// @Description List the effective template for every event, channel and locale. Built-in templates are marked with isDefault.
+// @Tags notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.NotificationTemplateResponseDTO "Notification templates"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/notification-templates [get]
+func (h *Handler) listTemplates(c *gin.Context) {
+	templates, err := h.templateService.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Template.ToResponseDTOs(templates))
+}
+
+// UpsertTemplate godoc
+// @Summary Override a notification template
+// @Description Store a template override for an event, channel and locale. The body is a Go template and is validated against a sample order before saving.
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param event path string true "Event (order_created, order_received, order_shipped, order_hold_expired, order_paid_after_cancel, low_stock, lot_expiring)"
+// @Param channel path string true "Channel (telegram, sms)"
+// @Param locale path string true "Locale (uk, en)"
+// @Param template body dto.NotificationTemplateUpsertDTO true "Template body"
+// @Success 200 {object} dto.NotificationTemplateResponseDTO "Saved template"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/notification-templates/{event}/{channel}/{locale} [put]
+func (h *Handler) upsertTemplate(c *gin.Context) {
+	var input dto.NotificationTemplateUpsertDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+	input.Event = c.Param("event")
+	input.Channel = c.Param("channel")
+	input.Locale = c.Param("locale")
+
+	template, err := h.templateService.Upsert(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Template.ToResponseDTO(template), "Template saved successfully")
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a notification template override
+// @Description Remove a stored override so the built-in template is used again
+// @Tags notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param event path string true "Event"
+// @Param channel path string true "Channel"
+// @Param locale path string true "Locale"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Template override not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/notification-templates/{event}/{channel}/{locale} [delete]
+func (h *Handler) deleteTemplate(c *gin.Context) {
+	err := h.templateService.Delete(c.Request.Context(), c.Param("event"), c.Param("channel"), c.Param("locale"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleDeleted(c, "Template override deleted, built-in template restored")
+}
@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"net/http"
+
+	"caviar/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) initWarehouseRoutes(api *gin.RouterGroup) {
+	warehouses := api.Group("/warehouses", h.AuthMiddleware())
+	warehouses.GET("", h.listWarehouses)
+	warehouses.POST("", h.createWarehouse)
+	warehouses.PATCH("/:id", h.updateWarehouse)
+}
+
+// ListWarehouses godoc
+// @Summary List warehouses
+// @Description List all warehouses, inactive ones included, by priority
+// @Tags warehouses
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.WarehouseResponseDTO "Warehouses"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/warehouses [get]
+func (h *Handler) listWarehouses(c *gin.Context) {
+	warehouses, err := h.warehouseService.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleSuccess(c, http.StatusOK, h.converter.Warehouse.ToResponseDTOs(warehouses))
+}
+
+// CreateWarehouse godoc
+// @Summary Create a warehouse
+// @Description Add a warehouse. Orders are fulfilled from the active warehouse with the lowest priority that lists the delivery country and has stock for every item; warehouses without countries ship anywhere and are tried after those listing the country. Creating a warehouse as the default replaces the current default.
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param warehouse body dto.WarehouseCreateDTO true "Warehouse"
+// @Success 201 {object} dto.WarehouseResponseDTO "Created warehouse"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Warehouse code already used"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/warehouses [post]
+func (h *Handler) createWarehouse(c *gin.Context) {
+	var input dto.WarehouseCreateDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	warehouse, err := h.warehouseService.Create(c.Request.Context(), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleCreated(c, h.converter.Warehouse.ToResponseDTO(warehouse), "Warehouse created successfully")
+}
+
+// UpdateWarehouse godoc
+// @Summary Update a warehouse
+// @Description Change the fields that are set. The default warehouse cannot be deactivated or unset; make another warehouse the default instead.
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warehouse ID"
+// @Param warehouse body dto.WarehousePatchDTO true "Changes"
+// @Success 200 {object} dto.WarehouseResponseDTO "Updated warehouse"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Warehouse not found"
+// @Failure 409 {object} map[string]interface{} "Warehouse code already used"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/warehouses/{id} [patch]
+func (h *Handler) updateWarehouse(c *gin.Context) {
+	id, ok := h.getPathParam(c, "id", true)
+	if !ok {
+		return
+	}
+
+	var input dto.WarehousePatchDTO
+	if !h.bindJSON(c, &input) {
+		return
+	}
+
+	warehouse, err := h.warehouseService.Update(c.Request.Context(), id, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.handleUpdated(c, h.converter.Warehouse.ToResponseDTO(warehouse), "Warehouse updated successfully")
+}
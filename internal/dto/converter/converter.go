@@ -1,14 +1,28 @@
 package converter
 
 type Converter struct {
-	Order   *OrderConverter
-	Product *ProductConverter
+	Order     *OrderConverter
+	Product   *ProductConverter
+	Template  *TemplateConverter
+	Inventory *InventoryConverter
+	Warehouse *WarehouseConverter
+	Promo     *PromoConverter
+	Shipping  *ShippingConverter
+	Tax       *TaxConverter
+	Exchange  *ExchangeRateConverter
 }
 
 func NewConverter() *Converter {
 	return &Converter{
-		Order:   NewOrderConverter(),
-		Product: NewProductConverter(),
+		Order:     NewOrderConverter(),
+		Product:   NewProductConverter(),
+		Template:  NewTemplateConverter(),
+		Inventory: NewInventoryConverter(),
+		Warehouse: NewWarehouseConverter(),
+		Promo:     NewPromoConverter(),
+		Shipping:  NewShippingConverter(),
+		Tax:       NewTaxConverter(),
+		Exchange:  NewExchangeRateConverter(),
 	}
 }
 
@@ -0,0 +1,46 @@
+package converter
+
+import (
+	"encoding/json"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+)
+
+type ExchangeRateConverter struct{}
+
+func NewExchangeRateConverter() *ExchangeRateConverter {
+	return &ExchangeRateConverter{}
+}
+
+// ToResponseDTO converts a model ExchangeRate to ExchangeRateResponseDTO
+func (c *ExchangeRateConverter) ToResponseDTO(r *models.ExchangeRate) dto.ExchangeRateResponseDTO {
+	return dto.ExchangeRateResponseDTO{
+		ID:          r.ID,
+		Base:        r.Base,
+		Quote:       r.Quote,
+		Rate:        json.Number(r.Rate.String()),
+		EffectiveOn: r.EffectiveOn.Format(models.DateLayout),
+		Source:      string(r.Source),
+		CreatedAt:   r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToResponseDTOs converts a slice of ExchangeRates to ExchangeRateResponseDTOs
+func (c *ExchangeRateConverter) ToResponseDTOs(rates []*models.ExchangeRate) []dto.ExchangeRateResponseDTO {
+	result := make([]dto.ExchangeRateResponseDTO, 0, len(rates))
+	for _, r := range rates {
+		result = append(result, c.ToResponseDTO(r))
+	}
+	return result
+}
+
+// ToImportResponseDTO converts the rates stored by an import to ExchangeRateImportResponseDTO
+func (c *ExchangeRateConverter) ToImportResponseDTO(rates []*models.ExchangeRate) dto.ExchangeRateImportResponseDTO {
+	return dto.ExchangeRateImportResponseDTO{
+		Imported: len(rates),
+		Rates:    c.ToResponseDTOs(rates),
+	}
+}
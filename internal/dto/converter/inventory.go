@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+)
+
+type InventoryConverter struct{}
+
+func NewInventoryConverter() *InventoryConverter {
+	return &InventoryConverter{}
+}
+
+// ToMovementResponseDTO converts a model StockMovement to StockMovementResponseDTO
+func (c *InventoryConverter) ToMovementResponseDTO(m *models.StockMovement) dto.StockMovementResponseDTO {
+	var lotID, warehouseID string
+	if m.LotID != nil {
+		lotID = *m.LotID
+	}
+	if m.WarehouseID != nil {
+		warehouseID = *m.WarehouseID
+	}
+	return dto.StockMovementResponseDTO{
+		ID:          m.ID,
+		VariantID:   m.VariantID,
+		ProductID:   m.ProductID,
+		LotID:       lotID,
+		WarehouseID: warehouseID,
+		Delta:       m.Delta,
+		Reason:      string(m.Reason),
+		ReferenceID: m.ReferenceID,
+		Actor:       m.Actor,
+		Note:        m.Note,
+		Balance:     m.Balance,
+		CreatedAt:   m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToMovementResponseDTOs converts a slice of StockMovements to StockMovementResponseDTOs
+func (c *InventoryConverter) ToMovementResponseDTOs(movements []*models.StockMovement) []dto.StockMovementResponseDTO {
+	result := make([]dto.StockMovementResponseDTO, 0, len(movements))
+	for _, m := range movements {
+		result = append(result, c.ToMovementResponseDTO(m))
+	}
+	return result
+}
+
+// ToMovementListResponseDTO converts StockMovements with pagination to StockMovementListResponseDTO
+func (c *InventoryConverter) ToMovementListResponseDTO(movements []*models.StockMovement, page, limit int, total int64) dto.StockMovementListResponseDTO {
+	return dto.StockMovementListResponseDTO{
+		Movements: c.ToMovementResponseDTOs(movements),
+		Total:     int(total),
+		Page:      page,
+		Limit:     limit,
+	}
+}
+
+// ToDiscrepancyResponseDTOs converts StockDiscrepancies to StockDiscrepancyResponseDTOs
+func (c *InventoryConverter) ToDiscrepancyResponseDTOs(items []models.StockDiscrepancy) []dto.StockDiscrepancyResponseDTO {
+	result := make([]dto.StockDiscrepancyResponseDTO, 0, len(items))
+	for _, d := range items {
+		result = append(result, dto.StockDiscrepancyResponseDTO{
+			VariantID:   d.VariantID,
+			ProductID:   d.ProductID,
+			SKU:         d.SKU,
+			Stock:       d.Stock,
+			LedgerStock: d.LedgerStock,
+			LotStock:    d.LotStock,
+			Difference:  d.Stock - d.LedgerStock,
+		})
+	}
+	return result
+}
+
+// ToLotResponseDTO converts a model StockLot to StockLotResponseDTO; now
+// is used for the days left until expiry
+func (c *InventoryConverter) ToLotResponseDTO(lot *models.StockLot, now time.Time) dto.StockLotResponseDTO {
+	result := dto.StockLotResponseDTO{
+		ID:               lot.ID,
+		VariantID:        lot.VariantID,
+		ProductID:        lot.ProductID,
+		WarehouseID:      lot.WarehouseID,
+		LotNumber:        lot.LotNumber,
+		DaysToExpiry:     lot.DaysToExpiry(now),
+		Expired:          lot.IsExpired(now),
+		ReceivedQuantity: lot.ReceivedQuantity,
+		Quantity:         lot.Quantity,
+		CreatedAt:        lot.CreatedAt.Format(time.RFC3339),
+	}
+	if lot.PackedAt != nil {
+		result.PackedAt = lot.PackedAt.Format(models.DateLayout)
+	}
+	if lot.ExpiresAt != nil {
+		result.ExpiresAt = lot.ExpiresAt.Format(models.DateLayout)
+	}
+	if lot.Variant != nil {
+		result.SKU = lot.Variant.SKU
+	}
+	if lot.Product != nil {
+		result.ProductName = lot.Product.Name
+	}
+	if lot.Warehouse != nil {
+		result.WarehouseCode = lot.Warehouse.Code
+	}
+	return result
+}
+
+// ToLotResponseDTOs converts a slice of StockLots to StockLotResponseDTOs
+func (c *InventoryConverter) ToLotResponseDTOs(lots []*models.StockLot, now time.Time) []dto.StockLotResponseDTO {
+	result := make([]dto.StockLotResponseDTO, 0, len(lots))
+	for _, lot := range lots {
+		result = append(result, c.ToLotResponseDTO(lot, now))
+	}
+	return result
+}
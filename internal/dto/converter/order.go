@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"encoding/json"
 	"time"
 
 	"caviar/internal/dto"
@@ -19,15 +20,34 @@ func (c *OrderConverter) ToResponseDTO(order *models.Order) dto.OrderResponseDTO
 		return dto.OrderResponseDTO{}
 	}
 
+	var warehouseID string
+	if order.WarehouseID != nil {
+		warehouseID = *order.WarehouseID
+	}
+
 	return dto.OrderResponseDTO{
 		ID:          order.ID,
 		OrderNumber: order.OrderNumber,
 		CustomerInfo: c.toCustomerInfoDTO(order.CustomerInfo),
 		DeliveryInfo: c.toDeliveryInfoDTO(order.DeliveryInfo),
-		Items:        c.toOrderItemsDTO(order.Items),
+		Items:        c.toOrderItemsDTO(order),
 		TotalAmount:  c.toMoneyDTO(order.TotalAmount),
 		Status:       string(order.Status),
 		Notes:        order.Notes,
+		TrackingNumber: order.TrackingNumber,
+		WarehouseID:  warehouseID,
+		HoldExpiresAt: formatOptionalTime(order.HoldExpiresAt),
+		PaymentMethod: string(order.PaymentMethod),
+		PaymentFee:   dto.MoneyDTO{Amount: order.PaymentFee, Currency: order.TotalAmount.Currency},
+		ShippingCost: dto.MoneyDTO{Amount: order.ShippingCost, Currency: order.TotalAmount.Currency},
+		ShippingWeight: order.ShippingWeight,
+		PromoCode:    order.PromoCode,
+		Discounts:    c.toDiscountsDTO(order.Discounts, order.TotalAmount.Currency),
+		DiscountAmount: dto.MoneyDTO{Amount: order.Discounts.Total(), Currency: order.TotalAmount.Currency},
+		Tax:          c.toTaxBreakdownDTO(order, order.NetAmount, order.TaxAmount),
+		PaidAt:       formatOptionalTime(order.PaidAt),
+		Payments:     c.toPaymentsDTO(order.Payments),
+		Refunds:      c.toRefundsDTO(order.Refunds),
 		CreatedAt:    order.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    order.UpdatedAt.Format(time.RFC3339),
 	}
@@ -64,6 +84,7 @@ func (c *OrderConverter) toCustomerInfoDTO(info models.CustomerInfo) dto.Custome
 		FullName:  info.FullName,
 		Phone:     info.Phone,
 		Email:     info.Email,
+		Language:  info.Language,
 	}
 }
 
@@ -79,8 +100,9 @@ func (c *OrderConverter) toDeliveryInfoDTO(info models.DeliveryInfo) dto.Deliver
 	}
 }
 
-// toOrderItemsDTO converts model OrderItems to OrderItemResponseDTOs
-func (c *OrderConverter) toOrderItemsDTO(items []models.OrderItem) []dto.OrderItemResponseDTO {
+// toOrderItemsDTO converts the order's items to OrderItemResponseDTOs
+func (c *OrderConverter) toOrderItemsDTO(order *models.Order) []dto.OrderItemResponseDTO {
+	items := order.Items
 	if len(items) == 0 {
 		return []dto.OrderItemResponseDTO{}
 	}
@@ -96,6 +118,13 @@ func (c *OrderConverter) toOrderItemsDTO(items []models.OrderItem) []dto.OrderIt
 			Quantity:   item.Quantity,
 			UnitPrice:  c.toMoneyDTO(item.UnitPrice),
 			TotalPrice: c.toMoneyDTO(item.TotalPrice),
+			Tax:        c.toLineTaxDTO(order, item),
+			Lots:       c.toOrderItemLotsDTO(item.Lots),
+		}
+
+		if item.Variant != nil {
+			itemResponse.SKU = item.Variant.SKU
+			itemResponse.Barcode = item.Variant.Barcode
 		}
 
 		if item.Product != nil {
@@ -108,6 +137,138 @@ func (c *OrderConverter) toOrderItemsDTO(items []models.OrderItem) []dto.OrderIt
 	return result
 }
 
+// toTaxBreakdownDTO splits an amount of the order at its tax rate
+func (c *OrderConverter) toTaxBreakdownDTO(order *models.Order, net, tax int) dto.TaxBreakdownDTO {
+	currency := order.TotalAmount.Currency
+	return dto.TaxBreakdownDTO{
+		Rate:      order.TaxRate,
+		Inclusive: order.TaxInclusive,
+		Net:       dto.MoneyDTO{Amount: net, Currency: currency},
+		Tax:       dto.MoneyDTO{Amount: tax, Currency: currency},
+		Gross:     dto.MoneyDTO{Amount: net + tax, Currency: currency},
+	}
+}
+
+// toLineTaxDTO splits what is paid for an order item at the order's tax rate
+func (c *OrderConverter) toLineTaxDTO(order *models.Order, item models.OrderItem) dto.LineTaxDTO {
+	return dto.LineTaxDTO{
+		Rate:      order.TaxRate,
+		Inclusive: order.TaxInclusive,
+		Currency:  order.TotalAmount.Currency,
+		Net:       json.Number(item.NetAmount.String()),
+		Tax:       json.Number(item.TaxAmount.String()),
+		Gross:     json.Number(item.GrossAmount.String()),
+	}
+}
+
+// ToPaymentResponseDTO converts a model Payment to PaymentResponseDTO
+func (c *OrderConverter) ToPaymentResponseDTO(p *models.Payment) dto.PaymentResponseDTO {
+	return dto.PaymentResponseDTO{
+		ID:             p.ID,
+		OrderID:        p.OrderID,
+		Provider:       p.Provider,
+		ExternalID:     p.ExternalID,
+		Amount:         dto.MoneyDTO{Amount: p.Amount, Currency: p.Currency},
+		Status:         string(p.Status),
+		CheckoutURL:    p.CheckoutURL,
+		FailureReason:  p.FailureReason,
+		RefundedAmount: dto.MoneyDTO{Amount: p.RefundedAmount, Currency: p.Currency},
+		PaidAt:         formatOptionalTime(p.PaidAt),
+		CreatedAt:      p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToPaymentResponseDTOs converts a slice of Payments to PaymentResponseDTOs
+func (c *OrderConverter) ToPaymentResponseDTOs(payments []*models.Payment) []dto.PaymentResponseDTO {
+	result := make([]dto.PaymentResponseDTO, 0, len(payments))
+	for _, p := range payments {
+		result = append(result, c.ToPaymentResponseDTO(p))
+	}
+	return result
+}
+
+// toPaymentsDTO converts the payments loaded with an order
+func (c *OrderConverter) toPaymentsDTO(payments []models.Payment) []dto.PaymentResponseDTO {
+	result := make([]dto.PaymentResponseDTO, 0, len(payments))
+	for i := range payments {
+		result = append(result, c.ToPaymentResponseDTO(&payments[i]))
+	}
+	return result
+}
+
+// ToRefundResponseDTO converts a model Refund to RefundResponseDTO
+func (c *OrderConverter) ToRefundResponseDTO(r *models.Refund) dto.RefundResponseDTO {
+	var paymentID string
+	if r.PaymentID != nil {
+		paymentID = *r.PaymentID
+	}
+
+	items := make([]dto.RefundItemResponseDTO, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, dto.RefundItemResponseDTO{
+			ItemID:    item.OrderItemID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Amount:    dto.MoneyDTO{Amount: item.Amount, Currency: r.Currency},
+			Restock:   item.Restock,
+			Lots:      c.toOrderItemLotsDTO(item.Lots),
+		})
+	}
+
+	return dto.RefundResponseDTO{
+		ID:        r.ID,
+		OrderID:   r.OrderID,
+		Kind:      string(r.Kind),
+		Amount:    dto.MoneyDTO{Amount: r.Amount, Currency: r.Currency},
+		PaymentID: paymentID,
+		Items:     items,
+		Reason:    r.Reason,
+		Actor:     r.Actor,
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// toRefundsDTO converts the refunds loaded with an order
+func (c *OrderConverter) toRefundsDTO(refunds []models.Refund) []dto.RefundResponseDTO {
+	result := make([]dto.RefundResponseDTO, 0, len(refunds))
+	for i := range refunds {
+		result = append(result, c.ToRefundResponseDTO(&refunds[i]))
+	}
+	return result
+}
+
+// toDiscountsDTO converts model OrderDiscounts to OrderDiscountDTOs
+func (c *OrderConverter) toDiscountsDTO(discounts models.OrderDiscounts, currency string) []dto.OrderDiscountDTO {
+	result := make([]dto.OrderDiscountDTO, 0, len(discounts))
+	for _, line := range discounts {
+		result = append(result, dto.OrderDiscountDTO{
+			Code:   line.Code,
+			Type:   string(line.Type),
+			ItemID: line.OrderItemID,
+			Amount: dto.MoneyDTO{Amount: line.Amount, Currency: currency},
+		})
+	}
+	return result
+}
+
+// toOrderItemLotsDTO converts model OrderItemLots to OrderItemLotDTOs
+func (c *OrderConverter) toOrderItemLotsDTO(lots models.OrderItemLots) []dto.OrderItemLotDTO {
+	result := make([]dto.OrderItemLotDTO, 0, len(lots))
+	for _, lot := range lots {
+		lotDTO := dto.OrderItemLotDTO{
+			LotID:     lot.LotID,
+			LotNumber: lot.LotNumber,
+			Quantity:  lot.Quantity,
+		}
+		if lot.ExpiresAt != nil {
+			lotDTO.ExpiresAt = lot.ExpiresAt.Format(models.DateLayout)
+		}
+		result = append(result, lotDTO)
+	}
+	return result
+}
+
 // toMoneyDTO converts model Money to MoneyDTO
 func (c *OrderConverter) toMoneyDTO(money models.Money) dto.MoneyDTO {
 	return dto.MoneyDTO{
@@ -1,6 +1,9 @@
 package converter
 
 import (
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"caviar/internal/dto"
@@ -27,12 +30,26 @@ func (c *ProductConverter) ToResponseDTO(product *models.Product) dto.ProductRes
 		Description: product.Description,
 		Variants:    c.toVariantResponseDTOs(product.Variants),
 		Details:     c.toCaviarDetailsDTO(product.Details),
+		Images:      c.ToImageResponseDTOs(product.Images),
 		IsActive:    product.IsActive,
+		Status:      string(product.Status),
+		PublishAt:   formatOptionalTime(product.PublishAt),
+		PublishedAt: formatOptionalTime(product.PublishedAt),
+		ArchivedAt:  formatOptionalTime(product.ArchivedAt),
 		CreatedAt:   product.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   product.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
+// formatOptionalTime formats t as RFC 3339, or returns nil when unset
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
 // ToResponseDTOs converts a slice of model Products to ProductResponseDTOs
 func (c *ProductConverter) ToResponseDTOs(products []*models.Product) []dto.ProductResponseDTO {
 	if len(products) == 0 {
@@ -46,6 +63,73 @@ func (c *ProductConverter) ToResponseDTOs(products []*models.Product) []dto.Prod
 	return result
 }
 
+// ToImageResponseDTOs converts model ProductImages to ProductImageResponseDTOs ordered by position
+func (c *ProductConverter) ToImageResponseDTOs(images models.ProductImages) []dto.ProductImageResponseDTO {
+	if len(images) == 0 {
+		return []dto.ProductImageResponseDTO{}
+	}
+
+	result := make([]dto.ProductImageResponseDTO, 0, len(images))
+	for _, image := range images.Sorted() {
+		result = append(result, c.ToImageResponseDTO(image))
+	}
+	return result
+}
+
+// ToImageResponseDTO converts a model ProductImage to ProductImageResponseDTO
+func (c *ProductConverter) ToImageResponseDTO(image models.ProductImage) dto.ProductImageResponseDTO {
+	return dto.ProductImageResponseDTO{
+		ID:          image.ID,
+		URL:         image.URL,
+		ContentType: image.ContentType,
+		Size:        image.Size,
+		Width:       image.Width,
+		Height:      image.Height,
+		Position:    image.Position,
+		IsPrimary:   image.IsPrimary,
+		Renditions:  c.toImageRenditionDTOs(image.Renditions),
+		SrcSet:      c.toSrcSet(image.Renditions),
+	}
+}
+
+// toImageRenditionDTOs converts model ImageRenditions to ProductImageRenditionDTOs ordered by width
+func (c *ProductConverter) toImageRenditionDTOs(renditions []models.ImageRendition) []dto.ProductImageRenditionDTO {
+	result := make([]dto.ProductImageRenditionDTO, 0, len(renditions))
+	for _, r := range renditions {
+		result = append(result, dto.ProductImageRenditionDTO{
+			Name:   r.Name,
+			Format: r.Format,
+			URL:    r.URL,
+			Width:  r.Width,
+			Height: r.Height,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Width < result[j].Width
+	})
+	return result
+}
+
+// toSrcSet builds a srcset value per format from the renditions
+func (c *ProductConverter) toSrcSet(renditions []models.ImageRendition) map[string]string {
+	byFormat := make(map[string][]dto.ProductImageRenditionDTO)
+	for _, r := range c.toImageRenditionDTOs(renditions) {
+		if r.URL != "" {
+			byFormat[r.Format] = append(byFormat[r.Format], r)
+		}
+	}
+
+	result := make(map[string]string, len(byFormat))
+	for format, items := range byFormat {
+		candidates := make([]string, 0, len(items))
+		for _, r := range items {
+			candidates = append(candidates, r.URL+" "+strconv.Itoa(r.Width)+"w")
+		}
+		result[format] = strings.Join(candidates, ", ")
+	}
+	return result
+}
+
 // toVariantResponseDTOs converts model Variants to VariantResponseDTOs
 func (c *ProductConverter) toVariantResponseDTOs(variants []models.Variant) []dto.VariantResponseDTO {
 	if len(variants) == 0 {
@@ -54,23 +138,43 @@ func (c *ProductConverter) toVariantResponseDTOs(variants []models.Variant) []dt
 
 	result := make([]dto.VariantResponseDTO, 0, len(variants))
 	for _, variant := range variants {
-		result = append(result, c.toVariantResponseDTO(variant))
+		result = append(result, c.ToVariantResponseDTO(variant))
 	}
 	return result
 }
 
-// toVariantResponseDTO converts a model Variant to VariantResponseDTO
-func (c *ProductConverter) toVariantResponseDTO(variant models.Variant) dto.VariantResponseDTO {
+// ToVariantResponseDTO converts a model Variant to VariantResponseDTO
+func (c *ProductConverter) ToVariantResponseDTO(variant models.Variant) dto.VariantResponseDTO {
 	return dto.VariantResponseDTO{
-		ID:        variant.ID,
-		Mass:      variant.Mass,
-		Stock:     variant.Stock,
-		Prices:    c.toMoneyDTOMap(variant.Prices),
-		CreatedAt: variant.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: variant.UpdatedAt.Format(time.RFC3339),
+		ID:                variant.ID,
+		Mass:              variant.Mass,
+		SKU:               variant.SKU,
+		Barcode:           variant.Barcode,
+		Stock:             variant.TotalStock(),
+		StockLevels:       c.toStockLevelDTOs(variant.Levels),
+		LowStockThreshold: variant.LowStockThreshold,
+		LowStock:          variant.IsLowOnStock(),
+		Prices:            c.toMoneyDTOMap(variant.Prices),
+		DerivedPrices:     c.toMoneyDTOMap(variant.DerivedPrices),
+		Version:           variant.Version,
+		CreatedAt:         variant.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         variant.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
+// toStockLevelDTOs converts model StockLevels to StockLevelResponseDTOs
+func (c *ProductConverter) toStockLevelDTOs(levels []models.StockLevel) []dto.StockLevelResponseDTO {
+	result := make([]dto.StockLevelResponseDTO, 0, len(levels))
+	for _, l := range levels {
+		result = append(result, dto.StockLevelResponseDTO{
+			WarehouseID:   l.WarehouseID,
+			WarehouseCode: l.WarehouseCode,
+			Quantity:      l.Quantity,
+		})
+	}
+	return result
+}
+
 // toMoneyDTOMap converts model MoneyMap to map[string]MoneyDTO
 func (c *ProductConverter) toMoneyDTOMap(prices models.MoneyMap) map[string]dto.MoneyDTO {
 	if len(prices) == 0 {
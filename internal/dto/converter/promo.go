@@ -0,0 +1,52 @@
+package converter
+
+import (
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+)
+
+type PromoConverter struct{}
+
+func NewPromoConverter() *PromoConverter {
+	return &PromoConverter{}
+}
+
+// ToResponseDTO converts a model PromoCode to PromoCodeResponseDTO
+func (c *PromoConverter) ToResponseDTO(p *models.PromoCode) dto.PromoCodeResponseDTO {
+	productIDs := []string(p.ProductIDs)
+	if productIDs == nil {
+		productIDs = []string{}
+	}
+	variantIDs := []string(p.VariantIDs)
+	if variantIDs == nil {
+		variantIDs = []string{}
+	}
+	return dto.PromoCodeResponseDTO{
+		ID:               p.ID,
+		Code:             p.Code,
+		Type:             string(p.Type),
+		Value:            p.Value,
+		Currency:         p.Currency,
+		MinOrderAmount:   p.MinOrderAmount,
+		StartsAt:         formatOptionalTime(p.StartsAt),
+		EndsAt:           formatOptionalTime(p.EndsAt),
+		UsageLimit:       p.UsageLimit,
+		PerCustomerLimit: p.PerCustomerLimit,
+		ProductIDs:       productIDs,
+		VariantIDs:       variantIDs,
+		IsActive:         p.IsActive,
+		CreatedAt:        p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToResponseDTOs converts a slice of PromoCodes to PromoCodeResponseDTOs
+func (c *PromoConverter) ToResponseDTOs(promos []*models.PromoCode) []dto.PromoCodeResponseDTO {
+	result := make([]dto.PromoCodeResponseDTO, 0, len(promos))
+	for _, p := range promos {
+		result = append(result, c.ToResponseDTO(p))
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+)
+
+type ShippingConverter struct{}
+
+func NewShippingConverter() *ShippingConverter {
+	return &ShippingConverter{}
+}
+
+// ToZoneResponseDTO converts a model ShippingZone to ShippingZoneResponseDTO
+func (c *ShippingConverter) ToZoneResponseDTO(z *models.ShippingZone) dto.ShippingZoneResponseDTO {
+	countries := []string(z.Countries)
+	if countries == nil {
+		countries = []string{}
+	}
+	return dto.ShippingZoneResponseDTO{
+		ID:        z.ID,
+		Code:      z.Code,
+		Name:      z.Name,
+		Countries: countries,
+		CreatedAt: z.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: z.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToZoneResponseDTOs converts a slice of ShippingZones to ShippingZoneResponseDTOs
+func (c *ShippingConverter) ToZoneResponseDTOs(zones []*models.ShippingZone) []dto.ShippingZoneResponseDTO {
+	result := make([]dto.ShippingZoneResponseDTO, 0, len(zones))
+	for _, z := range zones {
+		result = append(result, c.ToZoneResponseDTO(z))
+	}
+	return result
+}
+
+// ToRateResponseDTO converts a model ShippingRate to ShippingRateResponseDTO
+func (c *ShippingConverter) ToRateResponseDTO(r *models.ShippingRate) dto.ShippingRateResponseDTO {
+	resp := dto.ShippingRateResponseDTO{
+		ID:           r.ID,
+		ZoneID:       r.ZoneID,
+		DeliveryType: string(r.DeliveryType),
+		MaxWeight:    r.MaxWeight,
+		Price:        r.Price,
+		Currency:     r.Currency,
+		FreeFrom:     r.FreeFrom,
+		IsActive:     r.IsActive,
+		CreatedAt:    r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    r.UpdatedAt.Format(time.RFC3339),
+	}
+	if r.Zone != nil {
+		resp.ZoneCode = r.Zone.Code
+	}
+	return resp
+}
+
+// ToRateResponseDTOs converts a slice of ShippingRates to ShippingRateResponseDTOs
+func (c *ShippingConverter) ToRateResponseDTOs(rates []*models.ShippingRate) []dto.ShippingRateResponseDTO {
+	result := make([]dto.ShippingRateResponseDTO, 0, len(rates))
+	for _, r := range rates {
+		result = append(result, c.ToRateResponseDTO(r))
+	}
+	return result
+}
+
+// ToQuoteResponseDTO converts a model ShippingQuote to ShippingQuoteResponseDTO
+func (c *ShippingConverter) ToQuoteResponseDTO(q *models.ShippingQuote) dto.ShippingQuoteResponseDTO {
+	resp := dto.ShippingQuoteResponseDTO{
+		Zone:         q.Zone,
+		DeliveryType: string(q.DeliveryType),
+		Weight:       q.Weight,
+		Price:        dto.MoneyDTO{Amount: q.Price, Currency: q.Currency},
+		Charge:       dto.MoneyDTO{Amount: q.Charge(), Currency: q.Currency},
+		Free:         q.Free,
+	}
+	if q.FreeFrom > 0 {
+		resp.FreeFrom = &dto.MoneyDTO{Amount: q.FreeFrom, Currency: q.Currency}
+	}
+	return resp
+}
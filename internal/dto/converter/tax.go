@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+)
+
+type TaxConverter struct{}
+
+func NewTaxConverter() *TaxConverter {
+	return &TaxConverter{}
+}
+
+// ToResponseDTO converts a model TaxRate to TaxRateResponseDTO
+func (c *TaxConverter) ToResponseDTO(t *models.TaxRate) dto.TaxRateResponseDTO {
+	return dto.TaxRateResponseDTO{
+		ID:        t.ID,
+		Country:   t.Country,
+		Rate:      t.Rate,
+		Inclusive: t.Inclusive,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: t.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToResponseDTOs converts a slice of TaxRates to TaxRateResponseDTOs
+func (c *TaxConverter) ToResponseDTOs(rates []*models.TaxRate) []dto.TaxRateResponseDTO {
+	result := make([]dto.TaxRateResponseDTO, 0, len(rates))
+	for _, t := range rates {
+		result = append(result, c.ToResponseDTO(t))
+	}
+	return result
+}
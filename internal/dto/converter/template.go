@@ -0,0 +1,47 @@
+package converter
+
+import (
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+)
+
+type TemplateConverter struct{}
+
+func NewTemplateConverter() *TemplateConverter {
+	return &TemplateConverter{}
+}
+
+// ToResponseDTO converts a model NotificationTemplate to NotificationTemplateResponseDTO
+func (c *TemplateConverter) ToResponseDTO(t *models.NotificationTemplate) dto.NotificationTemplateResponseDTO {
+	if t == nil {
+		return dto.NotificationTemplateResponseDTO{}
+	}
+
+	response := dto.NotificationTemplateResponseDTO{
+		Event:     t.Event,
+		Channel:   t.Channel,
+		Locale:    t.Locale,
+		Body:      t.Body,
+		IsDefault: t.IsDefault,
+	}
+	if !t.IsDefault {
+		response.UpdatedAt = t.UpdatedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+// ToResponseDTOs converts a slice of model NotificationTemplates to NotificationTemplateResponseDTOs
+func (c *TemplateConverter) ToResponseDTOs(templates []*models.NotificationTemplate) []dto.NotificationTemplateResponseDTO {
+	if len(templates) == 0 {
+		return []dto.NotificationTemplateResponseDTO{}
+	}
+
+	result := make([]dto.NotificationTemplateResponseDTO, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, c.ToResponseDTO(t))
+	}
+	return result
+}
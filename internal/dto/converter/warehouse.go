@@ -0,0 +1,42 @@
+package converter
+
+import (
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+)
+
+type WarehouseConverter struct{}
+
+func NewWarehouseConverter() *WarehouseConverter {
+	return &WarehouseConverter{}
+}
+
+// ToResponseDTO converts a model Warehouse to WarehouseResponseDTO
+func (c *WarehouseConverter) ToResponseDTO(w *models.Warehouse) dto.WarehouseResponseDTO {
+	countries := []string(w.Countries)
+	if countries == nil {
+		countries = []string{}
+	}
+	return dto.WarehouseResponseDTO{
+		ID:        w.ID,
+		Code:      w.Code,
+		Name:      w.Name,
+		Countries: countries,
+		Priority:  w.Priority,
+		IsDefault: w.IsDefault,
+		IsActive:  w.IsActive,
+		CreatedAt: w.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: w.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToResponseDTOs converts a slice of Warehouses to WarehouseResponseDTOs
+func (c *WarehouseConverter) ToResponseDTOs(warehouses []*models.Warehouse) []dto.WarehouseResponseDTO {
+	result := make([]dto.WarehouseResponseDTO, 0, len(warehouses))
+	for _, w := range warehouses {
+		result = append(result, c.ToResponseDTO(w))
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "encoding/json"
+
+type ExchangeRateCreateDTO struct {
+	// Base and Quote are ISO 4217 codes; one unit of Base buys Rate units of Quote
+	Base  string      `json:"base" binding:"required"`
+	Quote string      `json:"quote" binding:"required"`
+	Rate  json.Number `json:"rate" binding:"required" swaggertype:"number"`
+	// EffectiveOn is the date the rate applies from, YYYY-MM-DD; empty means today
+	EffectiveOn string `json:"effective_on"`
+}
+
+type ExchangeRateResponseDTO struct {
+	ID          string      `json:"id"`
+	Base        string      `json:"base"`
+	Quote       string      `json:"quote"`
+	Rate        json.Number `json:"rate" swaggertype:"number"`
+	EffectiveOn string      `json:"effective_on"`
+	Source      string      `json:"source"`
+	CreatedAt   string      `json:"created_at"`
+	UpdatedAt   string      `json:"updated_at"`
+}
+
+type ExchangeRateImportResponseDTO struct {
+	Imported int                       `json:"imported"`
+	Rates    []ExchangeRateResponseDTO `json:"rates"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+type NotificationTemplateUpsertDTO struct {
+	Event   string `json:"event"`
+	Channel string `json:"channel"`
+	Locale  string `json:"locale"`
+	Body    string `json:"body" binding:"required"`
+}
+
+type NotificationTemplateResponseDTO struct {
+	Event     string `json:"event"`
+	Channel   string `json:"channel"`
+	Locale    string `json:"locale"`
+	Body      string `json:"body"`
+	IsDefault bool   `json:"isDefault"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
@@ -4,6 +4,9 @@ type OrderCreateDTO struct {
 	CustomerInfo CustomerInfoDTO  `json:"customerInfo" binding:"required"`
 	DeliveryInfo DeliveryInfoDTO  `json:"deliveryInfo" binding:"required"`
 	Items        []OrderItemDTO   `json:"items" binding:"required,min=1"`
+	// PaymentMethod is cod (the default), card or bank_transfer
+	PaymentMethod string          `json:"paymentMethod" binding:"omitempty,oneof=cod card bank_transfer"`
+	PromoCode    string           `json:"promoCode"`
 	Notes        string           `json:"notes"`
 }
 
@@ -13,6 +16,7 @@ type CustomerInfoDTO struct {
 	FullName  string `json:"fullName"`
 	Phone     string `json:"phone" binding:"required"`
 	Email     string `json:"email"`
+	Language  string `json:"language"`
 }
 
 type DeliveryInfoDTO struct {
@@ -41,6 +45,26 @@ type OrderResponseDTO struct {
 	TotalAmount  MoneyDTO             `json:"totalAmount"`
 	Status       string               `json:"status"`
 	Notes        string               `json:"notes"`
+	TrackingNumber string             `json:"trackingNumber,omitempty"`
+	WarehouseID  string               `json:"warehouseId,omitempty"`
+	// HoldExpiresAt is when an unpaid pending order is cancelled and its stock released
+	HoldExpiresAt *string             `json:"holdExpiresAt,omitempty"`
+	PaymentMethod string              `json:"paymentMethod"`
+	// PaymentFee is the payment method surcharge included in TotalAmount
+	PaymentFee   MoneyDTO             `json:"paymentFee"`
+	// ShippingCost is the delivery charge included in TotalAmount
+	ShippingCost MoneyDTO             `json:"shippingCost"`
+	// ShippingWeight is the parcel weight in grams
+	ShippingWeight int                `json:"shippingWeight"`
+	PromoCode    string               `json:"promoCode,omitempty"`
+	// Discounts are the lines taken off TotalAmount; DiscountAmount is their sum
+	Discounts    []OrderDiscountDTO   `json:"discounts"`
+	DiscountAmount MoneyDTO           `json:"discountAmount"`
+	// Tax splits TotalAmount into net and VAT at the delivery country's rate
+	Tax          TaxBreakdownDTO      `json:"tax"`
+	PaidAt       *string              `json:"paidAt,omitempty"`
+	Payments     []PaymentResponseDTO `json:"payments"`
+	Refunds      []RefundResponseDTO  `json:"refunds"`
 	CreatedAt    string               `json:"createdAt"`
 	UpdatedAt    string               `json:"updatedAt"`
 }
@@ -49,14 +73,27 @@ type OrderItemResponseDTO struct {
 	ID         string      `json:"id"`
 	ProductID  string      `json:"productId"`
 	VariantID  string      `json:"variantId"`
+	SKU        string      `json:"sku,omitempty"`
+	Barcode    string      `json:"barcode,omitempty"`
 	Quantity   int         `json:"quantity"`
 	UnitPrice  MoneyDTO    `json:"unitPrice"`
 	TotalPrice MoneyDTO    `json:"totalPrice"`
+	// Tax splits what is paid for the item after discounts
+	Tax        LineTaxDTO  `json:"tax"`
+	Lots       []OrderItemLotDTO `json:"lots"`
 	Product    *ProductResponseDTO `json:"product,omitempty"`
 }
 
+type OrderItemLotDTO struct {
+	LotID     string `json:"lotId"`
+	LotNumber string `json:"lotNumber,omitempty"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
 type OrderStatusUpdateDTO struct {
-	Status string `json:"status" binding:"required,oneof=pending confirmed processing shipped delivered cancelled"`
+	Status         string `json:"status" binding:"required,oneof=pending confirmed processing shipped delivered cancelled"`
+	TrackingNumber string `json:"trackingNumber"`
 }
 
 type OrderListResponseDTO struct {
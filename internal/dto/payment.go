@@ -0,0 +1,17 @@
+package dto
+
+type PaymentResponseDTO struct {
+	ID         string   `json:"id"`
+	OrderID    string   `json:"orderId"`
+	Provider   string   `json:"provider"`
+	ExternalID string   `json:"externalId,omitempty"`
+	Amount     MoneyDTO `json:"amount"`
+	Status     string   `json:"status"`
+	// CheckoutURL is the hosted page the customer pays on
+	CheckoutURL    string   `json:"checkoutUrl,omitempty"`
+	FailureReason  string   `json:"failureReason,omitempty"`
+	RefundedAmount MoneyDTO `json:"refundedAmount"`
+	PaidAt         *string  `json:"paidAt,omitempty"`
+	CreatedAt      string   `json:"createdAt"`
+	UpdatedAt      string   `json:"updatedAt"`
+}
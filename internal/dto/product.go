@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type ProductCreateDTO struct {
     Slug        string `json:"slug"`
     Name        string `json:"name"`
@@ -10,8 +12,13 @@ type ProductCreateDTO struct {
 }
 
 type VariantCreateDTO struct {
-    Mass   int `json:"mass"`
-    Stock  int `json:"stock"`
+    Mass    int    `json:"mass"`
+    SKU     string `json:"sku,omitempty"`
+    // Barcode is an EAN-13
+    Barcode string `json:"barcode,omitempty"`
+    Stock   int    `json:"stock"`
+    // LowStockThreshold alerts staff when stock drops to it; 0 disables the alert
+    LowStockThreshold int `json:"low_stock_threshold,omitempty"`
     Prices map[string]MoneyDTO `json:"prices"`
 }
 
@@ -50,6 +57,18 @@ type ProductUpdateDTO struct {
     Details     *CaviarDetailsDTO `json:"details,omitempty"`
 }
 
+// VariantPatchDTO changes the fields that are set. Version must match the
+// variant's current version, otherwise the request fails with 409.
+type VariantPatchDTO struct {
+    Mass    *int                `json:"mass,omitempty"`
+    SKU     *string             `json:"sku,omitempty"`
+    Barcode *string             `json:"barcode,omitempty"`
+    Stock   *int                `json:"stock,omitempty"`
+    LowStockThreshold *int      `json:"low_stock_threshold,omitempty"`
+    Prices  map[string]MoneyDTO `json:"prices,omitempty"`
+    Version int                 `json:"version" binding:"required"`
+}
+
 type VariantUpdateDTO struct {
     ID     string `json:"id,omitempty"`
     Mass   int    `json:"mass,omitempty"`
@@ -65,7 +84,12 @@ type ProductResponseDTO struct {
     Description string `json:"description"`
     Variants    []VariantResponseDTO `json:"variants"`
     Details     CaviarDetailsDTO `json:"details"`
+    Images      []ProductImageResponseDTO `json:"images"`
     IsActive    bool `json:"is_active"`
+    Status      string `json:"status"`
+    PublishAt   *string `json:"publish_at,omitempty"`
+    PublishedAt *string `json:"published_at,omitempty"`
+    ArchivedAt  *string `json:"archived_at,omitempty"`
     CreatedAt   string `json:"created_at"`
     UpdatedAt   string `json:"updated_at"`
 }
@@ -73,8 +97,58 @@ type ProductResponseDTO struct {
 type VariantResponseDTO struct {
     ID        string `json:"id"`
     Mass      int    `json:"mass"`
+    SKU       string `json:"sku"`
+    Barcode   string `json:"barcode"`
+    // Stock is the sum of StockLevels
     Stock     int    `json:"stock"`
+    StockLevels []StockLevelResponseDTO `json:"stock_levels"`
+    LowStockThreshold int `json:"low_stock_threshold"`
+    LowStock  bool   `json:"low_stock"`
     Prices    map[string]MoneyDTO `json:"prices"`
+    // DerivedPrices are converted from the base region's price at the
+    // current exchange rate, for the regions without a price of their own
+    DerivedPrices map[string]MoneyDTO `json:"derived_prices"`
+    Version   int    `json:"version"`
     CreatedAt string `json:"created_at"`
     UpdatedAt string `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+type ProductImageResponseDTO struct {
+    ID          string `json:"id"`
+    URL         string `json:"url"`
+    ContentType string `json:"content_type"`
+    Size        int64  `json:"size"`
+    Width       int    `json:"width"`
+    Height      int    `json:"height"`
+    Position    int    `json:"position"`
+    IsPrimary   bool   `json:"is_primary"`
+    Renditions  []ProductImageRenditionDTO `json:"renditions"`
+    // SrcSet holds a ready-to-use srcset value per format, e.g.
+    // {"webp": "https://.../a_thumb.webp 320w, https://.../a_card.webp 800w"}
+    SrcSet      map[string]string `json:"srcset"`
+}
+
+type ProductImageRenditionDTO struct {
+    Name   string `json:"name"`
+    Format string `json:"format"`
+    URL    string `json:"url"`
+    Width  int    `json:"width"`
+    Height int    `json:"height"`
+}
+
+// ProductPublishDTO schedules the publication when PublishAt is set,
+// otherwise the product is published immediately.
+type ProductPublishDTO struct {
+    PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+// VariantLookupResponseDTO is a variant found by SKU or barcode together
+// with its product.
+type VariantLookupResponseDTO struct {
+    Product ProductResponseDTO `json:"product"`
+    Variant VariantResponseDTO `json:"variant"`
+}
+
+type ProductImageReorderDTO struct {
+    ImageIDs []string `json:"image_ids" binding:"required,min=1"`
+}
@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+type PromoCodeCreateDTO struct {
+	Code string `json:"code" binding:"required"`
+	// Type is percentage, fixed or free_shipping
+	Type string `json:"type" binding:"required,oneof=percentage fixed free_shipping"`
+	// Value is the percentage off for percentage codes and the amount off for fixed ones
+	Value int `json:"value"`
+	// Currency is the currency of a fixed Value and of MinOrderAmount
+	Currency       string     `json:"currency"`
+	MinOrderAmount int        `json:"min_order_amount"`
+	StartsAt       *time.Time `json:"starts_at,omitempty"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	// UsageLimit caps the orders using the code; 0 means unlimited
+	UsageLimit int `json:"usage_limit"`
+	// PerCustomerLimit caps the orders per customer phone; 0 means unlimited
+	PerCustomerLimit int `json:"per_customer_limit"`
+	// ProductIDs and VariantIDs restrict the discount to those items; empty means all
+	ProductIDs []string `json:"product_ids"`
+	VariantIDs []string `json:"variant_ids"`
+}
+
+// PromoCodePatchDTO changes the fields that are set. The code and its
+// type cannot change once orders may have used it.
+type PromoCodePatchDTO struct {
+	Value            *int       `json:"value,omitempty"`
+	Currency         *string    `json:"currency,omitempty"`
+	MinOrderAmount   *int       `json:"min_order_amount,omitempty"`
+	StartsAt         *time.Time `json:"starts_at,omitempty"`
+	EndsAt           *time.Time `json:"ends_at,omitempty"`
+	UsageLimit       *int       `json:"usage_limit,omitempty"`
+	PerCustomerLimit *int       `json:"per_customer_limit,omitempty"`
+	ProductIDs       []string   `json:"product_ids,omitempty"`
+	VariantIDs       []string   `json:"variant_ids,omitempty"`
+	IsActive         *bool      `json:"is_active,omitempty"`
+}
+
+type PromoCodeResponseDTO struct {
+	ID               string   `json:"id"`
+	Code             string   `json:"code"`
+	Type             string   `json:"type"`
+	Value            int      `json:"value"`
+	Currency         string   `json:"currency,omitempty"`
+	MinOrderAmount   int      `json:"min_order_amount"`
+	StartsAt         *string  `json:"starts_at,omitempty"`
+	EndsAt           *string  `json:"ends_at,omitempty"`
+	UsageLimit       int      `json:"usage_limit"`
+	PerCustomerLimit int      `json:"per_customer_limit"`
+	ProductIDs       []string `json:"product_ids"`
+	VariantIDs       []string `json:"variant_ids"`
+	IsActive         bool     `json:"is_active"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+}
+
+// OrderDiscountDTO is a discount line of an order. Amount lines are
+// per item; a free shipping code has a single line without an item.
+type OrderDiscountDTO struct {
+	Code   string   `json:"code"`
+	Type   string   `json:"type"`
+	ItemID string   `json:"itemId,omitempty"`
+	Amount MoneyDTO `json:"amount"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+type OrderRefundCreateDTO struct {
+	Items  []OrderRefundItemDTO `json:"items" binding:"required,min=1,dive"`
+	Reason string               `json:"reason" binding:"required,max=500"`
+}
+
+type OrderRefundItemDTO struct {
+	ItemID   string `json:"itemId" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+	// Restock puts returned items back on sale; leave it off for damaged
+	// returns. Cancelled items are always restocked.
+	Restock bool `json:"restock"`
+}
+
+type RefundResponseDTO struct {
+	ID      string `json:"id"`
+	OrderID string `json:"orderId"`
+	// Kind is cancellation before shipping, return after
+	Kind      string                  `json:"kind"`
+	Amount    MoneyDTO                `json:"amount"`
+	PaymentID string                  `json:"paymentId,omitempty"`
+	Items     []RefundItemResponseDTO `json:"items"`
+	Reason    string                  `json:"reason"`
+	Actor     string                  `json:"actor"`
+	CreatedAt string                  `json:"createdAt"`
+}
+
+type RefundItemResponseDTO struct {
+	ItemID    string            `json:"itemId"`
+	VariantID string            `json:"variantId"`
+	Quantity  int               `json:"quantity"`
+	Amount    MoneyDTO          `json:"amount"`
+	Restock   bool              `json:"restock"`
+	Lots      []OrderItemLotDTO `json:"lots"`
+}
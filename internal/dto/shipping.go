@@ -0,0 +1,74 @@
+package dto
+
+type ShippingZoneCreateDTO struct {
+	Code string `json:"code" binding:"required"`
+	Name string `json:"name" binding:"required"`
+	// Countries are ISO 3166-1 alpha-2 codes; empty means every country no other zone lists
+	Countries []string `json:"countries"`
+}
+
+// ShippingZonePatchDTO changes the fields that are set.
+type ShippingZonePatchDTO struct {
+	Name      *string  `json:"name,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+}
+
+type ShippingZoneResponseDTO struct {
+	ID        string   `json:"id"`
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Countries []string `json:"countries"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+type ShippingRateCreateDTO struct {
+	ZoneID       string `json:"zone_id" binding:"required"`
+	DeliveryType string `json:"delivery_type" binding:"required,oneof=post_office courier address"`
+	// MaxWeight is the heaviest parcel in grams the rate applies to; 0 means no limit
+	MaxWeight int    `json:"max_weight"`
+	Price     int    `json:"price"`
+	Currency  string `json:"currency" binding:"required"`
+	// FreeFrom is the order amount after discounts from which delivery is free; 0 means never
+	FreeFrom int `json:"free_from"`
+}
+
+// ShippingRatePatchDTO changes the fields that are set.
+type ShippingRatePatchDTO struct {
+	MaxWeight *int  `json:"max_weight,omitempty"`
+	Price     *int  `json:"price,omitempty"`
+	FreeFrom  *int  `json:"free_from,omitempty"`
+	IsActive  *bool `json:"is_active,omitempty"`
+}
+
+type ShippingRateResponseDTO struct {
+	ID           string `json:"id"`
+	ZoneID       string `json:"zone_id"`
+	ZoneCode     string `json:"zone_code,omitempty"`
+	DeliveryType string `json:"delivery_type"`
+	MaxWeight    int    `json:"max_weight"`
+	Price        int    `json:"price"`
+	Currency     string `json:"currency"`
+	FreeFrom     int    `json:"free_from"`
+	IsActive     bool   `json:"is_active"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+type ShippingQuoteRequestDTO struct {
+	DeliveryType string         `json:"deliveryType" binding:"required,oneof=post_office courier address"`
+	Country      string         `json:"country" binding:"required"`
+	Items        []OrderItemDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+type ShippingQuoteResponseDTO struct {
+	Zone         string `json:"zone"`
+	DeliveryType string `json:"deliveryType"`
+	// Weight is the parcel weight in grams, packaging and ice packs included
+	Weight int      `json:"weight"`
+	Price  MoneyDTO `json:"price"`
+	// Charge is what the customer pays: zero when delivery is free
+	Charge   MoneyDTO  `json:"charge"`
+	FreeFrom *MoneyDTO `json:"freeFrom,omitempty"`
+	Free     bool      `json:"free"`
+}
@@ -0,0 +1,87 @@
+package dto
+
+type StockMovementCreateDTO struct {
+	// Delta is positive for stock coming in and negative for stock going out
+	Delta       int    `json:"delta" binding:"required"`
+	Reason      string `json:"reason" binding:"required"`
+	ReferenceID string `json:"reference_id,omitempty"`
+	Note        string `json:"note,omitempty"`
+	// LotID books the movement against one lot. Without it stock coming
+	// in goes to the unlabelled lot and stock going out is taken FEFO.
+	LotID string `json:"lot_id,omitempty"`
+	// WarehouseID limits the movement to one warehouse; stock coming in
+	// goes to the default warehouse when it is not set.
+	WarehouseID string `json:"warehouse_id,omitempty"`
+}
+
+type StockMovementResponseDTO struct {
+	ID          string `json:"id"`
+	VariantID   string `json:"variant_id"`
+	ProductID   string `json:"product_id"`
+	LotID       string `json:"lot_id,omitempty"`
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	Delta       int    `json:"delta"`
+	Reason      string `json:"reason"`
+	ReferenceID string `json:"reference_id,omitempty"`
+	Actor       string `json:"actor"`
+	Note        string `json:"note,omitempty"`
+	Balance     int    `json:"balance"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type StockMovementListResponseDTO struct {
+	Movements []StockMovementResponseDTO `json:"movements"`
+	Total     int                        `json:"total"`
+	Page      int                        `json:"page"`
+	Limit     int                        `json:"limit"`
+}
+
+type StockDiscrepancyResponseDTO struct {
+	VariantID   string `json:"variant_id"`
+	ProductID   string `json:"product_id"`
+	SKU         string `json:"sku,omitempty"`
+	Stock       int    `json:"stock"`
+	LedgerStock int    `json:"ledger_stock"`
+	LotStock    int    `json:"lot_stock"`
+	Difference  int    `json:"difference"`
+}
+
+type StockLotCreateDTO struct {
+	LotNumber string `json:"lot_number" binding:"required"`
+	// PackedAt is the harvest or packing date, YYYY-MM-DD
+	PackedAt string `json:"packed_at,omitempty"`
+	// ExpiresAt is the expiry date, YYYY-MM-DD
+	ExpiresAt string `json:"expires_at" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+	Note      string `json:"note,omitempty"`
+	// WarehouseID is where the lot is received; the default warehouse
+	// when not set
+	WarehouseID string `json:"warehouse_id,omitempty"`
+}
+
+type StockLotResponseDTO struct {
+	ID               string `json:"id"`
+	VariantID        string `json:"variant_id"`
+	ProductID        string `json:"product_id"`
+	ProductName      string `json:"product_name,omitempty"`
+	SKU              string `json:"sku,omitempty"`
+	WarehouseID      string `json:"warehouse_id"`
+	WarehouseCode    string `json:"warehouse_code,omitempty"`
+	LotNumber        string `json:"lot_number"`
+	PackedAt         string `json:"packed_at,omitempty"`
+	ExpiresAt        string `json:"expires_at,omitempty"`
+	DaysToExpiry     *int   `json:"days_to_expiry,omitempty"`
+	Expired          bool   `json:"expired"`
+	ReceivedQuantity int    `json:"received_quantity"`
+	Quantity         int    `json:"quantity"`
+	CreatedAt        string `json:"created_at"`
+}
+
+type StockTransferCreateDTO struct {
+	FromWarehouseID string `json:"from_warehouse_id" binding:"required"`
+	ToWarehouseID   string `json:"to_warehouse_id" binding:"required"`
+	Quantity        int    `json:"quantity" binding:"required,min=1"`
+	// LotID transfers from one lot; otherwise lots are taken FEFO
+	LotID string `json:"lot_id,omitempty"`
+	Note  string `json:"note,omitempty"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "encoding/json"
+
+type TaxRateCreateDTO struct {
+	// Country is an ISO 3166-1 alpha-2 code
+	Country string `json:"country" binding:"required"`
+	// Rate is in basis points: 2000 is 20%
+	Rate int `json:"rate"`
+	// Inclusive means prices already contain the tax; otherwise it is added on top
+	Inclusive bool `json:"inclusive"`
+}
+
+// TaxRatePatchDTO changes the fields that are set.
+type TaxRatePatchDTO struct {
+	Rate      *int  `json:"rate,omitempty"`
+	Inclusive *bool `json:"inclusive,omitempty"`
+}
+
+type TaxRateResponseDTO struct {
+	ID        string `json:"id"`
+	Country   string `json:"country"`
+	Rate      int    `json:"rate"`
+	Inclusive bool   `json:"inclusive"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// TaxBreakdownDTO splits an amount into its net and tax parts.
+type TaxBreakdownDTO struct {
+	// Rate is in basis points: 2000 is 20%
+	Rate      int      `json:"rate"`
+	Inclusive bool     `json:"inclusive"`
+	Net       MoneyDTO `json:"net"`
+	Tax       MoneyDTO `json:"tax"`
+	Gross     MoneyDTO `json:"gross"`
+}
+
+// LineTaxDTO splits what is paid for an order item. The amounts are
+// exact decimals; only the order's totals are rounded to the currency unit.
+type LineTaxDTO struct {
+	// Rate is in basis points: 2000 is 20%
+	Rate      int         `json:"rate"`
+	Inclusive bool        `json:"inclusive"`
+	Currency  string      `json:"currency"`
+	Net       json.Number `json:"net" swaggertype:"number"`
+	Tax       json.Number `json:"tax" swaggertype:"number"`
+	Gross     json.Number `json:"gross" swaggertype:"number"`
+}
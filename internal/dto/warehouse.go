@@ -0,0 +1,39 @@
+package dto
+
+type WarehouseCreateDTO struct {
+	Code string `json:"code" binding:"required"`
+	Name string `json:"name" binding:"required"`
+	// Countries are ISO 3166-1 alpha-2 codes the warehouse ships to; empty means anywhere
+	Countries []string `json:"countries"`
+	// Priority orders warehouses shipping to the same country; lower goes first
+	Priority  int  `json:"priority"`
+	IsDefault bool `json:"is_default"`
+}
+
+// WarehousePatchDTO changes the fields that are set.
+type WarehousePatchDTO struct {
+	Code      *string  `json:"code,omitempty"`
+	Name      *string  `json:"name,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+	Priority  *int     `json:"priority,omitempty"`
+	IsDefault *bool    `json:"is_default,omitempty"`
+	IsActive  *bool    `json:"is_active,omitempty"`
+}
+
+type WarehouseResponseDTO struct {
+	ID        string   `json:"id"`
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Countries []string `json:"countries"`
+	Priority  int      `json:"priority"`
+	IsDefault bool     `json:"is_default"`
+	IsActive  bool     `json:"is_active"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+type StockLevelResponseDTO struct {
+	WarehouseID   string `json:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code"`
+	Quantity      int    `json:"quantity"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// decimalPlaces is the scale of the numeric columns Decimal is stored in.
+const decimalPlaces = 10
+
+// Decimal is an exact decimal number, stored in a numeric column. The
+// zero value is 0.
+type Decimal struct {
+	rat *big.Rat
+}
+
+// newDecimal returns r rounded to the places the columns keep.
+func newDecimal(r *big.Rat) Decimal {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(decimalPlaces), nil))
+	scaled := new(big.Rat).SetInt(roundRatInt(new(big.Rat).Mul(r, scale)))
+	return Decimal{rat: scaled.Quo(scaled, scale)}
+}
+
+// ParseDecimal parses a decimal number such as "44.1" exactly. Fractions
+// and exponents are refused, the latter as they can be made to allocate
+// numbers of any size.
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/eE") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{rat: r}, nil
+}
+
+// Rat returns the number as a big.Rat the caller may modify.
+func (d Decimal) Rat() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(d.rat)
+}
+
+func (d Decimal) Sign() int {
+	if d.rat == nil {
+		return 0
+	}
+	return d.rat.Sign()
+}
+
+// Places returns the number of decimal places d needs, or -1 if it
+// needs more than the columns keep.
+func (d Decimal) Places() int {
+	r := d.Rat()
+	for places := 0; places <= decimalPlaces; places++ {
+		if r.IsInt() {
+			return places
+		}
+		r.Mul(r, big.NewRat(10, 1))
+	}
+	return -1
+}
+
+// String formats d without trailing zeros, e.g. "44.1".
+func (d Decimal) String() string {
+	s := d.Rat().FloatString(decimalPlaces)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+func (d *Decimal) Scan(value any) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		*d = Decimal{rat: new(big.Rat).SetInt64(v)}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", value)
+	}
+
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// roundRat rounds r to the nearest integer, halves away from zero.
+func roundRat(r *big.Rat) int64 {
+	return roundRatInt(r).Int64()
+}
+
+// roundRatInt is roundRat without the int64 limit.
+func roundRatInt(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return quo
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"44.1", "44.1", false},
+		{" 1.2500 ", "1.25", false},
+		{"0", "0", false},
+		{"-3.5", "-3.5", false},
+		{"1000000", "1000000", false},
+		{".5", "0.5", false},
+		{"", "", true},
+		{"  ", "", true},
+		{"abc", "", true},
+		{"1/3", "", true},
+		{"1e3", "", true},
+		{"1E-3", "", true},
+		{"1,5", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDecimal(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseDecimal(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalPlaces(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"12", 0},
+		{"1.5", 1},
+		{"0.0000000001", 10},
+		{"0.00000000001", -1},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.input)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q): %v", tt.input, err)
+		}
+		if got := d.Places(); got != tt.want {
+			t.Errorf("Places(%s) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		num, denom int64
+		want       int64
+	}{
+		{0, 1, 0},
+		{7, 1, 7},
+		{1, 3, 0},
+		{1, 2, 1},
+		{3, 2, 2},
+		{5, 2, 3},
+		{2, 3, 1},
+		{-1, 3, 0},
+		{-1, 2, -1},
+		{-5, 2, -3},
+		{-2, 3, -1},
+		{999999, 1000000, 1},
+	}
+	for _, tt := range tests {
+		r := big.NewRat(tt.num, tt.denom)
+		if got := roundRat(r); got != tt.want {
+			t.Errorf("roundRat(%s) = %d, want %d", r.RatString(), got, tt.want)
+		}
+	}
+}
+
+func TestNewDecimal(t *testing.T) {
+	tests := []struct {
+		num, denom int64
+		want       string
+	}{
+		{1, 6, "0.1666666667"},
+		{-1, 6, "-0.1666666667"},
+		{1, 5, "0.2"},
+		{5, 1, "5"},
+	}
+	for _, tt := range tests {
+		r := big.NewRat(tt.num, tt.denom)
+		if got := newDecimal(r).String(); got != tt.want {
+			t.Errorf("newDecimal(%s) = %s, want %s", r.RatString(), got, tt.want)
+		}
+	}
+}
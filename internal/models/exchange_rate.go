@@ -0,0 +1,173 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+type ExchangeRateSource string
+
+const (
+	ExchangeRateSourceManual ExchangeRateSource = "manual"
+	ExchangeRateSourceImport ExchangeRateSource = "import"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// maxExchangeRate bounds rates to the ten integer digits of the column.
+var maxExchangeRate = big.NewRat(10_000_000_000, 1)
+
+// ExchangeRate is how many units of Quote one unit of Base buys, from
+// EffectiveOn until the next rate of the pair. There is one rate per
+// pair and day.
+type ExchangeRate struct {
+	ID          string             `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Base        string             `gorm:"type:varchar(3);not null"`
+	Quote       string             `gorm:"type:varchar(3);not null"`
+	Rate        Decimal            `gorm:"type:numeric(20,10);not null"`
+	EffectiveOn time.Time          `gorm:"type:date;not null"`
+	Source      ExchangeRateSource `gorm:"type:varchar(20);not null"`
+	CreatedAt   time.Time          `gorm:"not null;default:now()"`
+	UpdatedAt   time.Time          `gorm:"not null;default:now()"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// NewExchangeRate validates a rate set by staff or read from an import.
+// Without a date the rate takes effect today.
+func NewExchangeRate(input dto.ExchangeRateCreateDTO, source ExchangeRateSource) (*ExchangeRate, error) {
+	base := strings.ToUpper(strings.TrimSpace(input.Base))
+	quote := strings.ToUpper(strings.TrimSpace(input.Quote))
+	if !currencyCodePattern.MatchString(base) || !currencyCodePattern.MatchString(quote) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "currencies must be ISO 4217 codes")
+	}
+	if base == quote {
+		return nil, apperror.New(apperror.CodeInvalidInput, "base and quote currencies must differ")
+	}
+	rate, err := ParseDecimal(input.Rate.String())
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInvalidInput, "rate must be a decimal number")
+	}
+	if rate.Sign() <= 0 || rate.Rat().Cmp(maxExchangeRate) >= 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "rate must be greater than 0 and less than 10000000000")
+	}
+	if rate.Places() < 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput,
+			fmt.Sprintf("rate must have at most %d decimal places", decimalPlaces))
+	}
+
+	now := time.Now().UTC()
+	effectiveOn := now.Truncate(24 * time.Hour)
+	if input.EffectiveOn != "" {
+		date, err := time.Parse(DateLayout, input.EffectiveOn)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInvalidInput, "effective_on must be a date in YYYY-MM-DD format")
+		}
+		effectiveOn = date
+	}
+
+	return &ExchangeRate{
+		ID:          uuid.New().String(),
+		Base:        base,
+		Quote:       quote,
+		Rate:        rate,
+		EffectiveOn: effectiveOn,
+		Source:      source,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// ExchangeRates converts amounts between currencies with the rates in
+// effect on a date.
+type ExchangeRates []*ExchangeRate
+
+// RateOn returns how many units of to one unit of from buys on a date:
+// the latest rate of the pair effective by then, or the inverse of the
+// reverse pair's if there is none. The rate is exact.
+func (r ExchangeRates) RateOn(from, to string, on time.Time) (*big.Rat, bool) {
+	if from == to {
+		return big.NewRat(1, 1), true
+	}
+
+	var direct, inverse *ExchangeRate
+	for _, rate := range r {
+		if rate.EffectiveOn.After(on) {
+			continue
+		}
+		switch {
+		case rate.Base == from && rate.Quote == to:
+			if direct == nil || rate.EffectiveOn.After(direct.EffectiveOn) {
+				direct = rate
+			}
+		case rate.Base == to && rate.Quote == from:
+			if inverse == nil || rate.EffectiveOn.After(inverse.EffectiveOn) {
+				inverse = rate
+			}
+		}
+	}
+
+	switch {
+	case direct != nil:
+		return direct.Rate.Rat(), true
+	case inverse != nil:
+		rate := inverse.Rate.Rat()
+		return rate.Inv(rate), true
+	}
+	return nil, false
+}
+
+// Convert returns m in currency to at the rate of the given date,
+// rounded to the smallest currency unit.
+func (r ExchangeRates) Convert(m Money, to string, on time.Time) (Money, error) {
+	rate, ok := r.RateOn(m.Currency, to, on)
+	if !ok {
+		return Money{}, apperror.New(apperror.CodeInvalidInput,
+			fmt.Sprintf("no %s/%s exchange rate on %s", m.Currency, to, on.Format(DateLayout)))
+	}
+	return Money{
+		Amount:   int(roundRat(convertAmount(int64(m.Amount), rate))),
+		Currency: to,
+	}, nil
+}
+
+// convertAmount returns amount at rate exactly, to be rounded once with
+// roundRat.
+func convertAmount(amount int64, rate *big.Rat) *big.Rat {
+	return new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+}
+
+// DerivePrices fills DerivedPrices of the variant for the regions that
+// have no price of their own, converting the base region's price into
+// the region's currency at the rates of the given date. Regions whose
+// currency has no rate are left out.
+func (v *Variant) DerivePrices(baseRegion string, regions map[string]string, rates ExchangeRates, on time.Time) {
+	base, ok := v.Prices[baseRegion]
+	if !ok {
+		return
+	}
+
+	for region, currency := range regions {
+		if _, ok := v.Prices[region]; ok {
+			continue
+		}
+		price, err := rates.Convert(base, currency, on)
+		if err != nil {
+			continue
+		}
+		if v.DerivedPrices == nil {
+			v.DerivedPrices = make(MoneyMap)
+		}
+		v.DerivedPrices[region] = price
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func testRate(t *testing.T, base, quote, rate, effectiveOn string) *ExchangeRate {
+	t.Helper()
+	d, err := ParseDecimal(rate)
+	if err != nil {
+		t.Fatalf("ParseDecimal(%q): %v", rate, err)
+	}
+	return &ExchangeRate{Base: base, Quote: quote, Rate: d, EffectiveOn: testDate(t, effectiveOn)}
+}
+
+func testDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	date, err := time.Parse(DateLayout, s)
+	if err != nil {
+		t.Fatalf("time.Parse(%q): %v", s, err)
+	}
+	return date
+}
+
+func TestRateOn(t *testing.T) {
+	rates := ExchangeRates{
+		testRate(t, "EUR", "UAH", "44", "2026-01-01"),
+		testRate(t, "EUR", "UAH", "45.5", "2026-02-01"),
+		testRate(t, "USD", "UAH", "40", "2026-01-10"),
+		testRate(t, "UAH", "USD", "0.025", "2026-01-20"),
+		testRate(t, "PLN", "EUR", "0.25", "2026-01-01"),
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		on       string
+		want     string
+		wantOK   bool
+	}{
+		{"same currency", "UAH", "UAH", "2020-01-01", "1", true},
+		{"direct", "EUR", "UAH", "2026-01-15", "44", true},
+		{"latest effective by the date", "EUR", "UAH", "2026-03-01", "91/2", true},
+		{"effective on the day itself", "EUR", "UAH", "2026-02-01", "91/2", true},
+		{"inverse of the reverse pair", "UAH", "EUR", "2026-01-15", "1/44", true},
+		{"direct preferred over inverse", "USD", "UAH", "2026-02-01", "40", true},
+		{"inverse before the direct rate", "UAH", "USD", "2026-01-15", "1/40", true},
+		{"direct after its own rate", "UAH", "USD", "2026-01-25", "1/40", true},
+		{"before any rate", "EUR", "UAH", "2025-12-31", "", false},
+		{"unknown pair", "PLN", "UAH", "2026-03-01", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rates.RateOn(tt.from, tt.to, testDate(t, tt.on))
+			if ok != tt.wantOK {
+				t.Fatalf("RateOn(%s, %s, %s) ok = %v, want %v", tt.from, tt.to, tt.on, ok, tt.wantOK)
+			}
+			if ok && got.RatString() != tt.want {
+				t.Errorf("RateOn(%s, %s, %s) = %s, want %s", tt.from, tt.to, tt.on, got.RatString(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRateOnDoesNotShareRates(t *testing.T) {
+	rates := ExchangeRates{testRate(t, "EUR", "UAH", "44", "2026-01-01")}
+	on := testDate(t, "2026-01-02")
+
+	rate, _ := rates.RateOn("EUR", "UAH", on)
+	rate.SetInt64(1)
+	if again, _ := rates.RateOn("EUR", "UAH", on); again.RatString() != "44" {
+		t.Errorf("rate changed to %s through a returned value", again.RatString())
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TemplateEventOrderCreated         = "order_created"
+	TemplateEventOrderReceived        = "order_received"
+	TemplateEventOrderShipped         = "order_shipped"
+	TemplateEventOrderHoldExpired     = "order_hold_expired"
+	TemplateEventOrderPaidAfterCancel = "order_paid_after_cancel"
+	TemplateEventLowStock             = "low_stock"
+	TemplateEventLotExpiring          = "lot_expiring"
+)
+
+const (
+	LocaleUkrainian = "uk"
+	LocaleEnglish   = "en"
+
+	DefaultLocale = LocaleUkrainian
+)
+
+// TemplateEvents lists the notification events and the channels each
+// event is delivered through.
+var TemplateEvents = map[string][]string{
+	TemplateEventOrderCreated:         {"telegram"},
+	TemplateEventOrderReceived:        {"sms"},
+	TemplateEventOrderShipped:         {"sms"},
+	TemplateEventOrderHoldExpired:     {"telegram"},
+	TemplateEventOrderPaidAfterCancel: {"telegram"},
+	TemplateEventLowStock:             {"telegram"},
+	TemplateEventLotExpiring:          {"telegram"},
+}
+
+var SupportedLocales = []string{LocaleUkrainian, LocaleEnglish}
+
+// NotificationTemplate is an operator-edited override of a built-in
+// notification template.
+type NotificationTemplate struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Event     string    `gorm:"type:varchar(64);not null"`
+	Channel   string    `gorm:"type:varchar(20);not null"`
+	Locale    string    `gorm:"type:varchar(8);not null"`
+	Body      string    `gorm:"type:text;not null"`
+	IsDefault bool      `gorm:"-"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+	UpdatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
+
+func NewNotificationTemplate(input dto.NotificationTemplateUpsertDTO) (*NotificationTemplate, error) {
+	channels, ok := TemplateEvents[input.Event]
+	if !ok {
+		return nil, apperror.New(apperror.CodeInvalidInput, "unknown template event "+input.Event)
+	}
+
+	channelOK := false
+	for _, c := range channels {
+		if c == input.Channel {
+			channelOK = true
+			break
+		}
+	}
+	if !channelOK {
+		return nil, apperror.New(apperror.CodeInvalidInput, "event "+input.Event+" is not sent through channel "+input.Channel)
+	}
+
+	if !IsSupportedLocale(input.Locale) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "unsupported locale "+input.Locale)
+	}
+	if input.Body == "" {
+		return nil, apperror.New(apperror.CodeInvalidInput, "template body is required")
+	}
+
+	now := time.Now()
+	return &NotificationTemplate{
+		ID:        uuid.New().String(),
+		Event:     input.Event,
+		Channel:   input.Channel,
+		Locale:    input.Locale,
+		Body:      input.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func IsSupportedLocale(locale string) bool {
+	for _, l := range SupportedLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
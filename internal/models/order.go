@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"caviar/internal/dto"
@@ -21,6 +22,58 @@ const (
 	OrderStatusCancelled  OrderStatus = "cancelled"
 )
 
+// OpenOrderStatuses are the statuses of orders that are not finished yet.
+var OpenOrderStatuses = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusProcessing,
+	OrderStatusShipped,
+}
+
+type PaymentMethod string
+
+const (
+	// PaymentMethodCOD is cash on delivery, collected by the carrier.
+	PaymentMethodCOD PaymentMethod = "cod"
+	// PaymentMethodCard is paid online through the payment provider.
+	PaymentMethodCard PaymentMethod = "card"
+	// PaymentMethodBankTransfer is paid by invoice; the order is
+	// confirmed once staff mark it paid.
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+)
+
+// HomeCountry is the only country couriers collect cash on delivery in.
+const HomeCountry = "UA"
+
+// CODFee is the cash on delivery surcharge: a fixed amount plus a
+// percentage of the order total, rounded up.
+type CODFee struct {
+	Fixed   int `gorm:"not null;default:0"`
+	Percent int `gorm:"not null;default:0"`
+}
+
+// For returns the fee for an order total.
+func (f CODFee) For(total int) int {
+	return f.Fixed + (total*f.Percent+99)/100
+}
+
+// CODFees are the cash on delivery terms of the shop: a fixed amount per
+// currency plus a percentage of the order total.
+type CODFees struct {
+	Fixed   map[string]int
+	Percent int
+}
+
+// For returns the fee for orders in currency. Cash on delivery is only
+// offered in currencies with a fixed amount, even a zero one.
+func (f CODFees) For(currency string) (CODFee, bool) {
+	fixed, ok := f.Fixed[strings.ToUpper(currency)]
+	if !ok {
+		return CODFee{}, false
+	}
+	return CODFee{Fixed: fixed, Percent: f.Percent}, true
+}
+
 type DeliveryType string
 
 const (
@@ -38,6 +91,41 @@ type Order struct {
 	TotalAmount  Money       `gorm:"type:jsonb;not null"`
 	Status       OrderStatus `gorm:"type:varchar(20);not null;default:'pending'"`
 	Notes        string      `gorm:"type:text"`
+	TrackingNumber string    `gorm:"type:varchar(64)"`
+	// WarehouseID is the warehouse the order is fulfilled from.
+	WarehouseID  *string     `gorm:"type:uuid"`
+	// HoldExpiresAt is when the stock reserved for a pending order is
+	// released and the order cancelled; nil means it is held until the
+	// order is confirmed or cancelled.
+	HoldExpiresAt *time.Time
+	// PromoCode is the code applied to the order and Discounts the lines
+	// it took off TotalAmount.
+	PromoCode    string         `gorm:"type:varchar(32)"`
+	Discounts    OrderDiscounts `gorm:"type:jsonb;not null;default:'[]'"`
+	// ShippingCost is the delivery charge included in TotalAmount, for a
+	// parcel of ShippingWeight grams. ShippingPrice and ShippingFreeFrom
+	// are the quoted rate's, to re-check the free shipping threshold when
+	// items are cancelled.
+	ShippingCost     int        `gorm:"not null;default:0"`
+	ShippingWeight   int        `gorm:"not null;default:0"`
+	ShippingPrice    int        `gorm:"not null;default:0"`
+	ShippingFreeFrom int        `gorm:"not null;default:0"`
+	PaymentMethod PaymentMethod `gorm:"type:varchar(20);not null"`
+	// PaymentFee is the surcharge of the payment method, included in
+	// TotalAmount, and CODFee the terms it was charged on.
+	PaymentFee   int         `gorm:"not null;default:0"`
+	CODFee       CODFee      `gorm:"embedded;embeddedPrefix:cod_fee_"`
+	// TaxRate is the VAT rate of the delivery country in basis points
+	// when the order was placed, and TaxInclusive whether prices
+	// contained it. TotalAmount is NetAmount plus TaxAmount.
+	TaxRate      int         `gorm:"not null;default:0"`
+	TaxInclusive bool        `gorm:"not null;default:true"`
+	NetAmount    int         `gorm:"not null;default:0"`
+	TaxAmount    int         `gorm:"not null;default:0"`
+	// PaidAt is when the order was paid: online, or marked paid by staff.
+	PaidAt       *time.Time
+	Payments     []Payment   `gorm:"foreignKey:OrderID"`
+	Refunds      []Refund    `gorm:"foreignKey:OrderID"`
 	CreatedAt    time.Time   `gorm:"not null;default:now()"`
 	UpdatedAt    time.Time   `gorm:"not null;default:now()"`
 }
@@ -54,7 +142,16 @@ type OrderItem struct {
 	Quantity  int       `gorm:"not null"`
 	UnitPrice Money     `gorm:"type:jsonb;not null"`
 	TotalPrice Money    `gorm:"type:jsonb;not null"`
+	// NetAmount and TaxAmount split GrossAmount, what is paid for the
+	// item after discounts, at the order's tax rate. They are not rounded
+	// to the currency unit; the order's amounts are.
+	NetAmount   Decimal `gorm:"type:numeric(20,10);not null;default:0"`
+	TaxAmount   Decimal `gorm:"type:numeric(20,10);not null;default:0"`
+	GrossAmount Decimal `gorm:"type:numeric(20,10);not null;default:0"`
+	// Lots are the lots the item was allocated from when stock was reserved.
+	Lots      OrderItemLots `gorm:"type:jsonb;not null;default:'[]'"`
 	Product   *Product  `gorm:"foreignKey:ProductID"`
+	Variant   *Variant  `gorm:"foreignKey:VariantID"`
 	CreatedAt time.Time `gorm:"not null;default:now()"`
 }
 
@@ -68,6 +165,7 @@ type CustomerInfo struct {
 	FullName  string `json:"full_name,omitempty"`
 	Phone     string `json:"phone"`
 	Email     string `json:"email,omitempty"`
+	Language  string `json:"language,omitempty"`
 }
 
 type DeliveryInfo struct {
@@ -79,6 +177,11 @@ type DeliveryInfo struct {
 	Instructions string       `json:"instructions,omitempty"`
 }
 
+// HoldExpired reports whether the order is still pending past its hold.
+func (o *Order) HoldExpired(now time.Time) bool {
+	return o.Status == OrderStatusPending && o.HoldExpiresAt != nil && !o.HoldExpiresAt.After(now)
+}
+
 func NewOrder(input dto.OrderCreateDTO) (*Order, error) {
 	now := time.Now()
 
@@ -96,6 +199,11 @@ func NewOrder(input dto.OrderCreateDTO) (*Order, error) {
 		return nil, err
 	}
 
+	paymentMethod, err := validatePaymentMethod(input.PaymentMethod, deliveryInfo)
+	if err != nil {
+		return nil, err
+	}
+
 	var orderItems []OrderItem
 	var totalAmount int
 	currency := ""
@@ -156,6 +264,7 @@ func NewOrder(input dto.OrderCreateDTO) (*Order, error) {
 			Currency: currency,
 		},
 		Status:       OrderStatusPending,
+		PaymentMethod: paymentMethod,
 		Notes:        input.Notes,
 		CreatedAt:    now,
 		UpdatedAt:    now,
@@ -164,16 +273,67 @@ func NewOrder(input dto.OrderCreateDTO) (*Order, error) {
 	return order, nil
 }
 
+// validatePaymentMethod checks the payment method against the delivery;
+// orders that do not name one are paid on delivery.
+func validatePaymentMethod(method string, delivery *DeliveryInfo) (PaymentMethod, error) {
+	if method == "" {
+		method = string(PaymentMethodCOD)
+	}
+	switch PaymentMethod(method) {
+	case PaymentMethodCard, PaymentMethodBankTransfer:
+	case PaymentMethodCOD:
+		if delivery.Type == DeliveryTypeCourier && !strings.EqualFold(delivery.Country, HomeCountry) {
+			return "", apperror.New(apperror.CodeInvalidInput, "cash on delivery is not available for courier delivery abroad")
+		}
+	default:
+		return "", apperror.New(apperror.CodeInvalidInput, "unsupported payment method "+method)
+	}
+	return PaymentMethod(method), nil
+}
+
+// ApplyCODFee adds the cash on delivery fee to a COD order's total. It
+// refuses COD orders in a currency without a fee.
+func (o *Order) ApplyCODFee(fees CODFees) error {
+	if o.PaymentMethod != PaymentMethodCOD {
+		return nil
+	}
+	fee, ok := fees.For(o.TotalAmount.Currency)
+	if !ok {
+		return apperror.New(apperror.CodeInvalidInput, "cash on delivery is not available for payments in "+o.TotalAmount.Currency)
+	}
+	o.CODFee = fee
+	o.PaymentFee = fee.For(o.TotalAmount.Amount)
+	o.TotalAmount.Amount += o.PaymentFee
+	return nil
+}
+
+// CheckStatusChange rejects moving a bank transfer order past pending
+// before it was marked paid.
+func (o *Order) CheckStatusChange(status OrderStatus) error {
+	if o.PaymentMethod != PaymentMethodBankTransfer || o.PaidAt != nil {
+		return nil
+	}
+	if status == OrderStatusPending || status == OrderStatusCancelled {
+		return nil
+	}
+	return apperror.New(apperror.CodeInvalidInput, "bank transfer orders stay pending until they are marked paid")
+}
+
 func validateCustomerInfo(info dto.CustomerInfoDTO, country string) (*CustomerInfo, error) {
 	customerInfo := &CustomerInfo{
-		Phone: info.Phone,
-		Email: info.Email,
+		Phone:    info.Phone,
+		Email:    info.Email,
+		Language: info.Language,
 	}
 
 	if info.Phone == "" {
 		return nil, apperror.New(apperror.CodeInvalidInput, "phone number is required")
 	}
 
+	if info.Language != "" && !IsSupportedLocale(info.Language) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "unsupported language "+info.Language)
+	}
+
 	// Accept either full name or first/last name format globally
 	if info.FullName != "" {
 		customerInfo.FullName = info.FullName
@@ -188,18 +348,22 @@ func validateCustomerInfo(info dto.CustomerInfoDTO, country string) (*CustomerIn
 }
 
 func validateDeliveryInfo(info dto.DeliveryInfoDTO) (*DeliveryInfo, error) {
+	country := strings.ToUpper(strings.TrimSpace(info.Country))
 	deliveryInfo := &DeliveryInfo{
 		Type:         DeliveryType(info.Type),
-		Country:      info.Country,
+		Country:      country,
 		City:         info.City,
 		Address:      info.Address,
 		PostOffice:   info.PostOffice,
 		Instructions: info.Instructions,
 	}
 
-	if info.Country == "" {
+	if country == "" {
 		return nil, apperror.New(apperror.CodeInvalidInput, "country is required")
 	}
+	if !countryCodePattern.MatchString(country) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "invalid country code "+country+", use ISO 3166-1 alpha-2")
+	}
 	if info.City == "" {
 		return nil, apperror.New(apperror.CodeInvalidInput, "city is required")
 	}
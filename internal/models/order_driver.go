@@ -98,4 +98,60 @@ func (m *Money) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, m)
 }
 
+// Value implements driver.Valuer for OrderItemLots
+func (l OrderItemLots) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner for OrderItemLots
+func (l *OrderItemLots) Scan(value interface{}) error {
+	if value == nil {
+		*l = OrderItemLots{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into OrderItemLots", value)
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer for RefundItems
+func (r RefundItems) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner for RefundItems
+func (r *RefundItems) Scan(value interface{}) error {
+	if value == nil {
+		*r = RefundItems{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RefundItems", value)
+	}
+
+	return json.Unmarshal(bytes, r)
+}
+
 // MoneyMap and CaviarDetails driver methods are already implemented in product_driver.go
\ No newline at end of file
@@ -0,0 +1,72 @@
+package models
+
+import "testing"
+
+func TestValidatePaymentMethod(t *testing.T) {
+	post := &DeliveryInfo{Type: DeliveryTypePostOffice, Country: "PL"}
+	courierHome := &DeliveryInfo{Type: DeliveryTypeCourier, Country: HomeCountry}
+	courierAbroad := &DeliveryInfo{Type: DeliveryTypeCourier, Country: "PL"}
+
+	tests := []struct {
+		name     string
+		method   string
+		delivery *DeliveryInfo
+		want     PaymentMethod
+		wantErr  bool
+	}{
+		{"defaults to cod", "", post, PaymentMethodCOD, false},
+		{"cod", "cod", courierHome, PaymentMethodCOD, false},
+		{"card", "card", courierAbroad, PaymentMethodCard, false},
+		{"bank transfer", "bank_transfer", post, PaymentMethodBankTransfer, false},
+		{"cod by courier abroad", "cod", courierAbroad, "", true},
+		{"default by courier abroad", "", courierAbroad, "", true},
+		{"unknown", "cash", post, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validatePaymentMethod(tt.method, tt.delivery)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validatePaymentMethod(%q) error = %v, wantErr %v", tt.method, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("validatePaymentMethod(%q) = %q, want %q", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCODFee(t *testing.T) {
+	fees := CODFees{Fixed: map[string]int{"UAH": 20, "EUR": 0}, Percent: 2}
+
+	tests := []struct {
+		name     string
+		method   PaymentMethod
+		currency string
+		total    int
+		wantFee  int
+		wantErr  bool
+	}{
+		{"fixed plus percent rounded up", PaymentMethodCOD, "UAH", 1010, 41, false},
+		{"zero fixed fee", PaymentMethodCOD, "EUR", 100, 2, false},
+		{"currency without a fee", PaymentMethodCOD, "USD", 100, 0, true},
+		{"not cod", PaymentMethodCard, "USD", 100, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Order{
+				PaymentMethod: tt.method,
+				TotalAmount:   Money{Amount: tt.total, Currency: tt.currency},
+			}
+			err := o.ApplyCODFee(fees)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyCODFee() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if o.PaymentFee != tt.wantFee {
+				t.Errorf("PaymentFee = %d, want %d", o.PaymentFee, tt.wantFee)
+			}
+			if o.TotalAmount.Amount != tt.total+tt.wantFee {
+				t.Errorf("total = %d, want %d", o.TotalAmount.Amount, tt.total+tt.wantFee)
+			}
+		})
+	}
+}
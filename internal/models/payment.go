@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending   PaymentStatus = "pending"
+	PaymentStatusSucceeded PaymentStatus = "succeeded"
+	PaymentStatusFailed    PaymentStatus = "failed"
+)
+
+// Payment is an attempt to collect an order's total through a payment
+// provider. An order may have several attempts; it is paid once one of
+// them succeeds.
+type Payment struct {
+	ID      string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrderID string `gorm:"type:uuid;not null;index"`
+	// Provider is the name of the gateway, ExternalID its payment ID.
+	Provider    string        `gorm:"type:varchar(32);not null"`
+	ExternalID  string        `gorm:"type:varchar(128)"`
+	Amount      int           `gorm:"not null"`
+	Currency    string        `gorm:"type:varchar(3);not null"`
+	Status      PaymentStatus `gorm:"type:varchar(20);not null;default:'pending'"`
+	CheckoutURL string        `gorm:"type:text"`
+	// FailureReason is the provider's explanation of a failed payment.
+	FailureReason string `gorm:"type:text"`
+	// RefundedAmount is how much of Amount was refunded.
+	RefundedAmount int `gorm:"not null;default:0"`
+	PaidAt         *time.Time
+	CreatedAt      time.Time `gorm:"not null;default:now()"`
+	UpdatedAt      time.Time `gorm:"not null;default:now()"`
+}
+
+func (Payment) TableName() string {
+	return "payments"
+}
+
+// NewPayment starts a payment of the order's total. Only pending orders
+// paid by card are paid online.
+func NewPayment(order *Order, provider string) (*Payment, error) {
+	if order.PaymentMethod != PaymentMethodCard {
+		return nil, apperror.New(apperror.CodeInvalidInput, "only orders paid by card can be paid online")
+	}
+	if order.Status != OrderStatusPending {
+		return nil, apperror.New(apperror.CodeInvalidInput, "only pending orders can be paid")
+	}
+	if order.PaidAt != nil {
+		return nil, apperror.New(apperror.CodeConflict, "order is already paid")
+	}
+	if order.HoldExpired(time.Now()) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "order hold has expired")
+	}
+
+	now := time.Now().UTC()
+	return &Payment{
+		ID:        uuid.New().String(),
+		OrderID:   order.ID,
+		Provider:  provider,
+		Amount:    order.TotalAmount.Amount,
+		Currency:  order.TotalAmount.Currency,
+		Status:    PaymentStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ApplyStatus records a status reported by the provider and reports
+// whether the payment changed. A succeeded payment stays succeeded, so
+// repeated and out-of-order callbacks are harmless. The reported amount
+// and currency must match what was asked for.
+func (p *Payment) ApplyStatus(status PaymentStatus, externalID string, amount int, currency, reason string, now time.Time) (bool, error) {
+	if p.Status == PaymentStatusSucceeded || p.Status == status {
+		return false, nil
+	}
+	if status == PaymentStatusSucceeded && (amount != p.Amount || currency != p.Currency) {
+		return false, apperror.New(apperror.CodeInvalidInput, "paid amount does not match the payment")
+	}
+
+	p.Status = status
+	if externalID != "" {
+		p.ExternalID = externalID
+	}
+	switch status {
+	case PaymentStatusSucceeded:
+		p.PaidAt = &now
+		p.FailureReason = ""
+	case PaymentStatusFailed:
+		p.FailureReason = reason
+	}
+	p.UpdatedAt = now
+	return true, nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
@@ -8,19 +9,26 @@ import (
 	"caviar/pkg/apperror"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Product struct {
     ID          string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-    Slug        string        `gorm:"uniqueIndex;not null"`
+    Slug        string        `gorm:"index:idx_products_slug,unique,where:deleted_at IS NULL;not null"`
     Name        string        `gorm:"not null"`
     Subtitle    string        `gorm:"not null"`
     Description string        `gorm:"type:text"`
     Variants    []Variant     `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
     Details     CaviarDetails `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
-    IsActive    bool          `gorm:"default:true"`
+    Images      ProductImages `gorm:"type:jsonb;default:'[]'::jsonb"`
+    IsActive    bool          `gorm:"not null;default:false"`
+    Status      ProductStatus `gorm:"type:varchar(20);not null;default:'draft'"`
+    PublishAt   *time.Time
+    PublishedAt *time.Time
+    ArchivedAt  *time.Time
     CreatedAt   time.Time     `gorm:"not null;default:now()"`
     UpdatedAt   time.Time     `gorm:"not null;default:now()"`
+    DeletedAt   gorm.DeletedAt `gorm:"index"`
 }
 
 func (Product) TableName() string {
@@ -31,10 +39,26 @@ type Variant struct {
     ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
     ProductID string    `gorm:"type:uuid;not null"`
     Mass      int       `gorm:"not null"`
+    SKU       string    `gorm:"column:sku;type:varchar(64);not null;default:''"`
+    Barcode   string    `gorm:"type:varchar(13);not null;default:''"`
     Stock     int       `gorm:"not null;default:0"`
+    // LowStockThreshold alerts staff once stock drops to it; 0 disables
+    // the alert. LowStockAlertedAt is set while an alert is outstanding
+    // and cleared when stock recovers above the threshold.
+    LowStockThreshold int        `gorm:"not null;default:0"`
+    LowStockAlertedAt *time.Time
+    // Levels is the stock per warehouse; Stock is their sum.
+    Levels    []StockLevel `gorm:"foreignKey:VariantID"`
     Prices    MoneyMap  `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+    // DerivedPrices are converted from the base region's price for the
+    // regions without a price of their own. They are not stored.
+    DerivedPrices MoneyMap `gorm:"-"`
+    // Version is bumped on every change, including stock movements from
+    // orders, so edits based on a stale read are rejected.
+    Version   int       `gorm:"not null;default:1"`
     CreatedAt time.Time `gorm:"not null;default:now()"`
     UpdatedAt time.Time `gorm:"not null;default:now()"`
+    DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func (Variant) TableName() string {
@@ -83,37 +107,26 @@ func NewProduct(input dto.ProductCreateDTO) (*Product, error) {
         return nil, apperror.New(apperror.CodeInvalidInput, "at least one variant is required")
     }
     var variants []Variant
+    codes := make(map[string]bool)
     for i, v := range input.Variants {
-        if v.Mass <= 0 {
-            return nil, apperror.New(apperror.CodeInvalidInput, "variant mass must be > 0 (index " + strconv.Itoa(i) + ")")
-        }
-        if v.Stock < 0 {
-            return nil, apperror.New(apperror.CodeInvalidInput, "variant stock cannot be negative (index " + strconv.Itoa(i) + ")")
-        }
-        if len(v.Prices) == 0 {
-            return nil, apperror.New(apperror.CodeInvalidInput, "variant must have at least one price (index " + strconv.Itoa(i) + ")")
-        }
-        m := make(MoneyMap, len(v.Prices))
-        for region, p := range v.Prices {
-            if p.Amount <= 0 {
-                return nil, apperror.New(apperror.CodeInvalidInput, "price amount must be > 0 for region " + region)
+        variant, err := NewVariant(v, now)
+        if err != nil {
+            var appErr *apperror.AppError
+            if errors.As(err, &appErr) {
+                appErr.Message += " (index " + strconv.Itoa(i) + ")"
             }
-            if p.Currency == "" {
-                return nil, apperror.New(apperror.CodeInvalidInput, "currency is required for region " + region)
+            return nil, err
+        }
+        for label, code := range map[string]string{"SKU": variant.SKU, "barcode": variant.Barcode} {
+            if code == "" {
+                continue
             }
-            m[region] = Money{
-                Amount:   p.Amount,
-                Currency: p.Currency,
+            if codes[label+code] {
+                return nil, apperror.New(apperror.CodeInvalidInput, label + " " + code + " is used by more than one variant (index " + strconv.Itoa(i) + ")")
             }
+            codes[label+code] = true
         }
-        variants = append(variants, Variant{
-            ID:        uuid.New().String(),
-            Mass:      v.Mass,
-            Stock:     v.Stock,
-            Prices:    m,
-            CreatedAt: now,
-            UpdatedAt: now,
-        })
+        variants = append(variants, *variant)
     }
     d := input.Details
     if d.FishAge == "" {
@@ -157,6 +170,7 @@ func NewProduct(input dto.ProductCreateDTO) (*Product, error) {
             },
         },
         IsActive:  false,
+        Status:    ProductStatusDraft,
         CreatedAt: now,
         UpdatedAt: now,
     }
@@ -52,4 +52,30 @@ func (cd *CaviarDetails) Scan(value any) error {
 
 func (cd CaviarDetails) Value() (driver.Value, error) {
     return json.Marshal(cd)
-}
\ No newline at end of file
+}
+
+func (pi *ProductImages) Scan(value any) error {
+    if value == nil {
+        *pi = ProductImages{}
+        return nil
+    }
+
+    var bytes []byte
+    switch v := value.(type) {
+    case []byte:
+        bytes = v
+    case string:
+        bytes = []byte(v)
+    default:
+        return fmt.Errorf("cannot scan %T into ProductImages", value)
+    }
+
+    return json.Unmarshal(bytes, pi)
+}
+
+func (pi ProductImages) Value() (driver.Value, error) {
+    if pi == nil {
+        return "[]", nil
+    }
+    return json.Marshal(pi)
+}
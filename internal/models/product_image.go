@@ -0,0 +1,197 @@
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+const MaxProductImages = 20
+
+// ImageContentTypes maps accepted image content types to the file
+// extension used for the object key.
+var ImageContentTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// ProductImage is an image stored in object storage. The list is kept
+// in the products.images JSONB column.
+type ProductImage struct {
+	ID          string           `json:"id"`
+	ObjectKey   string           `json:"object_key"`
+	ContentType string           `json:"content_type"`
+	Size        int64            `json:"size"`
+	Width       int              `json:"width"`
+	Height      int              `json:"height"`
+	Position    int              `json:"position"`
+	IsPrimary   bool             `json:"is_primary"`
+	Renditions  []ImageRendition `json:"renditions"`
+
+	// URL is resolved by the service on read and never persisted.
+	URL string `json:"-"`
+}
+
+// ImageRendition is a resized copy of a product image, stored next to
+// the original.
+type ImageRendition struct {
+	Name        string `json:"name"`
+	Format      string `json:"format"`
+	ObjectKey   string `json:"object_key"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+
+	URL string `json:"-"`
+}
+
+type ProductImages []ProductImage
+
+func NewProductImage(productID, contentType string, size int64) (*ProductImage, error) {
+	ext, ok := ImageContentTypes[contentType]
+	if !ok {
+		return nil, apperror.New(apperror.CodeInvalidInput, "unsupported image type "+contentType+", use JPEG, PNG or WebP")
+	}
+	if size <= 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "image is empty")
+	}
+
+	id := uuid.New().String()
+
+	return &ProductImage{
+		ID:          id,
+		ObjectKey:   "products/" + productID + "/" + id + "." + ext,
+		ContentType: contentType,
+		Size:        size,
+	}, nil
+}
+
+// RenditionKey returns the object key for a rendition of the image, e.g.
+// "products/{id}/{image}_thumb.webp".
+func (image *ProductImage) RenditionKey(name, ext string) string {
+	base := image.ObjectKey
+	if i := strings.LastIndexByte(base, '.'); i > strings.LastIndexByte(base, '/') {
+		base = base[:i]
+	}
+	return base + "_" + name + "." + ext
+}
+
+// ObjectKeys returns the keys of the original and all renditions.
+func (image *ProductImage) ObjectKeys() []string {
+	keys := []string{image.ObjectKey}
+	for _, r := range image.Renditions {
+		keys = append(keys, r.ObjectKey)
+	}
+	return keys
+}
+
+// Sorted returns the images ordered by position.
+func (images ProductImages) Sorted() ProductImages {
+	sorted := make(ProductImages, len(images))
+	copy(sorted, images)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position < sorted[j].Position
+	})
+	return sorted
+}
+
+// Primary returns the primary image, or nil when there are no images.
+func (images ProductImages) Primary() *ProductImage {
+	for i := range images {
+		if images[i].IsPrimary {
+			return &images[i]
+		}
+	}
+	return nil
+}
+
+func (images ProductImages) indexOf(id string) int {
+	for i := range images {
+		if images[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add appends an image at the end. The first image becomes primary.
+func (images ProductImages) Add(image ProductImage) (ProductImages, error) {
+	if len(images) >= MaxProductImages {
+		return nil, apperror.New(apperror.CodeInvalidInput, "product already has the maximum number of images")
+	}
+
+	result := images.Sorted()
+	image.Position = len(result)
+	image.IsPrimary = len(result) == 0
+	return append(result, image), nil
+}
+
+// Reorder arranges the images in the order of ids, which must list
+// every image exactly once.
+func (images ProductImages) Reorder(ids []string) (ProductImages, error) {
+	if len(ids) != len(images) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "image order must list every image of the product exactly once")
+	}
+
+	result := make(ProductImages, 0, len(images))
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		idx := images.indexOf(id)
+		if idx < 0 {
+			return nil, apperror.New(apperror.CodeNotFound, "image "+id+" not found")
+		}
+		if seen[id] {
+			return nil, apperror.New(apperror.CodeInvalidInput, "image "+id+" is listed more than once")
+		}
+		seen[id] = true
+
+		image := images[idx]
+		image.Position = i
+		result = append(result, image)
+	}
+
+	return result, nil
+}
+
+func (images ProductImages) SetPrimary(id string) (ProductImages, error) {
+	if images.indexOf(id) < 0 {
+		return nil, apperror.New(apperror.CodeNotFound, "image "+id+" not found")
+	}
+
+	result := make(ProductImages, len(images))
+	for i, image := range images {
+		image.IsPrimary = image.ID == id
+		result[i] = image
+	}
+	return result, nil
+}
+
+// Remove deletes an image and closes the gap in positions. When the
+// primary image is removed, the first remaining one takes its place.
+func (images ProductImages) Remove(id string) (ProductImages, *ProductImage, error) {
+	idx := images.indexOf(id)
+	if idx < 0 {
+		return nil, nil, apperror.New(apperror.CodeNotFound, "image "+id+" not found")
+	}
+	removed := images[idx]
+
+	result := make(ProductImages, 0, len(images)-1)
+	for _, image := range images.Sorted() {
+		if image.ID == id {
+			continue
+		}
+		image.Position = len(result)
+		result = append(result, image)
+	}
+
+	if removed.IsPrimary && len(result) > 0 {
+		result[0].IsPrimary = true
+	}
+
+	return result, &removed, nil
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"caviar/pkg/apperror"
+)
+
+type ProductStatus string
+
+const (
+	ProductStatusDraft     ProductStatus = "draft"
+	ProductStatusScheduled ProductStatus = "scheduled"
+	ProductStatusPublished ProductStatus = "published"
+	ProductStatusArchived  ProductStatus = "archived"
+)
+
+// ValidateForPublish lists everything that keeps the product off the
+// storefront, so the operator can fix it all in one go.
+func (p *Product) ValidateForPublish() error {
+	var problems []string
+
+	sellable := false
+	for _, v := range p.Variants {
+		if v.Stock <= 0 {
+			continue
+		}
+		for _, price := range v.Prices {
+			if price.Amount > 0 {
+				sellable = true
+				break
+			}
+		}
+	}
+	if !sellable {
+		problems = append(problems, "at least one variant needs a price and stock")
+	}
+	if len(p.Images) == 0 {
+		problems = append(problems, "at least one image is required")
+	}
+
+	if len(problems) > 0 {
+		return apperror.New(apperror.CodeInvalidInput, "product cannot be published: "+strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Publish makes the product visible on the storefront.
+func (p *Product) Publish(now time.Time) error {
+	if p.Status == ProductStatusPublished {
+		return apperror.New(apperror.CodeInvalidInput, "product is already published")
+	}
+	if err := p.ValidateForPublish(); err != nil {
+		return err
+	}
+
+	p.Status = ProductStatusPublished
+	p.IsActive = true
+	p.PublishAt = nil
+	p.PublishedAt = &now
+	p.ArchivedAt = nil
+	return nil
+}
+
+// Schedule validates the product now and publishes it at the given time.
+// The check is repeated when the time comes.
+func (p *Product) Schedule(at, now time.Time) error {
+	if !at.After(now) {
+		return apperror.New(apperror.CodeInvalidInput, "publish time must be in the future")
+	}
+	if p.Status == ProductStatusPublished {
+		return apperror.New(apperror.CodeInvalidInput, "product is already published")
+	}
+	if err := p.ValidateForPublish(); err != nil {
+		return err
+	}
+
+	p.Status = ProductStatusScheduled
+	p.IsActive = false
+	p.PublishAt = &at
+	p.ArchivedAt = nil
+	return nil
+}
+
+// Unpublish returns a published or scheduled product to draft.
+func (p *Product) Unpublish() error {
+	if p.Status != ProductStatusPublished && p.Status != ProductStatusScheduled {
+		return apperror.New(apperror.CodeInvalidInput, "only published or scheduled products can be unpublished")
+	}
+
+	p.Status = ProductStatusDraft
+	p.IsActive = false
+	p.PublishAt = nil
+	return nil
+}
+
+// Archive hides the product for good. The row is kept, so past orders
+// still show what was bought.
+func (p *Product) Archive(now time.Time) error {
+	if p.Status == ProductStatusArchived {
+		return apperror.New(apperror.CodeInvalidInput, "product is already archived")
+	}
+
+	p.Status = ProductStatusArchived
+	p.IsActive = false
+	p.PublishAt = nil
+	p.ArchivedAt = &now
+	return nil
+}
@@ -0,0 +1,369 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{2,31}$`)
+
+type PromoType string
+
+const (
+	PromoTypePercentage   PromoType = "percentage"
+	PromoTypeFixed        PromoType = "fixed"
+	PromoTypeFreeShipping PromoType = "free_shipping"
+)
+
+// PromoCode is a discount customers apply to an order by its code.
+//
+// Amount discounts are spread over the eligible items: all of them, or
+// those of the listed products and variants. Usage limits count orders
+// that were not cancelled, see PromoRedemption.
+type PromoCode struct {
+	ID   string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Code string    `gorm:"type:varchar(32);not null;uniqueIndex"`
+	Type PromoType `gorm:"type:varchar(20);not null"`
+	// Value is the percentage off for percentage codes and the amount
+	// off for fixed ones.
+	Value int `gorm:"not null;default:0"`
+	// Currency is the currency of a fixed Value and of MinOrderAmount.
+	Currency       string `gorm:"type:varchar(3)"`
+	MinOrderAmount int    `gorm:"not null;default:0"`
+	StartsAt       *time.Time
+	EndsAt         *time.Time
+	// UsageLimit caps the orders using the code and PerCustomerLimit the
+	// orders per customer phone; 0 means unlimited.
+	UsageLimit       int       `gorm:"not null;default:0"`
+	PerCustomerLimit int       `gorm:"not null;default:0"`
+	ProductIDs       IDList    `gorm:"type:jsonb;not null;default:'[]'"`
+	VariantIDs       IDList    `gorm:"type:jsonb;not null;default:'[]'"`
+	IsActive         bool      `gorm:"not null;default:true"`
+	CreatedAt        time.Time `gorm:"not null;default:now()"`
+	UpdatedAt        time.Time `gorm:"not null;default:now()"`
+}
+
+func (PromoCode) TableName() string {
+	return "promo_codes"
+}
+
+// IDList is a list of UUIDs stored as a JSON array.
+type IDList []string
+
+func (l IDList) contains(id string) bool {
+	for _, v := range l {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoRedemption records that an order used a promo code. It is
+// stored in the same transaction as the order.
+type PromoRedemption struct {
+	ID            string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	PromoCodeID   string    `gorm:"type:uuid;not null"`
+	OrderID       string    `gorm:"type:uuid;not null;uniqueIndex"`
+	CustomerPhone string    `gorm:"type:varchar(32);not null"`
+	CreatedAt     time.Time `gorm:"not null;default:now()"`
+}
+
+func (PromoRedemption) TableName() string {
+	return "promo_redemptions"
+}
+
+// OrderDiscount is a discount line of an order. Amount discounts have a
+// line per item they apply to; free shipping has a single line without
+// an item.
+type OrderDiscount struct {
+	Code        string    `json:"code"`
+	Type        PromoType `json:"type"`
+	OrderItemID string    `json:"order_item_id,omitempty"`
+	Amount      int       `json:"amount"`
+}
+
+type OrderDiscounts []OrderDiscount
+
+// Total returns the amount taken off the order.
+func (d OrderDiscounts) Total() int {
+	var total int
+	for _, line := range d {
+		total += line.Amount
+	}
+	return total
+}
+
+// forItem returns the amount taken off an order item.
+func (d OrderDiscounts) forItem(itemID string) int {
+	var total int
+	for _, line := range d {
+		if line.OrderItemID == itemID {
+			total += line.Amount
+		}
+	}
+	return total
+}
+
+func NewPromoCode(input dto.PromoCodeCreateDTO) (*PromoCode, error) {
+	code := NormalizePromoCode(input.Code)
+	if !promoCodePattern.MatchString(code) {
+		return nil, apperror.New(apperror.CodeInvalidInput,
+			"promo code must be 3 to 32 letters, digits, dashes or underscores")
+	}
+
+	now := time.Now().UTC()
+	p := &PromoCode{
+		ID:               uuid.New().String(),
+		Code:             code,
+		Type:             PromoType(input.Type),
+		Value:            input.Value,
+		Currency:         strings.ToUpper(strings.TrimSpace(input.Currency)),
+		MinOrderAmount:   input.MinOrderAmount,
+		StartsAt:         input.StartsAt,
+		EndsAt:           input.EndsAt,
+		UsageLimit:       input.UsageLimit,
+		PerCustomerLimit: input.PerCustomerLimit,
+		ProductIDs:       normalizeIDList(input.ProductIDs),
+		VariantIDs:       normalizeIDList(input.VariantIDs),
+		IsActive:         true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ApplyPatch changes the fields present in input.
+func (p *PromoCode) ApplyPatch(input dto.PromoCodePatchDTO) error {
+	if input.Value != nil {
+		p.Value = *input.Value
+	}
+	if input.Currency != nil {
+		p.Currency = strings.ToUpper(strings.TrimSpace(*input.Currency))
+	}
+	if input.MinOrderAmount != nil {
+		p.MinOrderAmount = *input.MinOrderAmount
+	}
+	if input.StartsAt != nil {
+		p.StartsAt = input.StartsAt
+	}
+	if input.EndsAt != nil {
+		p.EndsAt = input.EndsAt
+	}
+	if input.UsageLimit != nil {
+		p.UsageLimit = *input.UsageLimit
+	}
+	if input.PerCustomerLimit != nil {
+		p.PerCustomerLimit = *input.PerCustomerLimit
+	}
+	if input.ProductIDs != nil {
+		p.ProductIDs = normalizeIDList(input.ProductIDs)
+	}
+	if input.VariantIDs != nil {
+		p.VariantIDs = normalizeIDList(input.VariantIDs)
+	}
+	if input.IsActive != nil {
+		p.IsActive = *input.IsActive
+	}
+	p.UpdatedAt = time.Now().UTC()
+	return p.validate()
+}
+
+func (p *PromoCode) validate() error {
+	switch p.Type {
+	case PromoTypePercentage:
+		if p.Value < 1 || p.Value > 100 {
+			return apperror.New(apperror.CodeInvalidInput, "percentage must be between 1 and 100")
+		}
+	case PromoTypeFixed:
+		if p.Value <= 0 {
+			return apperror.New(apperror.CodeInvalidInput, "fixed discount must be greater than 0")
+		}
+		if p.Currency == "" {
+			return apperror.New(apperror.CodeInvalidInput, "currency is required for a fixed discount")
+		}
+	case PromoTypeFreeShipping:
+		if p.Value != 0 {
+			return apperror.New(apperror.CodeInvalidInput, "free shipping codes have no value")
+		}
+	default:
+		return apperror.New(apperror.CodeInvalidInput, "unsupported promo code type "+string(p.Type))
+	}
+
+	if p.MinOrderAmount < 0 {
+		return apperror.New(apperror.CodeInvalidInput, "minimum order amount cannot be negative")
+	}
+	if p.MinOrderAmount > 0 && p.Currency == "" {
+		return apperror.New(apperror.CodeInvalidInput, "currency is required for a minimum order amount")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return apperror.New(apperror.CodeInvalidInput, "promo code must end after it starts")
+	}
+	if p.UsageLimit < 0 || p.PerCustomerLimit < 0 {
+		return apperror.New(apperror.CodeInvalidInput, "usage limits cannot be negative")
+	}
+	for _, id := range append(append([]string{}, p.ProductIDs...), p.VariantIDs...) {
+		if _, err := uuid.Parse(id); err != nil {
+			return apperror.New(apperror.CodeInvalidInput, "invalid product or variant ID "+id)
+		}
+	}
+	return nil
+}
+
+// Apply checks that the code can be used on the order and records its
+// discount lines, taking them off the order total. Usage limits are
+// checked when the code is redeemed.
+func (p *PromoCode) Apply(o *Order, now time.Time) error {
+	if !p.IsActive || (p.StartsAt != nil && now.Before(*p.StartsAt)) || (p.EndsAt != nil && !now.Before(*p.EndsAt)) {
+		return apperror.New(apperror.CodeInvalidInput, "promo code "+p.Code+" is not valid")
+	}
+	if p.Currency != "" && p.Currency != o.TotalAmount.Currency {
+		return apperror.New(apperror.CodeInvalidInput, "promo code "+p.Code+" is not valid for orders in "+o.TotalAmount.Currency)
+	}
+
+	var subtotal, eligible int
+	for _, item := range o.Items {
+		subtotal += item.TotalPrice.Amount
+		if p.appliesTo(item) {
+			eligible += item.TotalPrice.Amount
+		}
+	}
+	if subtotal < p.MinOrderAmount {
+		return apperror.New(apperror.CodeInvalidInput,
+			fmt.Sprintf("promo code %s needs an order of at least %d %s", p.Code, p.MinOrderAmount, p.Currency))
+	}
+	if eligible == 0 {
+		return apperror.New(apperror.CodeInvalidInput, "promo code "+p.Code+" does not apply to any item of the order")
+	}
+
+	var lines OrderDiscounts
+	switch p.Type {
+	case PromoTypeFreeShipping:
+		lines = OrderDiscounts{{Code: p.Code, Type: p.Type}}
+	case PromoTypePercentage:
+		for _, item := range o.Items {
+			if p.appliesTo(item) {
+				lines = append(lines, OrderDiscount{
+					Code:        p.Code,
+					Type:        p.Type,
+					OrderItemID: item.ID,
+					Amount:      item.TotalPrice.Amount * p.Value / 100,
+				})
+			}
+		}
+	case PromoTypeFixed:
+		// Spread the discount over the eligible items by their share of
+		// the eligible total; the last item takes the rounding remainder.
+		discount := min(p.Value, eligible)
+		left := discount
+		for _, item := range o.Items {
+			if !p.appliesTo(item) {
+				continue
+			}
+			amount := discount * item.TotalPrice.Amount / eligible
+			lines = append(lines, OrderDiscount{Code: p.Code, Type: p.Type, OrderItemID: item.ID, Amount: amount})
+			left -= amount
+		}
+		lines[len(lines)-1].Amount += left
+	}
+
+	o.PromoCode = p.Code
+	o.Discounts = lines
+	o.TotalAmount.Amount -= lines.Total()
+	o.UpdatedAt = now
+	return nil
+}
+
+func (p *PromoCode) appliesTo(item OrderItem) bool {
+	if len(p.ProductIDs) == 0 && len(p.VariantIDs) == 0 {
+		return true
+	}
+	return p.ProductIDs.contains(item.ProductID) || p.VariantIDs.contains(item.VariantID)
+}
+
+// NormalizePromoCode returns code the way it is stored: trimmed and
+// upper case.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func normalizeIDList(ids []string) IDList {
+	result := make(IDList, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// Value implements driver.Valuer for IDList
+func (l IDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner for IDList
+func (l *IDList) Scan(value any) error {
+	if value == nil {
+		*l = IDList{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into IDList", value)
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer for OrderDiscounts
+func (d OrderDiscounts) Value() (driver.Value, error) {
+	if d == nil {
+		return "[]", nil
+	}
+	return json.Marshal(d)
+}
+
+// Scan implements sql.Scanner for OrderDiscounts
+func (d *OrderDiscounts) Scan(value any) error {
+	if value == nil {
+		*d = OrderDiscounts{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into OrderDiscounts", value)
+	}
+
+	return json.Unmarshal(bytes, d)
+}
@@ -0,0 +1,243 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+type RefundKind string
+
+const (
+	// RefundKindCancellation takes items out of an order before it ships;
+	// they never left the warehouse and always go back to stock.
+	RefundKindCancellation RefundKind = "cancellation"
+	// RefundKindReturn takes back items of a shipped order; they go back
+	// to stock only if they can be sold again.
+	RefundKindReturn RefundKind = "return"
+)
+
+// Refund records line items taken out of an order and the money owed
+// back for them. It is the audit trail of partial cancellations and
+// returns: the order items only keep what is left.
+type Refund struct {
+	ID      string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrderID string     `gorm:"type:uuid;not null;index"`
+	Kind    RefundKind `gorm:"type:varchar(20);not null"`
+	// Amount is refunded to the customer; it is zero for orders that were
+	// not paid yet. PaymentID is the online payment it is refunded
+	// against, nil for orders paid offline.
+	Amount    int         `gorm:"not null;default:0"`
+	Currency  string      `gorm:"type:varchar(3);not null"`
+	PaymentID *string     `gorm:"type:uuid"`
+	Items     RefundItems `gorm:"type:jsonb;not null"`
+	Reason    string      `gorm:"type:text;not null"`
+	Actor     string      `gorm:"type:varchar(64);not null"`
+	CreatedAt time.Time   `gorm:"not null;default:now()"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// RefundItem is a quantity taken out of an order item, with the lots it
+// had been allocated from.
+type RefundItem struct {
+	OrderItemID string        `json:"order_item_id"`
+	VariantID   string        `json:"variant_id"`
+	Quantity    int           `json:"quantity"`
+	Amount      int           `json:"amount"`
+	Restock     bool          `json:"restock"`
+	Lots        OrderItemLots `json:"lots"`
+}
+
+type RefundItems []RefundItem
+
+// RemoveItems takes the requested quantities out of the order and
+// returns the refund recording it. Item and order totals and their tax
+// are recalculated and the items take their share of the discount with
+// them. A cancellation also re-checks the free shipping threshold and
+// recomputes the cash on delivery fee on the goods left, so the refund
+// can be less than the items' amounts, but never below zero; an order
+// cancelled down to nothing is cancelled as a whole and its shipping and
+// payment fee refunded too. The refund amount is booked against the
+// order's succeeded payment, if it was paid online.
+func (o *Order) RemoveItems(ctx context.Context, input dto.OrderRefundCreateDTO, now time.Time) (*Refund, error) {
+	if o.Status == OrderStatusCancelled {
+		return nil, apperror.New(apperror.CodeInvalidInput, "cancelled orders cannot be refunded")
+	}
+	if len(input.Items) == 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "refund must contain at least one item")
+	}
+
+	kind := RefundKindCancellation
+	if o.Status == OrderStatusShipped || o.Status == OrderStatusDelivered {
+		kind = RefundKindReturn
+	}
+
+	refund := &Refund{
+		ID:        uuid.New().String(),
+		OrderID:   o.ID,
+		Kind:      kind,
+		Currency:  o.TotalAmount.Currency,
+		Reason:    input.Reason,
+		Actor:     ActorFromContext(ctx),
+		CreatedAt: now,
+	}
+
+	totalBefore := o.TotalAmount.Amount
+	grossBefore := make(map[string]*big.Rat, len(input.Items))
+	seen := make(map[string]bool, len(input.Items))
+	for _, requested := range input.Items {
+		if seen[requested.ItemID] {
+			return nil, apperror.New(apperror.CodeInvalidInput, "order item "+requested.ItemID+" is listed twice")
+		}
+		seen[requested.ItemID] = true
+
+		item := o.findItem(requested.ItemID)
+		if item == nil {
+			return nil, apperror.New(apperror.CodeNotFound, "order item "+requested.ItemID+" not found")
+		}
+		if requested.Quantity <= 0 || requested.Quantity > item.Quantity {
+			return nil, apperror.New(apperror.CodeInvalidInput,
+				fmt.Sprintf("cannot remove %d of order item %s: %d left", requested.Quantity, item.ID, item.Quantity))
+		}
+
+		o.takeDiscount(item.ID, requested.Quantity, item.Quantity)
+		grossBefore[item.ID] = item.GrossAmount.Rat()
+		refund.Items = append(refund.Items, RefundItem{
+			OrderItemID: item.ID,
+			VariantID:   item.VariantID,
+			Quantity:    requested.Quantity,
+			Restock:     kind == RefundKindCancellation || requested.Restock,
+			Lots:        item.Lots.take(requested.Quantity),
+		})
+
+		item.Quantity -= requested.Quantity
+		item.TotalPrice.Amount = item.UnitPrice.Amount * item.Quantity
+	}
+
+	switch {
+	case kind == RefundKindCancellation && o.remainingQuantity() == 0:
+		o.PaymentFee = 0
+		o.ShippingCost = 0
+		o.Status = OrderStatusCancelled
+		o.HoldExpiresAt = nil
+	case kind == RefundKindCancellation:
+		o.recalculateCharges()
+	}
+
+	o.calculateTax()
+	o.UpdatedAt = now
+	for i := range refund.Items {
+		line := &refund.Items[i]
+		gross := grossBefore[line.OrderItemID]
+		line.Amount = int(roundRat(gross.Sub(gross, o.findItem(line.OrderItemID).GrossAmount.Rat())))
+	}
+	refund.Amount = max(0, totalBefore-o.TotalAmount.Amount)
+
+	if o.PaidAt == nil {
+		refund.Amount = 0
+		return refund, nil
+	}
+	if p := o.refundablePayment(); p != nil {
+		refund.Amount = min(refund.Amount, p.Amount-p.RefundedAmount)
+		p.RefundedAmount += refund.Amount
+		p.UpdatedAt = now
+		refund.PaymentID = &p.ID
+	}
+	return refund, nil
+}
+
+// recalculateCharges re-checks the free shipping threshold and the cash
+// on delivery fee against the goods left in the order, after discounts,
+// the way they were charged when the order was placed.
+func (o *Order) recalculateCharges() {
+	goods := -o.Discounts.Total()
+	for _, item := range o.Items {
+		goods += item.TotalPrice.Amount
+	}
+
+	if o.ShippingFreeFrom > 0 && !o.hasFreeShipping() {
+		o.ShippingCost = o.ShippingPrice
+		if goods >= o.ShippingFreeFrom {
+			o.ShippingCost = 0
+		}
+	}
+	if o.PaymentMethod == PaymentMethodCOD {
+		o.PaymentFee = o.CODFee.For(goods + o.ShippingCost)
+	}
+}
+
+func (o *Order) findItem(id string) *OrderItem {
+	for i := range o.Items {
+		if o.Items[i].ID == id {
+			return &o.Items[i]
+		}
+	}
+	return nil
+}
+
+// takeDiscount removes the share of the item's discount lines that
+// belongs to quantity of its count units, so a refund gives back what
+// was paid for the items.
+func (o *Order) takeDiscount(itemID string, quantity, count int) {
+	for i := range o.Discounts {
+		line := &o.Discounts[i]
+		if line.OrderItemID != itemID {
+			continue
+		}
+		line.Amount -= line.Amount * quantity / count
+	}
+}
+
+func (o *Order) remainingQuantity() int {
+	var quantity int
+	for _, item := range o.Items {
+		quantity += item.Quantity
+	}
+	return quantity
+}
+
+// refundablePayment returns the succeeded payment that still has money
+// left to refund.
+func (o *Order) refundablePayment() *Payment {
+	for i := range o.Payments {
+		p := &o.Payments[i]
+		if p.Status == PaymentStatusSucceeded && p.RefundedAmount < p.Amount {
+			return p
+		}
+	}
+	return nil
+}
+
+// take removes quantity from the allocations, latest first so that the
+// soonest expiring stock stays with the order, and returns what was
+// removed. Allocations that are used up are dropped.
+func (l *OrderItemLots) take(quantity int) OrderItemLots {
+	var taken OrderItemLots
+	lots := *l
+	for i := len(lots) - 1; i >= 0 && quantity > 0; i-- {
+		n := min(lots[i].Quantity, quantity)
+		part := lots[i]
+		part.Quantity = n
+		taken = append(taken, part)
+		lots[i].Quantity -= n
+		quantity -= n
+	}
+
+	kept := lots[:0]
+	for _, lot := range lots {
+		if lot.Quantity > 0 {
+			kept = append(kept, lot)
+		}
+	}
+	*l = kept
+	return taken
+}
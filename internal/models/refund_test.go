@@ -0,0 +1,327 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"caviar/internal/dto"
+)
+
+// testRefundOrder returns a confirmed, untaxed card order of 3 × 100 and
+// 2 × 50 that ships free from 350, otherwise for 70.
+func testRefundOrder() *Order {
+	o := &Order{
+		ID:     "order",
+		Status: OrderStatusConfirmed,
+		Items: []OrderItem{
+			{ID: "a", VariantID: "va", Quantity: 3, UnitPrice: Money{Amount: 100}, TotalPrice: Money{Amount: 300},
+				Lots: OrderItemLots{{LotID: "a1", Quantity: 2}, {LotID: "a2", Quantity: 1}}},
+			{ID: "b", VariantID: "vb", Quantity: 2, UnitPrice: Money{Amount: 50}, TotalPrice: Money{Amount: 100}},
+		},
+		TotalAmount:      Money{Currency: "UAH"},
+		PaymentMethod:    PaymentMethodCard,
+		ShippingPrice:    70,
+		ShippingFreeFrom: 350,
+		TaxInclusive:     true,
+	}
+	o.calculateTax()
+	return o
+}
+
+func paidOnline(o *Order) {
+	paidAt := time.Now()
+	o.PaidAt = &paidAt
+	o.Payments = []Payment{{ID: "payment", Status: PaymentStatusSucceeded, Amount: o.TotalAmount.Amount}}
+}
+
+func TestRemoveItems(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(o *Order)
+		items        []dto.OrderRefundItemDTO
+		wantKind     RefundKind
+		wantAmount   int
+		wantLines    []int
+		wantRestock  []bool
+		wantStatus   OrderStatus
+		wantShipping int
+		wantFee      int
+		wantTotal    int
+		wantPayment  bool
+	}{
+		{
+			name:         "unpaid order refunds nothing",
+			items:        []dto.OrderRefundItemDTO{{ItemID: "b", Quantity: 1}},
+			wantKind:     RefundKindCancellation,
+			wantAmount:   0,
+			wantLines:    []int{50},
+			wantRestock:  []bool{true},
+			wantStatus:   OrderStatusConfirmed,
+			wantShipping: 0,
+			wantTotal:    350,
+		},
+		{
+			name:        "paid order refunds the items",
+			setup:       paidOnline,
+			items:       []dto.OrderRefundItemDTO{{ItemID: "b", Quantity: 1}},
+			wantKind:    RefundKindCancellation,
+			wantAmount:  50,
+			wantLines:   []int{50},
+			wantRestock: []bool{true},
+			wantStatus:  OrderStatusConfirmed,
+			wantTotal:   350,
+			wantPayment: true,
+		},
+		{
+			name:         "dropping below free shipping charges it",
+			setup:        paidOnline,
+			items:        []dto.OrderRefundItemDTO{{ItemID: "a", Quantity: 1}},
+			wantKind:     RefundKindCancellation,
+			wantAmount:   30,
+			wantLines:    []int{100},
+			wantRestock:  []bool{true},
+			wantStatus:   OrderStatusConfirmed,
+			wantShipping: 70,
+			wantTotal:    370,
+			wantPayment:  true,
+		},
+		{
+			name: "cash on delivery fee is recomputed",
+			setup: func(o *Order) {
+				o.PaymentMethod = PaymentMethodCOD
+				o.CODFee = CODFee{Fixed: 20, Percent: 2}
+				o.PaymentFee = o.CODFee.For(400)
+				o.calculateTax()
+			},
+			items:       []dto.OrderRefundItemDTO{{ItemID: "b", Quantity: 1}},
+			wantKind:    RefundKindCancellation,
+			wantLines:   []int{50},
+			wantRestock: []bool{true},
+			wantStatus:  OrderStatusConfirmed,
+			wantFee:     27,
+			wantTotal:   377,
+		},
+		{
+			name: "returns keep the charges",
+			setup: func(o *Order) {
+				paidOnline(o)
+				o.Status = OrderStatusShipped
+			},
+			items: []dto.OrderRefundItemDTO{
+				{ItemID: "a", Quantity: 1, Restock: true},
+				{ItemID: "b", Quantity: 2},
+			},
+			wantKind:    RefundKindReturn,
+			wantAmount:  200,
+			wantLines:   []int{100, 100},
+			wantRestock: []bool{true, false},
+			wantStatus:  OrderStatusShipped,
+			wantTotal:   200,
+			wantPayment: true,
+		},
+		{
+			name: "cancelling everything cancels the order",
+			setup: func(o *Order) {
+				o.ShippingFreeFrom = 0
+				o.ShippingCost = 70
+				o.calculateTax()
+				paidOnline(o)
+			},
+			items: []dto.OrderRefundItemDTO{
+				{ItemID: "a", Quantity: 3},
+				{ItemID: "b", Quantity: 2},
+			},
+			wantKind:    RefundKindCancellation,
+			wantAmount:  470,
+			wantLines:   []int{300, 100},
+			wantRestock: []bool{true, true},
+			wantStatus:  OrderStatusCancelled,
+			wantTotal:   0,
+			wantPayment: true,
+		},
+		{
+			name: "items take their discount with them",
+			setup: func(o *Order) {
+				o.Discounts = OrderDiscounts{{Code: "TEN", Type: PromoTypeFixed, OrderItemID: "a", Amount: 30}}
+				o.calculateTax()
+				paidOnline(o)
+			},
+			items:        []dto.OrderRefundItemDTO{{ItemID: "a", Quantity: 1}},
+			wantKind:     RefundKindCancellation,
+			wantAmount:   20,
+			wantLines:    []int{90},
+			wantRestock:  []bool{true},
+			wantStatus:   OrderStatusConfirmed,
+			wantShipping: 70,
+			wantTotal:    350,
+			wantPayment:  true,
+		},
+		{
+			name: "refund is capped by what is left of the payment",
+			setup: func(o *Order) {
+				paidOnline(o)
+				o.Payments[0].RefundedAmount = 390
+			},
+			items:       []dto.OrderRefundItemDTO{{ItemID: "b", Quantity: 1}},
+			wantKind:    RefundKindCancellation,
+			wantAmount:  10,
+			wantLines:   []int{50},
+			wantRestock: []bool{true},
+			wantStatus:  OrderStatusConfirmed,
+			wantTotal:   350,
+			wantPayment: true,
+		},
+		{
+			name: "paid offline",
+			setup: func(o *Order) {
+				paidAt := time.Now()
+				o.PaidAt = &paidAt
+			},
+			items:       []dto.OrderRefundItemDTO{{ItemID: "b", Quantity: 1}},
+			wantKind:    RefundKindCancellation,
+			wantAmount:  50,
+			wantLines:   []int{50},
+			wantRestock: []bool{true},
+			wantStatus:  OrderStatusConfirmed,
+			wantTotal:   350,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := testRefundOrder()
+			if tt.setup != nil {
+				tt.setup(o)
+			}
+			now := time.Now()
+
+			refund, err := o.RemoveItems(context.Background(), dto.OrderRefundCreateDTO{Items: tt.items, Reason: "test"}, now)
+			if err != nil {
+				t.Fatalf("RemoveItems: %v", err)
+			}
+
+			if refund.Kind != tt.wantKind {
+				t.Errorf("Kind = %s, want %s", refund.Kind, tt.wantKind)
+			}
+			if refund.Amount != tt.wantAmount {
+				t.Errorf("Amount = %d, want %d", refund.Amount, tt.wantAmount)
+			}
+			if len(refund.Items) != len(tt.wantLines) {
+				t.Fatalf("%d refund lines, want %d", len(refund.Items), len(tt.wantLines))
+			}
+			for i, line := range refund.Items {
+				if line.Amount != tt.wantLines[i] {
+					t.Errorf("line %s amount = %d, want %d", line.OrderItemID, line.Amount, tt.wantLines[i])
+				}
+				if line.Restock != tt.wantRestock[i] {
+					t.Errorf("line %s restock = %v, want %v", line.OrderItemID, line.Restock, tt.wantRestock[i])
+				}
+			}
+			if o.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", o.Status, tt.wantStatus)
+			}
+			if o.ShippingCost != tt.wantShipping || o.PaymentFee != tt.wantFee {
+				t.Errorf("shipping, fee = %d, %d, want %d, %d", o.ShippingCost, o.PaymentFee, tt.wantShipping, tt.wantFee)
+			}
+			if o.TotalAmount.Amount != tt.wantTotal {
+				t.Errorf("TotalAmount = %d, want %d", o.TotalAmount.Amount, tt.wantTotal)
+			}
+			if !o.UpdatedAt.Equal(now) {
+				t.Errorf("UpdatedAt = %v, want %v", o.UpdatedAt, now)
+			}
+
+			if tt.wantPayment {
+				if refund.PaymentID == nil || *refund.PaymentID != "payment" {
+					t.Errorf("PaymentID = %v, want payment", refund.PaymentID)
+				}
+			} else if refund.PaymentID != nil {
+				t.Errorf("PaymentID = %s, want none", *refund.PaymentID)
+			}
+		})
+	}
+}
+
+func TestRemoveItemsTakesLatestLots(t *testing.T) {
+	o := testRefundOrder()
+	refund, err := o.RemoveItems(context.Background(), dto.OrderRefundCreateDTO{
+		Items: []dto.OrderRefundItemDTO{{ItemID: "a", Quantity: 2}},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("RemoveItems: %v", err)
+	}
+
+	taken := refund.Items[0].Lots
+	if len(taken) != 2 || taken[0].LotID != "a2" || taken[0].Quantity != 1 || taken[1].LotID != "a1" || taken[1].Quantity != 1 {
+		t.Errorf("taken lots = %+v, want 1 of a2 and 1 of a1", taken)
+	}
+	kept := o.Items[0].Lots
+	if len(kept) != 1 || kept[0].LotID != "a1" || kept[0].Quantity != 1 {
+		t.Errorf("kept lots = %+v, want 1 of a1", kept)
+	}
+	if o.Items[0].Quantity != 1 || o.Items[0].TotalPrice.Amount != 100 {
+		t.Errorf("item a = %d for %d, want 1 for 100", o.Items[0].Quantity, o.Items[0].TotalPrice.Amount)
+	}
+}
+
+func TestRemoveItemsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(o *Order)
+		items []dto.OrderRefundItemDTO
+	}{
+		{"cancelled order", func(o *Order) { o.Status = OrderStatusCancelled }, []dto.OrderRefundItemDTO{{ItemID: "a", Quantity: 1}}},
+		{"no items", nil, nil},
+		{"item listed twice", nil, []dto.OrderRefundItemDTO{{ItemID: "a", Quantity: 1}, {ItemID: "a", Quantity: 1}}},
+		{"unknown item", nil, []dto.OrderRefundItemDTO{{ItemID: "c", Quantity: 1}}},
+		{"more than left", nil, []dto.OrderRefundItemDTO{{ItemID: "b", Quantity: 3}}},
+		{"zero quantity", nil, []dto.OrderRefundItemDTO{{ItemID: "b", Quantity: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := testRefundOrder()
+			if tt.setup != nil {
+				tt.setup(o)
+			}
+			if _, err := o.RemoveItems(context.Background(), dto.OrderRefundCreateDTO{Items: tt.items}, time.Now()); err == nil {
+				t.Error("RemoveItems succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestRecalculateCharges(t *testing.T) {
+	tests := []struct {
+		name         string
+		freeFrom     int
+		shipping     int
+		discounts    OrderDiscounts
+		method       PaymentMethod
+		wantShipping int
+		wantFee      int
+	}{
+		{"flat shipping is kept", 0, 70, nil, PaymentMethodCard, 70, 0},
+		{"below the threshold", 500, 0, nil, PaymentMethodCard, 70, 0},
+		{"at the threshold", 400, 70, nil, PaymentMethodCard, 0, 0},
+		{"discounts count against the threshold", 400, 0,
+			OrderDiscounts{{Type: PromoTypePercentage, OrderItemID: "a", Amount: 1}}, PaymentMethodCard, 70, 0},
+		{"free shipping code", 500, 0,
+			OrderDiscounts{{Type: PromoTypeFreeShipping}}, PaymentMethodCard, 0, 0},
+		{"cash on delivery on goods and shipping", 500, 0, nil, PaymentMethodCOD, 70, 20 + 10},
+		{"cash on delivery without shipping", 400, 70, nil, PaymentMethodCOD, 0, 20 + 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := testRefundOrder()
+			o.ShippingFreeFrom = tt.freeFrom
+			o.ShippingCost = tt.shipping
+			o.Discounts = tt.discounts
+			o.PaymentMethod = tt.method
+			o.CODFee = CODFee{Fixed: 20, Percent: 2}
+
+			o.recalculateCharges()
+			if o.ShippingCost != tt.wantShipping || o.PaymentFee != tt.wantFee {
+				t.Errorf("shipping, fee = %d, %d, want %d, %d", o.ShippingCost, o.PaymentFee, tt.wantShipping, tt.wantFee)
+			}
+		})
+	}
+}
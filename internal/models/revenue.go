@@ -0,0 +1,55 @@
+package models
+
+import (
+	"math/big"
+	"sort"
+	"time"
+)
+
+// DailyRevenue is the revenue of the orders in one currency placed on a
+// day, cancelled orders left out.
+type DailyRevenue struct {
+	Day      time.Time
+	Currency string
+	Net      int64
+	Tax      int64
+	Gross    int64
+}
+
+// RevenueReport is revenue converted to one currency, each day at the
+// rate in effect on it. Unconverted lists the currencies that had no
+// rate on some day; that revenue is left out of the totals.
+type RevenueReport struct {
+	Currency    string
+	Net         int64
+	Tax         int64
+	Gross       int64
+	Unconverted []string
+}
+
+// ReportRevenue converts the daily revenue to currency. The days are
+// summed exactly and the totals rounded once.
+func ReportRevenue(days []DailyRevenue, rates ExchangeRates, currency string) RevenueReport {
+	report := RevenueReport{Currency: currency}
+	net, tax, gross := new(big.Rat), new(big.Rat), new(big.Rat)
+	missing := make(map[string]bool)
+	for _, day := range days {
+		rate, ok := rates.RateOn(day.Currency, currency, day.Day)
+		if !ok {
+			missing[day.Currency] = true
+			continue
+		}
+		net.Add(net, convertAmount(day.Net, rate))
+		tax.Add(tax, convertAmount(day.Tax, rate))
+		gross.Add(gross, convertAmount(day.Gross, rate))
+	}
+	report.Net = roundRat(net)
+	report.Tax = roundRat(tax)
+	report.Gross = roundRat(gross)
+
+	for c := range missing {
+		report.Unconverted = append(report.Unconverted, c)
+	}
+	sort.Strings(report.Unconverted)
+	return report
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportRevenue(t *testing.T) {
+	rates := ExchangeRates{
+		testRate(t, "EUR", "UAH", "44", "2026-01-01"),
+		testRate(t, "EUR", "UAH", "45", "2026-01-02"),
+		testRate(t, "UAH", "USD", "0.0245", "2026-01-01"),
+	}
+
+	tests := []struct {
+		name            string
+		days            []DailyRevenue
+		currency        string
+		wantNet         int64
+		wantTax         int64
+		wantGross       int64
+		wantUnconverted []string
+	}{
+		{
+			name:     "no revenue",
+			currency: "UAH",
+		},
+		{
+			name: "same currency",
+			days: []DailyRevenue{
+				{Day: testDate(t, "2026-01-01"), Currency: "UAH", Net: 100, Tax: 20, Gross: 120},
+				{Day: testDate(t, "2026-01-02"), Currency: "UAH", Net: 50, Tax: 10, Gross: 60},
+			},
+			currency: "UAH",
+			wantNet:  150, wantTax: 30, wantGross: 180,
+		},
+		{
+			name: "each day at its own rate",
+			days: []DailyRevenue{
+				{Day: testDate(t, "2026-01-01"), Currency: "EUR", Net: 10, Tax: 2, Gross: 12},
+				{Day: testDate(t, "2026-01-02"), Currency: "EUR", Net: 10, Tax: 2, Gross: 12},
+				{Day: testDate(t, "2026-01-02"), Currency: "UAH", Net: 5, Tax: 1, Gross: 6},
+			},
+			currency: "UAH",
+			wantNet:  895, wantTax: 179, wantGross: 1074,
+		},
+		{
+			// 0.49 + 0.49 is rounded once to 1; rounding every day would give 0.
+			name: "rounded once",
+			days: []DailyRevenue{
+				{Day: testDate(t, "2026-01-01"), Currency: "UAH", Net: 20, Gross: 20},
+				{Day: testDate(t, "2026-01-02"), Currency: "UAH", Net: 20, Gross: 20},
+			},
+			currency: "USD",
+			wantNet:  1, wantGross: 1,
+		},
+		{
+			name: "inverse rate",
+			days: []DailyRevenue{
+				{Day: testDate(t, "2026-01-02"), Currency: "UAH", Net: 450, Tax: 90, Gross: 540},
+			},
+			currency: "EUR",
+			wantNet:  10, wantTax: 2, wantGross: 12,
+		},
+		{
+			name: "currencies without a rate are left out",
+			days: []DailyRevenue{
+				{Day: testDate(t, "2025-12-31"), Currency: "EUR", Net: 10, Gross: 10},
+				{Day: testDate(t, "2026-01-01"), Currency: "PLN", Net: 10, Gross: 10},
+				{Day: testDate(t, "2026-01-02"), Currency: "PLN", Net: 10, Gross: 10},
+				{Day: testDate(t, "2026-01-01"), Currency: "UAH", Net: 7, Gross: 7},
+			},
+			currency:        "UAH",
+			wantNet:         7,
+			wantGross:       7,
+			wantUnconverted: []string{"EUR", "PLN"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ReportRevenue(tt.days, rates, tt.currency)
+			if got.Currency != tt.currency {
+				t.Errorf("Currency = %s, want %s", got.Currency, tt.currency)
+			}
+			if got.Net != tt.wantNet || got.Tax != tt.wantTax || got.Gross != tt.wantGross {
+				t.Errorf("net, tax, gross = %d, %d, %d, want %d, %d, %d",
+					got.Net, got.Tax, got.Gross, tt.wantNet, tt.wantTax, tt.wantGross)
+			}
+			if strings.Join(got.Unconverted, ",") != strings.Join(tt.wantUnconverted, ",") {
+				t.Errorf("Unconverted = %v, want %v", got.Unconverted, tt.wantUnconverted)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+// ShippingZone groups the delivery countries that share shipping rates.
+// A zone without countries covers every country no other zone lists.
+type ShippingZone struct {
+	ID        string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Code      string       `gorm:"type:varchar(32);not null;uniqueIndex"`
+	Name      string       `gorm:"type:varchar(255);not null"`
+	Countries CountryCodes `gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt time.Time    `gorm:"not null;default:now()"`
+	UpdatedAt time.Time    `gorm:"not null;default:now()"`
+}
+
+func (ShippingZone) TableName() string {
+	return "shipping_zones"
+}
+
+// ShippingRate is the price of delivering a parcel of up to MaxWeight
+// grams with one delivery type within a zone. A MaxWeight of 0 has no
+// upper bound. Orders of at least FreeFrom, after discounts, ship free;
+// 0 means never. A free rate, with neither a price nor a threshold,
+// costs nothing in any currency and also quotes orders in the others.
+type ShippingRate struct {
+	ID           string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ZoneID       string        `gorm:"type:uuid;not null"`
+	Zone         *ShippingZone `gorm:"foreignKey:ZoneID"`
+	DeliveryType DeliveryType  `gorm:"type:varchar(20);not null"`
+	MaxWeight    int           `gorm:"not null;default:0"`
+	Price        int           `gorm:"not null"`
+	Currency     string        `gorm:"type:varchar(3);not null"`
+	FreeFrom     int           `gorm:"not null;default:0"`
+	IsActive     bool          `gorm:"not null;default:true"`
+	CreatedAt    time.Time     `gorm:"not null;default:now()"`
+	UpdatedAt    time.Time     `gorm:"not null;default:now()"`
+}
+
+func (ShippingRate) TableName() string {
+	return "shipping_rates"
+}
+
+// Packaging describes how goods are packed; masses are in grams. Goods
+// travel with one ice pack per IcePackPerGrams of them, at least one.
+type Packaging struct {
+	BoxWeight       int
+	IcePackWeight   int
+	IcePackPerGrams int
+}
+
+// ParcelWeight returns the weight of a parcel holding goods grams.
+func (p Packaging) ParcelWeight(goods int) int {
+	icePacks := 1
+	if p.IcePackPerGrams > 0 {
+		icePacks = max(1, (goods+p.IcePackPerGrams-1)/p.IcePackPerGrams)
+	}
+	return goods + p.BoxWeight + icePacks*p.IcePackWeight
+}
+
+// ShippingQuote is the shipping charge of a parcel. Price is what the
+// rate asks; Charge is zero when the parcel ships free.
+type ShippingQuote struct {
+	Zone         string
+	DeliveryType DeliveryType
+	Weight       int
+	Price        int
+	Currency     string
+	FreeFrom     int
+	Free         bool
+}
+
+// Charge returns what the customer pays for shipping.
+func (q *ShippingQuote) Charge() int {
+	if q.Free {
+		return 0
+	}
+	return q.Price
+}
+
+// QuoteShipping prices a parcel of weight grams to country. The zones
+// listing the country are tried before the catch-all zones, and within
+// a zone the lightest rate the parcel fits wins. subtotal is the value
+// of the goods after discounts, for the free shipping threshold. Rates
+// in the subtotal's currency are preferred to free rates in another.
+func QuoteShipping(rates []*ShippingRate, deliveryType DeliveryType, country string, weight int, subtotal Money) (*ShippingQuote, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+
+	var listed, listedFree, anywhere, anywhereFree []*ShippingRate
+	for _, r := range rates {
+		if !r.IsActive || r.Zone == nil || r.DeliveryType != deliveryType {
+			continue
+		}
+		sameCurrency := r.Currency == subtotal.Currency
+		if !sameCurrency && !r.isFree() {
+			continue
+		}
+		switch {
+		case r.Zone.covers(country) && sameCurrency:
+			listed = append(listed, r)
+		case r.Zone.covers(country):
+			listedFree = append(listedFree, r)
+		case len(r.Zone.Countries) == 0 && sameCurrency:
+			anywhere = append(anywhere, r)
+		case len(r.Zone.Countries) == 0:
+			anywhereFree = append(anywhereFree, r)
+		}
+	}
+
+	var candidates []*ShippingRate
+	for _, tier := range [][]*ShippingRate{listed, listedFree, anywhere, anywhereFree} {
+		if len(tier) > 0 {
+			candidates = tier
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput,
+			fmt.Sprintf("%s delivery to %s is not available", deliveryType, country))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].MaxWeight, candidates[j].MaxWeight
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	for _, r := range candidates {
+		if r.MaxWeight == 0 || weight <= r.MaxWeight {
+			return &ShippingQuote{
+				Zone:         r.Zone.Code,
+				DeliveryType: deliveryType,
+				Weight:       weight,
+				Price:        r.Price,
+				Currency:     subtotal.Currency,
+				FreeFrom:     r.FreeFrom,
+				Free:         r.FreeFrom > 0 && subtotal.Amount >= r.FreeFrom,
+			}, nil
+		}
+	}
+	return nil, apperror.New(apperror.CodeInvalidInput,
+		fmt.Sprintf("a parcel of %d g is too heavy for %s delivery to %s", weight, deliveryType, country))
+}
+
+func (r *ShippingRate) isFree() bool {
+	return r.Price == 0 && r.FreeFrom == 0
+}
+
+func (z *ShippingZone) covers(country string) bool {
+	for _, c := range z.Countries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyShipping adds the quoted shipping charge to the order. A free
+// shipping promo code waives it.
+func (o *Order) ApplyShipping(quote *ShippingQuote) {
+	if o.hasFreeShipping() {
+		quote.Free = true
+	}
+	o.ShippingWeight = quote.Weight
+	o.ShippingPrice = quote.Price
+	o.ShippingFreeFrom = quote.FreeFrom
+	o.ShippingCost = quote.Charge()
+	o.TotalAmount.Amount += o.ShippingCost
+}
+
+func (o *Order) hasFreeShipping() bool {
+	for _, line := range o.Discounts {
+		if line.Type == PromoTypeFreeShipping {
+			return true
+		}
+	}
+	return false
+}
+
+func NewShippingZone(input dto.ShippingZoneCreateDTO) (*ShippingZone, error) {
+	code, err := normalizeWarehouseCode(input.Code)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInvalidInput,
+			"zone code must be up to 32 letters, digits, dashes or underscores")
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, apperror.New(apperror.CodeInvalidInput, "zone name is required")
+	}
+	countries, err := normalizeCountryCodes(input.Countries)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &ShippingZone{
+		ID:        uuid.New().String(),
+		Code:      code,
+		Name:      name,
+		Countries: countries,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ApplyPatch changes the fields present in input.
+func (z *ShippingZone) ApplyPatch(input dto.ShippingZonePatchDTO) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return apperror.New(apperror.CodeInvalidInput, "zone name is required")
+		}
+		z.Name = name
+	}
+	if input.Countries != nil {
+		countries, err := normalizeCountryCodes(input.Countries)
+		if err != nil {
+			return err
+		}
+		z.Countries = countries
+	}
+	z.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func NewShippingRate(input dto.ShippingRateCreateDTO) (*ShippingRate, error) {
+	now := time.Now().UTC()
+	r := &ShippingRate{
+		ID:           uuid.New().String(),
+		ZoneID:       input.ZoneID,
+		DeliveryType: DeliveryType(input.DeliveryType),
+		MaxWeight:    input.MaxWeight,
+		Price:        input.Price,
+		Currency:     strings.ToUpper(strings.TrimSpace(input.Currency)),
+		FreeFrom:     input.FreeFrom,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ApplyPatch changes the fields present in input.
+func (r *ShippingRate) ApplyPatch(input dto.ShippingRatePatchDTO) error {
+	if input.MaxWeight != nil {
+		r.MaxWeight = *input.MaxWeight
+	}
+	if input.Price != nil {
+		r.Price = *input.Price
+	}
+	if input.FreeFrom != nil {
+		r.FreeFrom = *input.FreeFrom
+	}
+	if input.IsActive != nil {
+		r.IsActive = *input.IsActive
+	}
+	r.UpdatedAt = time.Now().UTC()
+	return r.validate()
+}
+
+func (r *ShippingRate) validate() error {
+	switch r.DeliveryType {
+	case DeliveryTypePostOffice, DeliveryTypeCourier, DeliveryTypeAddress:
+	default:
+		return apperror.New(apperror.CodeInvalidInput, "invalid delivery type")
+	}
+	if r.MaxWeight < 0 {
+		return apperror.New(apperror.CodeInvalidInput, "maximum weight cannot be negative")
+	}
+	if r.Price < 0 || r.FreeFrom < 0 {
+		return apperror.New(apperror.CodeInvalidInput, "price and free shipping threshold cannot be negative")
+	}
+	if r.Currency == "" {
+		return apperror.New(apperror.CodeInvalidInput, "currency is required")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package models
+
+import "testing"
+
+func TestQuoteShipping(t *testing.T) {
+	ua := &ShippingZone{Code: "UA", Countries: CountryCodes{"UA"}}
+	eu := &ShippingZone{Code: "EU", Countries: CountryCodes{"PL", "DE"}}
+	lt := &ShippingZone{Code: "LT", Countries: CountryCodes{"LT"}}
+	world := &ShippingZone{Code: "WORLD"}
+
+	post, courier := DeliveryTypePostOffice, DeliveryTypeCourier
+	rates := []*ShippingRate{
+		// Listed out of weight order on purpose.
+		{Zone: ua, DeliveryType: post, MaxWeight: 0, Price: 200, Currency: "UAH", IsActive: true},
+		{Zone: ua, DeliveryType: post, MaxWeight: 5000, Price: 90, Currency: "UAH", IsActive: true},
+		{Zone: ua, DeliveryType: post, MaxWeight: 1000, Price: 60, Currency: "UAH", FreeFrom: 2000, IsActive: true},
+		{Zone: ua, DeliveryType: courier, Price: 150, Currency: "UAH", IsActive: true},
+		{Zone: ua, DeliveryType: courier, Price: 10, Currency: "UAH", IsActive: false},
+		{Zone: nil, DeliveryType: courier, Price: 5, Currency: "UAH", IsActive: true},
+		{Zone: eu, DeliveryType: post, MaxWeight: 20000, Price: 10, Currency: "EUR", FreeFrom: 100, IsActive: true},
+		{Zone: lt, DeliveryType: post, Price: 12, Currency: "EUR", IsActive: true},
+		{Zone: lt, DeliveryType: post, Price: 0, Currency: "USD", IsActive: true},
+		{Zone: world, DeliveryType: post, Price: 500, Currency: "UAH", IsActive: true},
+		{Zone: world, DeliveryType: post, Price: 0, Currency: "USD", IsActive: true},
+	}
+
+	tests := []struct {
+		name         string
+		deliveryType DeliveryType
+		country      string
+		weight       int
+		subtotal     Money
+		wantZone     string
+		wantPrice    int
+		wantFreeFrom int
+		wantCharge   int
+		wantErr      bool
+	}{
+		{"lightest rate the parcel fits", post, "UA", 800, Money{1000, "UAH"}, "UA", 60, 2000, 60, false},
+		{"at the weight limit", post, "UA", 1000, Money{1000, "UAH"}, "UA", 60, 2000, 60, false},
+		{"next rate up", post, "UA", 1200, Money{1000, "UAH"}, "UA", 90, 0, 90, false},
+		{"unbounded rate last", post, "UA", 6000, Money{1000, "UAH"}, "UA", 200, 0, 200, false},
+		{"free from the threshold", post, "UA", 800, Money{2000, "UAH"}, "UA", 60, 2000, 0, false},
+		{"country in any case", post, " ua ", 800, Money{1000, "UAH"}, "UA", 60, 2000, 60, false},
+		{"inactive and zoneless rates ignored", courier, "UA", 800, Money{1000, "UAH"}, "UA", 150, 0, 150, false},
+		{"listed zone in the subtotal's currency", post, "PL", 800, Money{50, "EUR"}, "EU", 10, 100, 10, false},
+		{"listed zone in another currency skipped", post, "PL", 800, Money{1000, "UAH"}, "WORLD", 500, 0, 500, false},
+		{"catch-all zone", post, "FR", 800, Money{1000, "UAH"}, "WORLD", 500, 0, 500, false},
+		{"own currency preferred to a free rate", post, "LT", 800, Money{50, "EUR"}, "LT", 12, 0, 12, false},
+		{"free rate in another currency", post, "LT", 800, Money{1000, "UAH"}, "LT", 0, 0, 0, false},
+		{"free catch-all rate in another currency", post, "FR", 800, Money{50, "EUR"}, "WORLD", 0, 0, 0, false},
+		{"too heavy for the listed zone", post, "PL", 25000, Money{50, "EUR"}, "", 0, 0, 0, true},
+		{"delivery type without rates", DeliveryTypeAddress, "UA", 800, Money{1000, "UAH"}, "", 0, 0, 0, true},
+		{"no zone for the country", courier, "FR", 800, Money{1000, "UAH"}, "", 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := QuoteShipping(rates, tt.deliveryType, tt.country, tt.weight, tt.subtotal)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("QuoteShipping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if quote.Zone != tt.wantZone || quote.Price != tt.wantPrice || quote.FreeFrom != tt.wantFreeFrom || quote.Charge() != tt.wantCharge {
+				t.Errorf("quote = %s %d free from %d charging %d, want %s %d free from %d charging %d",
+					quote.Zone, quote.Price, quote.FreeFrom, quote.Charge(),
+					tt.wantZone, tt.wantPrice, tt.wantFreeFrom, tt.wantCharge)
+			}
+			if quote.Currency != tt.subtotal.Currency || quote.Weight != tt.weight || quote.DeliveryType != tt.deliveryType {
+				t.Errorf("quote = %s, %d g, %s, want %s, %d g, %s",
+					quote.Currency, quote.Weight, quote.DeliveryType, tt.subtotal.Currency, tt.weight, tt.deliveryType)
+			}
+		})
+	}
+}
+
+func TestParcelWeight(t *testing.T) {
+	p := Packaging{BoxWeight: 200, IcePackWeight: 100, IcePackPerGrams: 500}
+	tests := []struct {
+		goods int
+		want  int
+	}{
+		{0, 300},
+		{250, 550},
+		{500, 800},
+		{501, 901},
+		{1500, 2000},
+	}
+	for _, tt := range tests {
+		if got := p.ParcelWeight(tt.goods); got != tt.want {
+			t.Errorf("ParcelWeight(%d) = %d, want %d", tt.goods, got, tt.want)
+		}
+	}
+
+	if got := (Packaging{BoxWeight: 200, IcePackWeight: 100}).ParcelWeight(5000); got != 5300 {
+		t.Errorf("ParcelWeight without a pack size = %d, want 5300", got)
+	}
+}
+
+func TestApplyShipping(t *testing.T) {
+	quote := &ShippingQuote{Weight: 900, Price: 60, FreeFrom: 2000}
+
+	o := &Order{TotalAmount: Money{Amount: 1000}}
+	o.ApplyShipping(quote)
+	if o.ShippingCost != 60 || o.TotalAmount.Amount != 1060 || o.ShippingWeight != 900 {
+		t.Errorf("shipping %d, total %d, weight %d, want 60, 1060, 900", o.ShippingCost, o.TotalAmount.Amount, o.ShippingWeight)
+	}
+
+	quote = &ShippingQuote{Weight: 900, Price: 60, FreeFrom: 2000}
+	o = &Order{TotalAmount: Money{Amount: 1000}, Discounts: OrderDiscounts{{Type: PromoTypeFreeShipping}}}
+	o.ApplyShipping(quote)
+	if o.ShippingCost != 0 || o.TotalAmount.Amount != 1000 || o.ShippingPrice != 60 {
+		t.Errorf("free shipping code: shipping %d, total %d, price %d, want 0, 1000, 60", o.ShippingCost, o.TotalAmount.Amount, o.ShippingPrice)
+	}
+}
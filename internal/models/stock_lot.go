@@ -0,0 +1,128 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+// DateLayout is the format of calendar dates such as packing and expiry
+// dates in the API.
+const DateLayout = "2006-01-02"
+
+// StockLot is a batch of a variant received together, with its own
+// packing and expiry date. The quantities of a variant's lots always add
+// up to its stock.
+//
+// A lot sits in one warehouse; transferring part of it creates a lot
+// with the same number in the destination warehouse.
+//
+// Stock that was never received as a lot (opening balances, initial
+// stock, unassigned adjustments) sits in the variant's unlabelled lot of
+// the warehouse: LotNumber is empty and the dates are unknown. FEFO
+// allocation uses it last.
+type StockLot struct {
+	ID          string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	VariantID   string     `gorm:"type:uuid;not null;uniqueIndex:idx_stock_lots_variant_warehouse_lot_number"`
+	ProductID   string     `gorm:"type:uuid;not null"`
+	WarehouseID string     `gorm:"type:uuid;not null;uniqueIndex:idx_stock_lots_variant_warehouse_lot_number"`
+	LotNumber   string     `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_stock_lots_variant_warehouse_lot_number"`
+	PackedAt    *time.Time `gorm:"type:date"`
+	ExpiresAt   *time.Time `gorm:"type:date"`
+	// ReceivedQuantity is everything ever received into the lot;
+	// Quantity is what is left of it.
+	ReceivedQuantity int `gorm:"not null;default:0"`
+	Quantity         int `gorm:"not null;default:0"`
+	// ExpiryAlertedAt is set once staff were alerted that the lot is
+	// nearing expiry.
+	ExpiryAlertedAt *time.Time
+	Variant         *Variant   `gorm:"foreignKey:VariantID"`
+	Product         *Product   `gorm:"foreignKey:ProductID"`
+	Warehouse       *Warehouse `gorm:"foreignKey:WarehouseID"`
+	CreatedAt       time.Time  `gorm:"not null;default:now()"`
+	UpdatedAt       time.Time  `gorm:"not null;default:now()"`
+}
+
+func (StockLot) TableName() string {
+	return "stock_lots"
+}
+
+// IsExpired reports whether the lot's expiry date is before the day of now.
+func (l *StockLot) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && l.ExpiresAt.Before(startOfDay(now))
+}
+
+// DaysToExpiry returns the number of days from the day of now until the
+// expiry date, negative once expired, or nil when the date is unknown.
+func (l *StockLot) DaysToExpiry(now time.Time) *int {
+	if l.ExpiresAt == nil {
+		return nil
+	}
+	days := int(startOfDay(*l.ExpiresAt).Sub(startOfDay(now)).Hours() / 24)
+	return &days
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NewStockLot validates a lot being received for a variant. The quantity
+// is booked by the receipt movement, not set here. An empty WarehouseID stands for the default warehouse.
+func NewStockLot(variantID string, input dto.StockLotCreateDTO, now time.Time) (*StockLot, error) {
+	lotNumber := strings.TrimSpace(input.LotNumber)
+	if lotNumber == "" {
+		return nil, apperror.New(apperror.CodeInvalidInput, "lot number is required")
+	}
+	if len(lotNumber) > 64 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "lot number must be at most 64 characters")
+	}
+
+	expiresAt, err := time.Parse(DateLayout, input.ExpiresAt)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInvalidInput, "expires_at must be a date in YYYY-MM-DD format")
+	}
+
+	lot := &StockLot{
+		ID:          uuid.New().String(),
+		VariantID:   variantID,
+		WarehouseID: strings.TrimSpace(input.WarehouseID),
+		LotNumber:   lotNumber,
+		ExpiresAt:   &expiresAt,
+	}
+
+	if input.PackedAt != "" {
+		packedAt, err := time.Parse(DateLayout, input.PackedAt)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInvalidInput, "packed_at must be a date in YYYY-MM-DD format")
+		}
+		if !expiresAt.After(packedAt) {
+			return nil, apperror.New(apperror.CodeInvalidInput, "expires_at must be after packed_at")
+		}
+		if packedAt.After(startOfDay(now)) {
+			return nil, apperror.New(apperror.CodeInvalidInput, "packed_at cannot be in the future")
+		}
+		lot.PackedAt = &packedAt
+	}
+
+	if lot.IsExpired(now) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "cannot receive a lot that has already expired")
+	}
+
+	return lot, nil
+}
+
+// OrderItemLot records how much of an order item was taken from a lot,
+// for traceability and recalls.
+type OrderItemLot struct {
+	LotID     string     `json:"lot_id"`
+	LotNumber string     `json:"lot_number,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Quantity  int        `json:"quantity"`
+}
+
+type OrderItemLots []OrderItemLot
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+type StockMovementReason string
+
+const (
+	StockReasonOrderReserve StockMovementReason = "order_reserve"
+	StockReasonOrderCancel  StockMovementReason = "order_cancel"
+	StockReasonOrderReturn  StockMovementReason = "order_return"
+	StockReasonAdjustment   StockMovementReason = "adjustment"
+	StockReasonReceipt      StockMovementReason = "receipt"
+	StockReasonWriteOff     StockMovementReason = "write_off"
+	StockReasonTransfer     StockMovementReason = "transfer"
+)
+
+// StockMovement is an entry in the inventory ledger. Every change of
+// product_variants.stock writes one, so the sum of deltas for a variant
+// always equals its stock. A change spread over several lots writes one
+// entry per lot.
+type StockMovement struct {
+	ID          string              `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	VariantID   string              `gorm:"type:uuid;not null"`
+	ProductID   string              `gorm:"type:uuid;not null"`
+	LotID       *string             `gorm:"type:uuid"`
+	WarehouseID *string             `gorm:"type:uuid"`
+	Delta       int                 `gorm:"not null"`
+	Reason      StockMovementReason `gorm:"type:varchar(20);not null"`
+	ReferenceID string              `gorm:"type:varchar(64)"`
+	Actor       string              `gorm:"type:varchar(64);not null"`
+	Note        string              `gorm:"type:text"`
+	// Balance is the variant's stock right after this movement.
+	Balance   int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+// StockDiscrepancy is a variant whose stock differs from its ledger or
+// from the sum of its lots.
+type StockDiscrepancy struct {
+	VariantID   string
+	ProductID   string
+	SKU         string
+	Stock       int
+	LedgerStock int
+	LotStock    int
+}
+
+func NewStockMovement(ctx context.Context, variantID string, delta int, reason StockMovementReason, referenceID, note string) *StockMovement {
+	return &StockMovement{
+		ID:          uuid.New().String(),
+		VariantID:   variantID,
+		Delta:       delta,
+		Reason:      reason,
+		ReferenceID: referenceID,
+		Actor:       ActorFromContext(ctx),
+		Note:        note,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// NewManualStockMovement validates a movement entered by staff. Order
+// reasons are reserved for the order flow.
+func NewManualStockMovement(ctx context.Context, variantID string, input dto.StockMovementCreateDTO) (*StockMovement, error) {
+	reason := StockMovementReason(input.Reason)
+	switch reason {
+	case StockReasonReceipt:
+		if input.Delta <= 0 {
+			return nil, apperror.New(apperror.CodeInvalidInput, "a receipt must add stock")
+		}
+	case StockReasonWriteOff:
+		if input.Delta >= 0 {
+			return nil, apperror.New(apperror.CodeInvalidInput, "a write-off must remove stock")
+		}
+	case StockReasonAdjustment:
+		if input.Delta == 0 {
+			return nil, apperror.New(apperror.CodeInvalidInput, "an adjustment must change stock")
+		}
+	default:
+		return nil, apperror.New(apperror.CodeInvalidInput,
+			"reason must be one of receipt, write_off, adjustment")
+	}
+
+	note := strings.TrimSpace(input.Note)
+	if reason != StockReasonReceipt && note == "" {
+		return nil, apperror.New(apperror.CodeInvalidInput, "a note is required for write-offs and adjustments")
+	}
+
+	m := NewStockMovement(ctx, variantID, input.Delta, reason, strings.TrimSpace(input.ReferenceID), note)
+	if lotID := strings.TrimSpace(input.LotID); lotID != "" {
+		m.LotID = &lotID
+	}
+	if warehouseID := strings.TrimSpace(input.WarehouseID); warehouseID != "" {
+		m.WarehouseID = &warehouseID
+	}
+	return m, nil
+}
+
+// StockTransfer moves stock of a variant between warehouses. It is
+// recorded as a pair of transfer movements per lot sharing the transfer
+// ID as reference.
+type StockTransfer struct {
+	ID              string
+	VariantID       string
+	FromWarehouseID string
+	ToWarehouseID   string
+	LotID           *string
+	Quantity        int
+	Note            string
+}
+
+func NewStockTransfer(variantID string, input dto.StockTransferCreateDTO) (*StockTransfer, error) {
+	from := strings.TrimSpace(input.FromWarehouseID)
+	to := strings.TrimSpace(input.ToWarehouseID)
+	if from == "" || to == "" {
+		return nil, apperror.New(apperror.CodeInvalidInput, "source and destination warehouses are required")
+	}
+	if from == to {
+		return nil, apperror.New(apperror.CodeInvalidInput, "source and destination warehouses must differ")
+	}
+	if input.Quantity <= 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "transfer quantity must be > 0")
+	}
+
+	t := &StockTransfer{
+		ID:              uuid.New().String(),
+		VariantID:       variantID,
+		FromWarehouseID: from,
+		ToWarehouseID:   to,
+		Quantity:        input.Quantity,
+		Note:            strings.TrimSpace(input.Note),
+	}
+	if lotID := strings.TrimSpace(input.LotID); lotID != "" {
+		t.LotID = &lotID
+	}
+	return t, nil
+}
+
+type actorKey struct{}
+
+// ContextWithActor records who is acting, for audit trails such as the
+// stock ledger.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by ContextWithActor, or
+// "system" for background work.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "system"
+}
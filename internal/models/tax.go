@@ -0,0 +1,131 @@
+package models
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+// TaxRate is the VAT charged on orders delivered to a country. Rate is
+// in basis points: 2000 is 20%. Prices of inclusive countries already
+// contain the tax, which is carved out of them; exclusive countries have
+// it added on top. Orders to countries without a rate are not taxed.
+type TaxRate struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Country   string    `gorm:"type:varchar(2);not null;uniqueIndex"`
+	Rate      int       `gorm:"not null"`
+	Inclusive bool      `gorm:"not null;default:true"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+	UpdatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+// maxTaxRate caps rates at 100%.
+const maxTaxRate = 10000
+
+func NewTaxRate(input dto.TaxRateCreateDTO) (*TaxRate, error) {
+	country := strings.ToUpper(strings.TrimSpace(input.Country))
+	if !countryCodePattern.MatchString(country) {
+		return nil, apperror.New(apperror.CodeInvalidInput, "invalid country code "+country+", use ISO 3166-1 alpha-2")
+	}
+
+	now := time.Now().UTC()
+	t := &TaxRate{
+		ID:        uuid.New().String(),
+		Country:   country,
+		Rate:      input.Rate,
+		Inclusive: input.Inclusive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ApplyPatch changes the fields present in input.
+func (t *TaxRate) ApplyPatch(input dto.TaxRatePatchDTO) error {
+	if input.Rate != nil {
+		t.Rate = *input.Rate
+	}
+	if input.Inclusive != nil {
+		t.Inclusive = *input.Inclusive
+	}
+	t.UpdatedAt = time.Now().UTC()
+	return t.validate()
+}
+
+func (t *TaxRate) validate() error {
+	if t.Rate < 0 || t.Rate > maxTaxRate {
+		return apperror.New(apperror.CodeInvalidInput, "tax rate must be between 0 and 10000 basis points")
+	}
+	return nil
+}
+
+// ApplyTax records the tax rate of the order's delivery country and
+// splits every line and the order into net and tax amounts; nil means
+// the order is not taxed. Exclusive rates add the tax to TotalAmount.
+// It goes last, once discounts, shipping and fees are in.
+func (o *Order) ApplyTax(rate *TaxRate) {
+	o.TaxRate = 0
+	o.TaxInclusive = true
+	if rate != nil {
+		o.TaxRate = rate.Rate
+		o.TaxInclusive = rate.Inclusive
+	}
+	o.calculateTax()
+}
+
+// calculateTax recalculates the tax breakdown and TotalAmount from the
+// items, discounts and charges of the order at its recorded rate. Items
+// are taxed on what is paid for them after discounts; shipping and the
+// payment fee are taxed at the same rate. Lines keep their exact split,
+// and the order's tax is rounded once, from their sum, the way the
+// invoice states it.
+func (o *Order) calculateTax() {
+	net, tax := new(big.Rat), new(big.Rat)
+	for i := range o.Items {
+		item := &o.Items[i]
+		itemNet, itemTax := o.splitTax(item.TotalPrice.Amount - o.Discounts.forItem(item.ID))
+		item.TaxAmount = newDecimal(itemTax)
+		item.GrossAmount = newDecimal(new(big.Rat).Add(itemNet, itemTax))
+		item.NetAmount = Decimal{rat: new(big.Rat).Sub(item.GrossAmount.Rat(), item.TaxAmount.Rat())}
+		net.Add(net, itemNet)
+		tax.Add(tax, itemTax)
+	}
+
+	chargesNet, chargesTax := o.splitTax(o.ShippingCost + o.PaymentFee)
+	net.Add(net, chargesNet)
+	tax.Add(tax, chargesTax)
+
+	// What the customer pays stays the sum of the prices: gross ones
+	// when they include the tax, net ones plus the tax otherwise.
+	o.TaxAmount = int(roundRat(tax))
+	if o.TaxInclusive {
+		o.TotalAmount.Amount = int(roundRat(net.Add(net, tax)))
+		o.NetAmount = o.TotalAmount.Amount - o.TaxAmount
+		return
+	}
+	o.NetAmount = int(roundRat(net))
+	o.TotalAmount.Amount = o.NetAmount + o.TaxAmount
+}
+
+// splitTax returns the exact net and tax parts of amount, which is
+// gross for inclusive rates and net for exclusive ones.
+func (o *Order) splitTax(amount int) (net, tax *big.Rat) {
+	paid := big.NewRat(int64(amount), 1)
+	if o.TaxInclusive {
+		tax = new(big.Rat).Mul(paid, big.NewRat(int64(o.TaxRate), int64(maxTaxRate+o.TaxRate)))
+		return new(big.Rat).Sub(paid, tax), tax
+	}
+	return paid, new(big.Rat).Mul(paid, big.NewRat(int64(o.TaxRate), maxTaxRate))
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSplitTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      int
+		inclusive bool
+		amount    int
+		wantNet   string
+		wantTax   string
+	}{
+		{"inclusive 20%", 2000, true, 120, "100", "20"},
+		{"inclusive keeps fractions", 2000, true, 1, "5/6", "1/6"},
+		{"inclusive 7%", 700, true, 107, "100", "7"},
+		{"exclusive 20%", 2000, false, 100, "100", "20"},
+		{"exclusive keeps fractions", 2000, false, 1, "1", "1/5"},
+		{"exclusive 23%", 2300, false, 3, "3", "69/100"},
+		{"untaxed", 0, true, 99, "99", "0"},
+		{"zero amount", 2000, true, 0, "0", "0"},
+		{"negative amount", 2000, false, -5, "-5", "-1"},
+		{"full rate inclusive", maxTaxRate, true, 10, "5", "5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Order{TaxRate: tt.rate, TaxInclusive: tt.inclusive}
+			net, tax := o.splitTax(tt.amount)
+			if net.RatString() != tt.wantNet || tax.RatString() != tt.wantTax {
+				t.Errorf("splitTax(%d) = %s, %s, want %s, %s", tt.amount, net.RatString(), tax.RatString(), tt.wantNet, tt.wantTax)
+			}
+			paid := net
+			if tt.inclusive {
+				paid = new(big.Rat).Add(net, tax)
+			}
+			if paid.Cmp(big.NewRat(int64(tt.amount), 1)) != 0 {
+				t.Errorf("splitTax(%d) does not add up to the amount: %s", tt.amount, paid.RatString())
+			}
+		})
+	}
+}
+
+func TestCalculateTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      int
+		inclusive bool
+		prices    []int
+		shipping  int
+		wantNet   int
+		wantTax   int
+		wantTotal int
+	}{
+		// Each line's VAT is 1/6; rounding per line would give 0.
+		{"inclusive rounds the sum once", 2000, true, []int{1, 1, 1, 1, 1, 1}, 0, 5, 1, 6},
+		{"inclusive with shipping", 2000, true, []int{100, 20}, 60, 150, 30, 180},
+		{"inclusive total is the prices", 2000, true, []int{7, 7, 7}, 0, 17, 4, 21},
+		// Each line's VAT is 0.2; rounding per line would give 0.
+		{"exclusive rounds the sum once", 2000, false, []int{1, 1, 1, 1, 1}, 0, 5, 1, 6},
+		{"exclusive adds the tax", 2300, false, []int{10, 3}, 0, 13, 3, 16},
+		{"untaxed", 0, true, []int{10, 20}, 5, 35, 0, 35},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Order{TaxRate: tt.rate, TaxInclusive: tt.inclusive, ShippingCost: tt.shipping}
+			for _, price := range tt.prices {
+				o.Items = append(o.Items, OrderItem{Quantity: 1, TotalPrice: Money{Amount: price}})
+			}
+			o.calculateTax()
+			if o.NetAmount != tt.wantNet || o.TaxAmount != tt.wantTax || o.TotalAmount.Amount != tt.wantTotal {
+				t.Errorf("net, tax, total = %d, %d, %d, want %d, %d, %d",
+					o.NetAmount, o.TaxAmount, o.TotalAmount.Amount, tt.wantNet, tt.wantTax, tt.wantTotal)
+			}
+			for _, item := range o.Items {
+				sum := new(big.Rat).Add(item.NetAmount.Rat(), item.TaxAmount.Rat())
+				if sum.Cmp(item.GrossAmount.Rat()) != 0 {
+					t.Errorf("item net %s + tax %s != gross %s", item.NetAmount, item.TaxAmount, item.GrossAmount)
+				}
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ type User struct {
 	FirstName  string    `json:"first_name" gorm:"column:first_name"`
 	LastName   string    `json:"last_name" gorm:"column:last_name"`
 	Username   string    `json:"username" gorm:"column:username"`
+	Language   string    `json:"language" gorm:"column:language;default:uk"`
 	IsActive   bool      `json:"is_active" gorm:"column:is_active;default:true"`
 	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
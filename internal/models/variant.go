@@ -0,0 +1,177 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9._-]{0,63}$`)
+
+// NormalizeSKU upper-cases and trims a SKU and checks its format.
+// An empty SKU is allowed.
+func NormalizeSKU(sku string) (string, error) {
+	sku = strings.ToUpper(strings.TrimSpace(sku))
+	if sku != "" && !skuPattern.MatchString(sku) {
+		return "", apperror.New(apperror.CodeInvalidInput,
+			"SKU must be up to 64 letters, digits, dots, dashes or underscores")
+	}
+	return sku, nil
+}
+
+// ValidateBarcode checks that barcode is an EAN-13 with a correct check
+// digit. An empty barcode is allowed.
+func ValidateBarcode(barcode string) error {
+	if barcode == "" {
+		return nil
+	}
+	if len(barcode) != 13 {
+		return apperror.New(apperror.CodeInvalidInput, "barcode must be a 13-digit EAN-13")
+	}
+
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := barcode[i]
+		if d < '0' || d > '9' {
+			return apperror.New(apperror.CodeInvalidInput, "barcode must contain digits only")
+		}
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+	check := byte((10-sum%10)%10) + '0'
+	if barcode[12] != check {
+		return apperror.New(apperror.CodeInvalidInput, "barcode check digit is wrong, expected "+string(check))
+	}
+	return nil
+}
+
+func NewVariant(input dto.VariantCreateDTO, now time.Time) (*Variant, error) {
+	if input.Mass <= 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "variant mass must be > 0")
+	}
+	sku, err := NormalizeSKU(input.SKU)
+	if err != nil {
+		return nil, err
+	}
+	barcode := strings.TrimSpace(input.Barcode)
+	if err := ValidateBarcode(barcode); err != nil {
+		return nil, err
+	}
+	if input.Stock < 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "variant stock cannot be negative")
+	}
+	if input.LowStockThreshold < 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "low stock threshold cannot be negative")
+	}
+	prices, err := newMoneyMap(input.Prices)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Variant{
+		ID:                uuid.New().String(),
+		Mass:              input.Mass,
+		SKU:               sku,
+		Barcode:           barcode,
+		Stock:             input.Stock,
+		LowStockThreshold: input.LowStockThreshold,
+		Prices:            prices,
+		Version:           1,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}, nil
+}
+
+// ApplyPatch changes the fields present in input. The caller checks
+// input.Version against the stored row.
+func (v *Variant) ApplyPatch(input dto.VariantPatchDTO) error {
+	if input.Mass != nil {
+		if *input.Mass <= 0 {
+			return apperror.New(apperror.CodeInvalidInput, "variant mass must be > 0")
+		}
+		v.Mass = *input.Mass
+	}
+	if input.SKU != nil {
+		sku, err := NormalizeSKU(*input.SKU)
+		if err != nil {
+			return err
+		}
+		v.SKU = sku
+	}
+	if input.Barcode != nil {
+		barcode := strings.TrimSpace(*input.Barcode)
+		if err := ValidateBarcode(barcode); err != nil {
+			return err
+		}
+		v.Barcode = barcode
+	}
+	if input.Stock != nil {
+		if *input.Stock < 0 {
+			return apperror.New(apperror.CodeInvalidInput, "variant stock cannot be negative")
+		}
+		v.Stock = *input.Stock
+	}
+	if input.LowStockThreshold != nil {
+		if *input.LowStockThreshold < 0 {
+			return apperror.New(apperror.CodeInvalidInput, "low stock threshold cannot be negative")
+		}
+		v.LowStockThreshold = *input.LowStockThreshold
+	}
+	if input.Prices != nil {
+		prices, err := newMoneyMap(input.Prices)
+		if err != nil {
+			return err
+		}
+		v.Prices = prices
+	}
+	return nil
+}
+
+func newMoneyMap(prices map[string]dto.MoneyDTO) (MoneyMap, error) {
+	if len(prices) == 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "variant must have at least one price")
+	}
+
+	m := make(MoneyMap, len(prices))
+	for region, p := range prices {
+		if p.Amount <= 0 {
+			return nil, apperror.New(apperror.CodeInvalidInput, "price amount must be > 0 for region "+region)
+		}
+		if p.Currency == "" {
+			return nil, apperror.New(apperror.CodeInvalidInput, "currency is required for region "+region)
+		}
+		m[region] = Money{
+			Amount:   p.Amount,
+			Currency: p.Currency,
+		}
+	}
+	return m, nil
+}
+
+// IsLowOnStock reports whether the variant has a threshold and its stock
+// is at or below it.
+func (v *Variant) IsLowOnStock() bool {
+	return v.LowStockThreshold > 0 && v.Stock <= v.LowStockThreshold
+}
+
+// TotalStock is the sum of the per-warehouse stock levels when they are
+// loaded, and the stored aggregate otherwise. Both are equal unless the
+// ledger is out of balance.
+func (v *Variant) TotalStock() int {
+	if v.Levels == nil {
+		return v.Stock
+	}
+	total := 0
+	for _, l := range v.Levels {
+		total += l.Quantity
+	}
+	return total
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+var (
+	warehouseCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{0,31}$`)
+	countryCodePattern   = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// Warehouse is a place stock is held in. Every lot belongs to one.
+//
+// Countries are the delivery countries the warehouse ships to; a
+// warehouse without countries ships anywhere. Orders are fulfilled from
+// one warehouse, see RankWarehouses.
+type Warehouse struct {
+	ID        string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Code      string       `gorm:"type:varchar(32);not null;uniqueIndex"`
+	Name      string       `gorm:"type:varchar(255);not null"`
+	Countries CountryCodes `gorm:"type:jsonb;not null;default:'[]'"`
+	// Priority orders warehouses that ship to the same country; lower
+	// goes first.
+	Priority int `gorm:"not null;default:0"`
+	// IsDefault marks the warehouse that receives stock when none is
+	// given. There is exactly one.
+	IsDefault bool      `gorm:"not null;default:false"`
+	IsActive  bool      `gorm:"not null;default:true"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+	UpdatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// CountryCodes is a list of ISO 3166-1 alpha-2 country codes.
+type CountryCodes []string
+
+// ShipsTo reports whether the warehouse lists country explicitly.
+func (w *Warehouse) ShipsTo(country string) bool {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	for _, c := range w.Countries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// RankWarehouses returns the active warehouses that may fulfil an order
+// to country, best first: those listing the country, then those shipping
+// anywhere, each by priority and code.
+func RankWarehouses(warehouses []*Warehouse, country string) []*Warehouse {
+	var listed, anywhere []*Warehouse
+	for _, w := range warehouses {
+		switch {
+		case !w.IsActive:
+		case w.ShipsTo(country):
+			listed = append(listed, w)
+		case len(w.Countries) == 0:
+			anywhere = append(anywhere, w)
+		}
+	}
+
+	byPriority := func(ws []*Warehouse) {
+		sort.SliceStable(ws, func(i, j int) bool {
+			if ws[i].Priority != ws[j].Priority {
+				return ws[i].Priority < ws[j].Priority
+			}
+			return ws[i].Code < ws[j].Code
+		})
+	}
+	byPriority(listed)
+	byPriority(anywhere)
+
+	return append(listed, anywhere...)
+}
+
+func NewWarehouse(input dto.WarehouseCreateDTO) (*Warehouse, error) {
+	code, err := normalizeWarehouseCode(input.Code)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, apperror.New(apperror.CodeInvalidInput, "warehouse name is required")
+	}
+	countries, err := normalizeCountryCodes(input.Countries)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Warehouse{
+		ID:        uuid.New().String(),
+		Code:      code,
+		Name:      name,
+		Countries: countries,
+		Priority:  input.Priority,
+		IsDefault: input.IsDefault,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ApplyPatch changes the fields present in input.
+func (w *Warehouse) ApplyPatch(input dto.WarehousePatchDTO) error {
+	if input.Code != nil {
+		code, err := normalizeWarehouseCode(*input.Code)
+		if err != nil {
+			return err
+		}
+		w.Code = code
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return apperror.New(apperror.CodeInvalidInput, "warehouse name is required")
+		}
+		w.Name = name
+	}
+	if input.Countries != nil {
+		countries, err := normalizeCountryCodes(input.Countries)
+		if err != nil {
+			return err
+		}
+		w.Countries = countries
+	}
+	if input.Priority != nil {
+		w.Priority = *input.Priority
+	}
+	if input.IsDefault != nil {
+		if !*input.IsDefault && w.IsDefault {
+			return apperror.New(apperror.CodeInvalidInput, "make another warehouse the default instead")
+		}
+		w.IsDefault = *input.IsDefault
+	}
+	if input.IsActive != nil {
+		w.IsActive = *input.IsActive
+	}
+	if w.IsDefault && !w.IsActive {
+		return apperror.New(apperror.CodeInvalidInput, "the default warehouse cannot be deactivated")
+	}
+	w.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func normalizeWarehouseCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !warehouseCodePattern.MatchString(code) {
+		return "", apperror.New(apperror.CodeInvalidInput,
+			"warehouse code must be up to 32 letters, digits, dashes or underscores")
+	}
+	return code, nil
+}
+
+func normalizeCountryCodes(countries []string) (CountryCodes, error) {
+	result := make(CountryCodes, 0, len(countries))
+	seen := make(map[string]bool, len(countries))
+	for _, c := range countries {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if !countryCodePattern.MatchString(c) {
+			return nil, apperror.New(apperror.CodeInvalidInput, "invalid country code "+c+", use ISO 3166-1 alpha-2")
+		}
+		if !seen[c] {
+			seen[c] = true
+			result = append(result, c)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// StockLevel is a variant's stock in one warehouse. It is read from the
+// stock_levels view, which sums the variant's lots per warehouse.
+type StockLevel struct {
+	VariantID     string
+	WarehouseID   string
+	WarehouseCode string
+	Quantity      int
+}
+
+func (StockLevel) TableName() string {
+	return "stock_levels"
+}
+
+// Value implements driver.Valuer for CountryCodes
+func (c CountryCodes) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner for CountryCodes
+func (c *CountryCodes) Scan(value any) error {
+	if value == nil {
+		*c = CountryCodes{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into CountryCodes", value)
+	}
+
+	return json.Unmarshal(bytes, c)
+}
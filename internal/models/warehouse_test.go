@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"caviar/internal/dto"
+)
+
+func TestRankWarehouses(t *testing.T) {
+	warehouses := []*Warehouse{
+		{Code: "KYIV", Countries: CountryCodes{"UA"}, Priority: 1, IsActive: true},
+		{Code: "LVIV", Countries: CountryCodes{"PL", "UA"}, Priority: 0, IsActive: true},
+		{Code: "ODESA", Countries: CountryCodes{"UA"}, Priority: 1, IsActive: true},
+		{Code: "CLOSED", Countries: CountryCodes{"UA"}, Priority: -1, IsActive: false},
+		{Code: "HUB-B", Priority: 5, IsActive: true},
+		{Code: "HUB-A", Priority: 5, IsActive: true},
+		{Code: "HUB-0", Priority: 0, IsActive: true},
+		{Code: "BERLIN", Countries: CountryCodes{"DE"}, IsActive: true},
+	}
+
+	tests := []struct {
+		name    string
+		country string
+		want    []string
+	}{
+		{"listed before anywhere, by priority then code", "UA", []string{"LVIV", "KYIV", "ODESA", "HUB-0", "HUB-A", "HUB-B"}},
+		{"country in any case", " pl ", []string{"LVIV", "HUB-0", "HUB-A", "HUB-B"}},
+		{"only warehouses shipping anywhere", "FR", []string{"HUB-0", "HUB-A", "HUB-B"}},
+		{"empty country", "", []string{"HUB-0", "HUB-A", "HUB-B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, w := range RankWarehouses(warehouses, tt.country) {
+				got = append(got, w.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("RankWarehouses(%q) = %v, want %v", tt.country, got, tt.want)
+			}
+		})
+	}
+
+	if got := RankWarehouses(nil, "UA"); len(got) != 0 {
+		t.Errorf("RankWarehouses(nil) = %v, want none", got)
+	}
+}
+
+func TestValidateDeliveryInfoCountry(t *testing.T) {
+	tests := []struct {
+		country string
+		want    string
+		wantErr bool
+	}{
+		{"UA", "UA", false},
+		{" ua ", "UA", false},
+		{"De", "DE", false},
+		{"", "", true},
+		{"  ", "", true},
+		{"Ukraine", "", true},
+		{"U", "", true},
+		{"U1", "", true},
+		{"УК", "", true},
+	}
+	for _, tt := range tests {
+		info, err := validateDeliveryInfo(dto.DeliveryInfoDTO{
+			Type:       string(DeliveryTypePostOffice),
+			Country:    tt.country,
+			City:       "Kyiv",
+			PostOffice: "12",
+		})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("validateDeliveryInfo(%q) accepted the country", tt.country)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("validateDeliveryInfo(%q): %v", tt.country, err)
+			continue
+		}
+		if info.Country != tt.want {
+			t.Errorf("validateDeliveryInfo(%q) country = %q, want %q", tt.country, info.Country, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+	"caviar/internal/types"
+	"caviar/pkg/apperror"
+
+	"go.uber.org/zap"
+)
+
+// maxImportedRates caps the rows of one exchange rate import.
+const maxImportedRates = 10000
+
+type exchangeRateService struct {
+	storage ExchangeRateStorage
+	logger  *zap.Logger
+}
+
+func NewExchangeRateService(storage ExchangeRateStorage, logger *zap.Logger) *exchangeRateService {
+	return &exchangeRateService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s *exchangeRateService) List(ctx context.Context, filter *types.ExchangeRateFilter) ([]*models.ExchangeRate, error) {
+	filter.Currency = strings.ToUpper(strings.TrimSpace(filter.Currency))
+	return s.storage.List(ctx, filter)
+}
+
+// Set stores a rate entered by staff, replacing the pair's rate of that
+// day if there is one.
+func (s *exchangeRateService) Set(ctx context.Context, input *dto.ExchangeRateCreateDTO) (*models.ExchangeRate, error) {
+	rate, err := models.NewExchangeRate(*input, models.ExchangeRateSourceManual)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Save(ctx, []*models.ExchangeRate{rate}); err != nil {
+		s.logger.Error("failed to save exchange rate", zap.String("base", rate.Base), zap.String("quote", rate.Quote), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("exchange rate set",
+		zap.String("base", rate.Base),
+		zap.String("quote", rate.Quote),
+		zap.Stringer("rate", rate.Rate),
+		zap.String("effective_on", rate.EffectiveOn.Format(models.DateLayout)))
+	return rate, nil
+}
+
+// Import stores the rates of a CSV file with a header row naming the
+// date, base, quote and rate columns. Later rows of the same pair and
+// day win, and so does the file over rates already stored. Nothing is
+// stored if a row is invalid.
+func (s *exchangeRateService) Import(ctx context.Context, r io.Reader) ([]*models.ExchangeRate, error) {
+	rows, err := readExchangeRates(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []*models.ExchangeRate
+	index := make(map[string]int, len(rows))
+	for i, row := range rows {
+		rate, err := models.NewExchangeRate(row, models.ExchangeRateSourceImport)
+		if err != nil {
+			var appErr *apperror.AppError
+			if errors.As(err, &appErr) {
+				appErr.Message += fmt.Sprintf(" (line %d)", i+2)
+			}
+			return nil, err
+		}
+
+		key := rate.Base + "/" + rate.Quote + "/" + rate.EffectiveOn.Format(models.DateLayout)
+		if j, ok := index[key]; ok {
+			rates[j] = rate
+			continue
+		}
+		index[key] = len(rates)
+		rates = append(rates, rate)
+	}
+
+	if err := s.storage.Save(ctx, rates); err != nil {
+		s.logger.Error("failed to import exchange rates", zap.Int("count", len(rates)), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("exchange rates imported", zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (s *exchangeRateService) Delete(ctx context.Context, id string) error {
+	if err := s.storage.Delete(ctx, id); err != nil {
+		s.logger.Error("failed to delete exchange rate", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("exchange rate deleted", zap.String("id", id))
+	return nil
+}
+
+// readExchangeRates parses the rows of an exchange rate CSV file. The
+// columns may come in any order; effective_on is accepted for date.
+func readExchangeRates(r io.Reader) ([]dto.ExchangeRateCreateDTO, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, apperror.New(apperror.CodeInvalidInput, "exchange rate file is empty")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInvalidInput, "failed to read exchange rate file")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "effective_on" {
+			name = "date"
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, apperror.New(apperror.CodeInvalidInput, "exchange rate file has no "+name+" column")
+		}
+	}
+
+	var rows []dto.ExchangeRateCreateDTO
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apperror.Wrap(err, apperror.CodeInvalidInput, "failed to read exchange rate file")
+		}
+		if len(rows) == maxImportedRates {
+			return nil, apperror.New(apperror.CodeInvalidInput,
+				fmt.Sprintf("exchange rate file has more than %d rows", maxImportedRates))
+		}
+
+		rate := strings.TrimSpace(record[columns["rate"]])
+		if rate == "" {
+			return nil, apperror.New(apperror.CodeInvalidInput, fmt.Sprintf("rate is required (line %d)", line))
+		}
+		date := strings.TrimSpace(record[columns["date"]])
+		if date == "" {
+			return nil, apperror.New(apperror.CodeInvalidInput, fmt.Sprintf("date is required (line %d)", line))
+		}
+		rows = append(rows, dto.ExchangeRateCreateDTO{
+			Base:        record[columns["base"]],
+			Quote:       record[columns["quote"]],
+			Rate:        json.Number(rate),
+			EffectiveOn: date,
+		})
+	}
+	if len(rows) == 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "exchange rate file has no rates")
+	}
+	return rows, nil
+}
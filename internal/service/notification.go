@@ -3,7 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
 	"caviar/internal/models"
@@ -12,31 +12,15 @@ import (
 )
 
 const (
-	batchSize                = 30
-	batchDelay               = 100 * time.Millisecond
 	dateTimeFormat          = "02.01.2006 15:04"
-	
-	msgNewOrder             = "🆕 <b>Нове замовлення!</b>\n\n"
-	msgOrderNumber          = "📋 <b>Номер:</b> %s\n"
-	msgOrderAmount          = "💰 <b>Сума:</b> %d %s\n"
-	msgOrderDate            = "📅 <b>Дата:</b> %s\n"
-	msgCustomer             = "👤 <b>Клієнт:</b> %s\n"
-	msgPhone                = "📱 <b>Телефон:</b> %s\n"
-	msgDelivery             = "🚚 <b>Доставка:</b> %s\n"
-	msgLocation             = "🌍 <b>Місто:</b> %s, %s\n"
-	msgPostOffice           = "📦 <b>Відділення:</b> %s\n"
-	msgAddress              = "🏠 <b>Адреса:</b> %s\n"
-	msgItemsCount           = "\n📦 <b>Товарів:</b> %d шт.\n"
-	msgNotes                = "\n💭 <b>Примітки:</b> %s\n"
-	
-	deliveryPostOffice      = "Нова пошта"
-	deliveryCourier         = "Кур'єрська доставка"
-	deliveryAddress         = "За адресою"
+	dayFormat               = "02.01.2006"
 )
 
 type NotificationService struct {
 	userStorage      UserStorage
 	telegramService  TelegramNotifier
+	smsProvider      SMSProvider
+	templates        *TemplateService
 	logger          *zap.Logger
 	enabledChannels []NotificationChannel
 }
@@ -56,14 +40,23 @@ type UserStorage interface {
 
 type TelegramNotifier interface {
 	SendMessage(ctx context.Context, telegramID int64, message string) error
+	SendMessageWithPriority(ctx context.Context, telegramID int64, message string, priority int) error
 	SendMessageToMultiple(ctx context.Context, telegramIDs []int64, message string) error
 }
 
+type SMSProvider interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
 type NotificationRequest struct {
 	Title     string
 	Message   string
 	Channels  []NotificationChannel
 	UserIDs   []string
+	Phones    []string
+	Event     string
+	Data      any
+	Locale    string
 	Priority  Priority
 	Metadata  map[string]any
 }
@@ -88,11 +81,15 @@ type NotificationResult struct {
 func NewNotificationService(
 	userStorage UserStorage,
 	telegramService TelegramNotifier,
+	smsProvider SMSProvider,
+	templates *TemplateService,
 	logger *zap.Logger,
 ) *NotificationService {
 	return &NotificationService{
 		userStorage:     userStorage,
 		telegramService: telegramService,
+		smsProvider:     smsProvider,
+		templates:       templates,
 		logger:          logger,
 		enabledChannels: []NotificationChannel{ChannelTelegram},
 	}
@@ -105,11 +102,10 @@ func (s *NotificationService) SetEnabledChannels(channels []NotificationChannel)
 }
 
 func (s *NotificationService) SendOrderCreatedNotification(ctx context.Context, order *models.Order) error {
-	message := s.formatOrderCreatedMessage(order)
-	
 	req := &NotificationRequest{
 		Title:    "Нове замовлення",
-		Message:  message,
+		Event:    models.TemplateEventOrderCreated,
+		Data:     newOrderTemplateData(order),
 		Channels: []NotificationChannel{ChannelTelegram},
 		Priority: PriorityNormal,
 		Metadata: map[string]any{
@@ -122,6 +118,103 @@ func (s *NotificationService) SendOrderCreatedNotification(ctx context.Context,
 	return s.SendNotification(ctx, req)
 }
 
+// SendOrderHoldExpiredNotification tells staff that an unpaid order was
+// cancelled because its stock hold expired.
+func (s *NotificationService) SendOrderHoldExpiredNotification(ctx context.Context, order *models.Order) error {
+	req := &NotificationRequest{
+		Title:    "Замовлення скасовано",
+		Event:    models.TemplateEventOrderHoldExpired,
+		Data:     newOrderTemplateData(order),
+		Channels: []NotificationChannel{ChannelTelegram},
+		Priority: PriorityNormal,
+		Metadata: map[string]any{
+			"order_id":     order.ID,
+			"order_number": order.OrderNumber,
+		},
+	}
+
+	return s.SendNotification(ctx, req)
+}
+
+// SendOrderPaidAfterCancelNotification tells staff that a payment
+// succeeded for an order that had already been cancelled, so the money
+// can be refunded.
+func (s *NotificationService) SendOrderPaidAfterCancelNotification(ctx context.Context, order *models.Order, payment *models.Payment) error {
+	req := &NotificationRequest{
+		Title:    "Оплата скасованого замовлення",
+		Event:    models.TemplateEventOrderPaidAfterCancel,
+		Data:     PaidAfterCancelTemplateData{OrderTemplateData: newOrderTemplateData(order), Payment: payment},
+		Channels: []NotificationChannel{ChannelTelegram},
+		Priority: PriorityHigh,
+		Metadata: map[string]any{
+			"order_id":     order.ID,
+			"order_number": order.OrderNumber,
+			"payment_id":   payment.ID,
+		},
+	}
+
+	return s.SendNotification(ctx, req)
+}
+
+// SendLowStockAlert tells staff which variants dropped to their low-stock
+// threshold. productNames maps product IDs to names.
+func (s *NotificationService) SendLowStockAlert(ctx context.Context, variants []*models.Variant, productNames map[string]string) error {
+	req := &NotificationRequest{
+		Title:    "Закінчується товар",
+		Event:    models.TemplateEventLowStock,
+		Data:     newLowStockTemplateData(variants, productNames),
+		Channels: []NotificationChannel{ChannelTelegram},
+		Priority: PriorityHigh,
+		Metadata: map[string]any{
+			"variants": len(variants),
+		},
+	}
+
+	return s.SendNotification(ctx, req)
+}
+
+// SendLotExpiryAlert tells staff which lots are nearing or past expiry.
+func (s *NotificationService) SendLotExpiryAlert(ctx context.Context, lots []*models.StockLot) error {
+	req := &NotificationRequest{
+		Title:    "Спливає термін придатності",
+		Event:    models.TemplateEventLotExpiring,
+		Data:     newLotExpiryTemplateData(lots, time.Now()),
+		Channels: []NotificationChannel{ChannelTelegram},
+		Priority: PriorityHigh,
+		Metadata: map[string]any{
+			"lots": len(lots),
+		},
+	}
+
+	return s.SendNotification(ctx, req)
+}
+
+func (s *NotificationService) SendOrderReceivedSMS(ctx context.Context, order *models.Order) error {
+	return s.sendCustomerSMS(ctx, "Замовлення прийнято", models.TemplateEventOrderReceived, order)
+}
+
+func (s *NotificationService) SendOrderShippedSMS(ctx context.Context, order *models.Order) error {
+	return s.sendCustomerSMS(ctx, "Замовлення відправлено", models.TemplateEventOrderShipped, order)
+}
+
+func (s *NotificationService) sendCustomerSMS(ctx context.Context, title, event string, order *models.Order) error {
+	req := &NotificationRequest{
+		Title:    title,
+		Event:    event,
+		Data:     newOrderTemplateData(order),
+		Locale:   order.CustomerInfo.Language,
+		Channels: []NotificationChannel{ChannelSMS},
+		Phones:   []string{order.CustomerInfo.Phone},
+		Priority: PriorityNormal,
+		Metadata: map[string]any{
+			"order_id":     order.ID,
+			"order_number": order.OrderNumber,
+		},
+	}
+	
+	return s.SendNotification(ctx, req)
+}
+
 func (s *NotificationService) SendNotification(ctx context.Context, req *NotificationRequest) error {
 	s.logger.Info("Sending notification",
 		zap.String("title", req.Title),
@@ -166,8 +259,7 @@ func (s *NotificationService) sendToChannel(ctx context.Context, channel Notific
 		result.Errors = []error{fmt.Errorf("email notifications not implemented")}
 		return result, fmt.Errorf("email notifications not implemented")
 	case ChannelSMS:
-		result.Errors = []error{fmt.Errorf("SMS notifications not implemented")}
-		return result, fmt.Errorf("SMS notifications not implemented")
+		return s.sendSMSNotification(ctx, req)
 	default:
 		err := fmt.Errorf("unknown notification channel: %s", channel)
 		result.Errors = []error{err}
@@ -192,49 +284,132 @@ func (s *NotificationService) sendTelegramNotification(ctx context.Context, req
 		return result, nil
 	}
 	
-	var telegramIDs []int64
+	telegramIDsByLocale := make(map[string][]int64)
 	userMap := make(map[int64]*models.User)
 	
 	for _, user := range users {
 		if user.TelegramID != nil && *user.TelegramID > 0 {
-			telegramIDs = append(telegramIDs, *user.TelegramID)
+			locale := user.Language
+			if locale == "" {
+				locale = req.Locale
+			}
+			telegramIDsByLocale[locale] = append(telegramIDsByLocale[locale], *user.TelegramID)
 			userMap[*user.TelegramID] = user
 		}
 	}
 	
-	if len(telegramIDs) == 0 {
+	if len(userMap) == 0 {
 		s.logger.Info("No users with valid Telegram ID found")
 		return result, nil
 	}
 	
 	s.logger.Info("Sending Telegram notification to users",
-		zap.Int("user_count", len(telegramIDs)))
+		zap.Int("user_count", len(userMap)))
 	
-	successCount, failureCount, errors := s.sendTelegramBatch(ctx, telegramIDs, req.Message, userMap)
+	for locale, telegramIDs := range telegramIDsByLocale {
+		message, err := s.renderMessage(ctx, req, ChannelTelegram, locale)
+		if err != nil {
+			result.Failed += len(telegramIDs)
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+		
+		successCount, failureCount, errors := s.sendTelegramBatch(ctx, telegramIDs, message, req.Priority, userMap)
+		
+		result.Success += successCount
+		result.Failed += failureCount
+		result.Errors = append(result.Errors, errors...)
+	}
+	
+	return result, nil
+}
+
+// renderMessage returns the request text for the given locale: the
+// rendered event template when the request names one, the literal
+// Message otherwise.
+func (s *NotificationService) renderMessage(ctx context.Context, req *NotificationRequest, channel NotificationChannel, locale string) (string, error) {
+	if req.Event == "" {
+		return req.Message, nil
+	}
+	
+	if s.templates == nil {
+		return "", fmt.Errorf("template service is not configured")
+	}
+	
+	if locale == "" {
+		locale = s.templates.DefaultLocale()
+	}
+	
+	message, err := s.templates.Render(ctx, req.Event, channel, locale, req.Data)
+	if err != nil {
+		s.logger.Error("Failed to render notification template",
+			zap.String("event", req.Event),
+			zap.String("channel", string(channel)),
+			zap.String("locale", locale),
+			zap.Error(err))
+		return "", err
+	}
+	
+	return message, nil
+}
+
+func (s *NotificationService) sendSMSNotification(ctx context.Context, req *NotificationRequest) (NotificationResult, error) {
+	result := NotificationResult{
+		Channel:     ChannelSMS,
+		ProcessedAt: time.Now(),
+	}
+	
+	if s.smsProvider == nil {
+		err := fmt.Errorf("SMS provider is not configured")
+		result.Errors = []error{err}
+		return result, err
+	}
+	
+	if len(req.Phones) == 0 {
+		s.logger.Info("No phone numbers provided for SMS notification")
+		return result, nil
+	}
+	
+	message, err := s.renderMessage(ctx, req, ChannelSMS, req.Locale)
+	if err != nil {
+		result.Failed = len(req.Phones)
+		result.Errors = []error{err}
+		return result, err
+	}
 	
-	result.Success = successCount
-	result.Failed = failureCount
-	result.Errors = errors
+	for _, phone := range req.Phones {
+		if err := s.smsProvider.Send(ctx, phone, message); err != nil {
+			s.logger.Warn("Failed to send SMS",
+				zap.String("phone", phone),
+				zap.Error(err))
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Errorf("phone %s: %w", phone, err))
+			continue
+		}
+		result.Success++
+	}
 	
 	return result, nil
 }
 
-func (s *NotificationService) sendTelegramBatch(ctx context.Context, telegramIDs []int64, message string, userMap map[int64]*models.User) (int, int, []error) {
+// sendTelegramBatch hands all messages to the Telegram service at once;
+// its shared limiter paces delivery and orders it by priority.
+func (s *NotificationService) sendTelegramBatch(ctx context.Context, telegramIDs []int64, message string, priority Priority, userMap map[int64]*models.User) (int, int, []error) {
 	var successCount, failureCount int
 	var errors []error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 	
-	for i := 0; i < len(telegramIDs); i += batchSize {
-		end := i + batchSize
-		if end > len(telegramIDs) {
-			end = len(telegramIDs)
-		}
-		
-		batch := telegramIDs[i:end]
-		
-		for _, telegramID := range batch {
+	for _, telegramID := range telegramIDs {
+		wg.Add(1)
+		go func(telegramID int64) {
+			defer wg.Done()
 			user := userMap[telegramID]
 			
-			err := s.telegramService.SendMessage(ctx, telegramID, message)
+			err := s.telegramService.SendMessageWithPriority(ctx, telegramID, message, int(priority))
+			
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
 				s.logger.Warn("Failed to send Telegram message to user",
 					zap.Int64("telegram_id", telegramID),
@@ -248,12 +423,9 @@ func (s *NotificationService) sendTelegramBatch(ctx context.Context, telegramIDs
 					zap.Int64("telegram_id", telegramID),
 					zap.String("user_id", user.ID.String()))
 			}
-		}
-		
-		if i+batchSize < len(telegramIDs) {
-			time.Sleep(batchDelay)
-		}
+		}(telegramID)
 	}
+	wg.Wait()
 	
 	return successCount, failureCount, errors
 }
@@ -278,60 +450,6 @@ func (s *NotificationService) getTargetUsers(ctx context.Context, userIDs []stri
 	return users, nil
 }
 
-func (s *NotificationService) formatOrderCreatedMessage(order *models.Order) string {
-	var sb strings.Builder
-	
-	sb.WriteString(msgNewOrder)
-	sb.WriteString(fmt.Sprintf(msgOrderNumber, order.OrderNumber))
-	sb.WriteString(fmt.Sprintf(msgOrderAmount, order.TotalAmount.Amount, order.TotalAmount.Currency))
-	sb.WriteString(fmt.Sprintf(msgOrderDate, order.CreatedAt.Format(dateTimeFormat)))
-	
-	if order.CustomerInfo.FullName != "" {
-		sb.WriteString(fmt.Sprintf(msgCustomer, order.CustomerInfo.FullName))
-	} else if order.CustomerInfo.FirstName != "" {
-		name := order.CustomerInfo.FirstName
-		if order.CustomerInfo.LastName != "" {
-			name += " " + order.CustomerInfo.LastName
-		}
-		sb.WriteString(fmt.Sprintf(msgCustomer, name))
-	}
-	
-	if order.CustomerInfo.Phone != "" {
-		sb.WriteString(fmt.Sprintf(msgPhone, order.CustomerInfo.Phone))
-	}
-	
-	sb.WriteString(fmt.Sprintf(msgDelivery, s.getDeliveryTypeUkrainian(order.DeliveryInfo.Type)))
-	sb.WriteString(fmt.Sprintf(msgLocation, order.DeliveryInfo.City, order.DeliveryInfo.Country))
-	
-	if order.DeliveryInfo.PostOffice != "" {
-		sb.WriteString(fmt.Sprintf(msgPostOffice, order.DeliveryInfo.PostOffice))
-	}
-	if order.DeliveryInfo.Address != "" {
-		sb.WriteString(fmt.Sprintf(msgAddress, order.DeliveryInfo.Address))
-	}
-	
-	sb.WriteString(fmt.Sprintf(msgItemsCount, len(order.Items)))
-	
-	if order.Notes != "" {
-		sb.WriteString(fmt.Sprintf(msgNotes, order.Notes))
-	}
-	
-	return sb.String()
-}
-
-func (s *NotificationService) getDeliveryTypeUkrainian(deliveryType models.DeliveryType) string {
-	switch deliveryType {
-	case models.DeliveryTypePostOffice:
-		return deliveryPostOffice
-	case models.DeliveryTypeCourier:
-		return deliveryCourier
-	case models.DeliveryTypeAddress:
-		return deliveryAddress
-	default:
-		return string(deliveryType)
-	}
-}
-
 func (s *NotificationService) isChannelEnabled(channel NotificationChannel) bool {
 	for _, enabled := range s.enabledChannels {
 		if enabled == channel {
@@ -3,29 +3,54 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"caviar/internal/dto"
 	"caviar/internal/models"
 	"caviar/internal/types"
 	"caviar/pkg/apperror"
+	"caviar/pkg/sms"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
-
-
+// shippedSMSTimeout bounds sending the shipped SMS, which outlives the
+// request that shipped the order.
+const shippedSMSTimeout = 30 * time.Second
 
 type OrderService struct {
 	orderStorage        OrderStorage
 	productStorage      ProductStorage
+	warehouseStorage    WarehouseStorage
+	promoStorage        PromoStorage
+	shipping            *shippingQuoter
+	taxStorage          TaxStorage
+	exchangeRates       ExchangeRateStorage
 	notificationService *NotificationService
+	holds               OrderHoldConfig
+	codFees             models.CODFees
+	reportingCurrency   string
 	logger              *zap.Logger
 }
 
-func NewOrderService(orderStorage OrderStorage, productStorage ProductStorage, notificationService *NotificationService, logger *zap.Logger) *OrderService {
+func NewOrderService(orderStorage OrderStorage, productStorage ProductStorage, warehouseStorage WarehouseStorage, promoStorage PromoStorage, shippingStorage ShippingStorage, taxStorage TaxStorage, exchangeRates ExchangeRateStorage, notificationService *NotificationService, holds OrderHoldConfig, codFees models.CODFees, packaging models.Packaging, reportingCurrency string, logger *zap.Logger) *OrderService {
 	return &OrderService{
-		orderStorage:        orderStorage,
-		productStorage:      productStorage,
+		orderStorage:     orderStorage,
+		productStorage:   productStorage,
+		warehouseStorage: warehouseStorage,
+		promoStorage:     promoStorage,
+		shipping: &shippingQuoter{
+			shippingStorage: shippingStorage,
+			productStorage:  productStorage,
+			packaging:       packaging,
+		},
+		taxStorage:          taxStorage,
+		exchangeRates:       exchangeRates,
+		holds:               holds,
+		codFees:             codFees,
+		reportingCurrency:   reportingCurrency,
 		notificationService: notificationService,
 		logger:              logger,
 	}
@@ -43,14 +68,38 @@ func (s *OrderService) Create(ctx context.Context, input *dto.OrderCreateDTO) (*
 		s.logger.Error("Failed to create order model", zap.Error(err))
 		return nil, err
 	}
+	var redemption *models.PromoRedemption
+	if input.PromoCode != "" {
+		if redemption, err = s.applyPromoCode(ctx, order, input.PromoCode); err != nil {
+			return nil, err
+		}
+	}
+	// The free shipping threshold applies to the discounted amount, so
+	// shipping is quoted after the promo code.
+	quote, err := s.shipping.Quote(ctx, order.DeliveryInfo.Type, order.DeliveryInfo.Country, input.Items, order.TotalAmount)
+	if err != nil {
+		s.logger.Warn("Failed to quote shipping", zap.Error(err))
+		return nil, err
+	}
+	order.ApplyShipping(quote)
+	if err := order.ApplyCODFee(s.codFees); err != nil {
+		return nil, err
+	}
+	if err := s.applyTax(ctx, order); err != nil {
+		return nil, err
+	}
 
-	if err := s.reserveStock(ctx, order.Items); err != nil {
+	if err := s.reserveStock(ctx, order); err != nil {
 		s.logger.Error("Failed to reserve stock", zap.Error(err))
 		return nil, err
 	}
+	if hold := s.holds.For(order); hold > 0 {
+		expiresAt := time.Now().UTC().Add(hold)
+		order.HoldExpiresAt = &expiresAt
+	}
 
-	if err := s.orderStorage.Create(ctx, order); err != nil {
-		if rollbackErr := s.rollbackStockReservation(ctx, order.Items); rollbackErr != nil {
+	if err := s.orderStorage.Create(ctx, order, redemption); err != nil {
+		if rollbackErr := s.rollbackStockReservation(ctx, order, order.Items, "order creation failed"); rollbackErr != nil {
 			s.logger.Error("Failed to rollback stock reservation", zap.Error(rollbackErr))
 		}
 		s.logger.Error("Failed to create order in storage", zap.Error(err))
@@ -63,11 +112,25 @@ func (s *OrderService) Create(ctx context.Context, input *dto.OrderCreateDTO) (*
 	if s.notificationService != nil {
 		go func() {
 			notifyCtx := context.Background()
-			if err := s.notificationService.SendOrderCreatedNotification(notifyCtx, order); err != nil {
+			
+			// Reload to get product names and variants for the message
+			notifyOrder := order
+			if loaded, err := s.orderStorage.GetByID(notifyCtx, order.ID); err == nil {
+				notifyOrder = loaded
+			} else {
+				s.logger.Warn("Failed to reload order for notification", zap.String("order_id", order.ID), zap.Error(err))
+			}
+			
+			if err := s.notificationService.SendOrderCreatedNotification(notifyCtx, notifyOrder); err != nil {
 				s.logger.Error("Failed to send order notification", 
 					zap.String("order_id", order.ID),
 					zap.Error(err))
 			}
+			if err := s.notificationService.SendOrderReceivedSMS(notifyCtx, notifyOrder); err != nil {
+				s.logger.Error("Failed to send order received SMS",
+					zap.String("order_id", order.ID),
+					zap.Error(err))
+			}
 		}()
 	}
 	
@@ -86,7 +149,8 @@ func (s *OrderService) List(ctx context.Context, filter *types.OrderFilter) ([]*
 	return s.orderStorage.List(ctx, filter)
 }
 
-func (s *OrderService) UpdateStatus(ctx context.Context, id string, status models.OrderStatus) error {
+func (s *OrderService) UpdateStatus(ctx context.Context, id string, input *dto.OrderStatusUpdateDTO) error {
+	status := models.OrderStatus(input.Status)
 	s.logger.Info("Updating order status", zap.String("order_id", id), zap.String("status", string(status)))
 
 	order, err := s.orderStorage.GetByID(ctx, id)
@@ -94,20 +158,102 @@ func (s *OrderService) UpdateStatus(ctx context.Context, id string, status model
 		return err
 	}
 
-	if status == models.OrderStatusCancelled && order.Status != models.OrderStatusCancelled {
-		if err := s.rollbackStockReservation(ctx, order.Items); err != nil {
-			s.logger.Error("Failed to rollback stock on order cancellation", zap.Error(err))
-		}
+	if err := order.CheckStatusChange(status); err != nil {
+		return err
 	}
 
-	if err := s.orderStorage.UpdateStatus(ctx, id, status); err != nil {
+	if err := s.orderStorage.UpdateStatus(ctx, id, order.Status, status, input.TrackingNumber); err != nil {
 		return err
 	}
+	if input.TrackingNumber != "" {
+		order.TrackingNumber = input.TrackingNumber
+	}
+
+	// Only the caller whose update cancelled the order releases its stock,
+	// so a cancellation racing the hold sweeper cannot release it twice.
+	if status == models.OrderStatusCancelled && order.Status != models.OrderStatusCancelled {
+		s.releaseCancelledOrder(ctx, id, "order cancelled")
+	}
 
 	s.logger.Info("Order status updated successfully", zap.String("order_id", id), zap.String("new_status", string(status)))
+
+	if status == models.OrderStatusShipped && order.Status != models.OrderStatusShipped && s.notificationService != nil {
+		// The SMS goes out after the response, so it keeps the request's
+		// values but not its cancellation.
+		smsCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shippedSMSTimeout)
+		go func() {
+			defer cancel()
+			if err := s.notificationService.SendOrderShippedSMS(smsCtx, order); err != nil {
+				s.logger.Error("Failed to send order shipped SMS",
+					zap.String("order_id", order.ID),
+					zap.Error(err))
+			}
+		}()
+	}
+
 	return nil
 }
 
+// MarkPaid records a payment received outside the payment provider,
+// such as a bank transfer or cash collected on delivery, and confirms
+// the order if it is still pending.
+func (s *OrderService) MarkPaid(ctx context.Context, id string) (*models.Order, error) {
+	order, err := s.orderStorage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == models.OrderStatusCancelled {
+		return nil, apperror.New(apperror.CodeInvalidInput, "cancelled orders cannot be marked paid")
+	}
+	if order.PaidAt != nil {
+		return nil, apperror.New(apperror.CodeConflict, "order is already paid")
+	}
+
+	if _, err := s.orderStorage.MarkPaid(ctx, id, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Order marked paid",
+		zap.String("order_id", id),
+		zap.String("payment_method", string(order.PaymentMethod)))
+	return s.orderStorage.GetByID(ctx, id)
+}
+
+// Refund takes items out of an order: a cancellation before it ships or
+// a return after. Cancelled items and returns marked for restocking go
+// back to the lots they were taken from; damaged returns do not.
+func (s *OrderService) Refund(ctx context.Context, id string, input *dto.OrderRefundCreateDTO) (*models.Refund, error) {
+	var order models.Order
+	refund, err := s.orderStorage.Refund(ctx, id, func(o *models.Order) (*models.Refund, error) {
+		refund, err := o.RemoveItems(ctx, *input, time.Now().UTC())
+		order = *o
+		return refund, err
+	})
+	if err != nil {
+		s.logger.Error("Failed to refund order", zap.String("order_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	reason := models.StockReasonOrderCancel
+	if refund.Kind == models.RefundKindReturn {
+		reason = models.StockReasonOrderReturn
+	}
+	for _, item := range refund.Items {
+		if item.Restock {
+			s.returnToStock(ctx, &order, item.VariantID, item.Lots, item.Quantity, reason, string(refund.Kind)+": "+refund.Reason)
+		}
+	}
+
+	s.logger.Info("Order refunded",
+		zap.String("order_id", id),
+		zap.String("refund_id", refund.ID),
+		zap.String("kind", string(refund.Kind)),
+		zap.Int("amount", refund.Amount),
+		zap.String("actor", refund.Actor),
+		zap.String("status", string(order.Status)))
+	return refund, nil
+}
+
 func (s *OrderService) Delete(ctx context.Context, id string) error {
 	s.logger.Info("Deleting order", zap.String("order_id", id))
 
@@ -117,7 +263,7 @@ func (s *OrderService) Delete(ctx context.Context, id string) error {
 	}
 
 	if order.Status != models.OrderStatusCancelled {
-		if err := s.rollbackStockReservation(ctx, order.Items); err != nil {
+		if err := s.rollbackStockReservation(ctx, order, order.Items, "order deleted"); err != nil {
 			s.logger.Error("Failed to rollback stock on order deletion", zap.Error(err))
 		}
 	}
@@ -130,8 +276,41 @@ func (s *OrderService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// GetStatistics returns the order statistics, with the revenue of every
+// currency converted to the reporting currency at the rate of the day
+// each order was placed.
 func (s *OrderService) GetStatistics(ctx context.Context) (map[string]any, error) {
-	return s.orderStorage.GetOrderStatistics(ctx)
+	stats, err := s.orderStorage.GetOrderStatistics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.orderStorage.DailyRevenue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.exchangeRates.List(ctx, &types.ExchangeRateFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	report := models.ReportRevenue(days, rates, s.reportingCurrency)
+	if len(report.Unconverted) > 0 {
+		s.logger.Warn("Revenue left out of the reporting currency total",
+			zap.String("currency", report.Currency), zap.Strings("unconverted", report.Unconverted))
+	}
+	unconverted := report.Unconverted
+	if unconverted == nil {
+		unconverted = []string{}
+	}
+	stats["reporting_revenue"] = map[string]any{
+		"currency":    report.Currency,
+		"net":         report.Net,
+		"tax":         report.Tax,
+		"gross":       report.Gross,
+		"unconverted": unconverted,
+	}
+	return stats, nil
 }
 
 func (s *OrderService) validateOrderItems(ctx context.Context, items []dto.OrderItemDTO) error {
@@ -164,23 +343,185 @@ func (s *OrderService) validateOrderItems(ctx context.Context, items []dto.Order
 	return nil
 }
 
-func (s *OrderService) reserveStock(ctx context.Context, items []models.OrderItem) error {
-	for _, item := range items {
-		if err := s.productStorage.UpdateVariantStock(ctx, item.VariantID, -item.Quantity); err != nil {
+// applyPromoCode applies the promo code to the order and returns the
+// redemption to store with it, which takes one of the code's uses.
+// Limits per customer count orders by normalized phone.
+func (s *OrderService) applyPromoCode(ctx context.Context, order *models.Order, code string) (*models.PromoRedemption, error) {
+	code = models.NormalizePromoCode(code)
+	promo, err := s.promoStorage.GetByCode(ctx, code)
+	if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.CodeNotFound {
+		return nil, apperror.New(apperror.CodeInvalidInput, "promo code "+code+" is not valid")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := promo.Apply(order, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	phone, err := sms.NormalizePhone(order.CustomerInfo.Phone)
+	if err != nil {
+		phone = order.CustomerInfo.Phone
+	}
+	return &models.PromoRedemption{
+		ID:            uuid.New().String(),
+		PromoCodeID:   promo.ID,
+		OrderID:       order.ID,
+		CustomerPhone: phone,
+		CreatedAt:     time.Now().UTC(),
+	}, nil
+}
+
+// applyTax splits the order into net and tax amounts at the rate of its
+// delivery country. Countries without a rate are not taxed.
+func (s *OrderService) applyTax(ctx context.Context, order *models.Order) error {
+	country := strings.ToUpper(strings.TrimSpace(order.DeliveryInfo.Country))
+	rate, err := s.taxStorage.GetByCountry(ctx, country)
+	if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.CodeNotFound {
+		rate, err = nil, nil
+	}
+	if err != nil {
+		s.logger.Error("Failed to get tax rate", zap.String("country", country), zap.Error(err))
+		return err
+	}
+
+	order.ApplyTax(rate)
+	return nil
+}
+
+// chooseWarehouse picks the warehouse the order is shipped from: the
+// best ranked one for the delivery country that has unexpired stock for
+// every item.
+func (s *OrderService) chooseWarehouse(ctx context.Context, order *models.Order) (*models.Warehouse, error) {
+	warehouses, err := s.warehouseStorage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidates := models.RankWarehouses(warehouses, order.DeliveryInfo.Country)
+	if len(candidates) == 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, fmt.Sprintf("no warehouse ships to %s", order.DeliveryInfo.Country))
+	}
+
+	needed := make(map[string]int)
+	variantIDs := make([]string, 0, len(order.Items))
+	for _, item := range order.Items {
+		if _, ok := needed[item.VariantID]; !ok {
+			variantIDs = append(variantIDs, item.VariantID)
+		}
+		needed[item.VariantID] += item.Quantity
+	}
+
+	for _, w := range candidates {
+		available, err := s.productStorage.AvailableStock(ctx, w.ID, variantIDs)
+		if err != nil {
+			return nil, err
+		}
+		if coversOrder(available, needed) {
+			return w, nil
+		}
+	}
+	return nil, apperror.New(apperror.CodeInvalidInput, "insufficient stock: no single warehouse can fulfil the order")
+}
+
+func coversOrder(available, needed map[string]int) bool {
+	for variantID, quantity := range needed {
+		if available[variantID] < quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// reserveStock takes stock for every item from the warehouse chosen for
+// the order and records on each item the lots it was allocated from. If
+// one item cannot be reserved, the items reserved so far are released
+// again.
+func (s *OrderService) reserveStock(ctx context.Context, order *models.Order) error {
+	warehouse, err := s.chooseWarehouse(ctx, order)
+	if err != nil {
+		return err
+	}
+	order.WarehouseID = &warehouse.ID
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		m := models.NewStockMovement(ctx, item.VariantID, -item.Quantity, models.StockReasonOrderReserve, order.ID, order.OrderNumber)
+		m.WarehouseID = order.WarehouseID
+		movements, err := s.productStorage.MoveStock(ctx, m)
+		if err != nil {
+			if rollbackErr := s.rollbackStockReservation(ctx, order, order.Items[:i], "reservation failed"); rollbackErr != nil {
+				s.logger.Error("Failed to rollback partial stock reservation", zap.Error(rollbackErr))
+			}
 			return err
 		}
+
+		item.Lots = s.toItemLots(ctx, item.VariantID, movements)
 	}
 	return nil
 }
 
-func (s *OrderService) rollbackStockReservation(ctx context.Context, items []models.OrderItem) error {
+// toItemLots describes the lots reservation movements were taken from.
+// Lot numbers and expiry dates are informational, so failing to load
+// them does not fail the order.
+func (s *OrderService) toItemLots(ctx context.Context, variantID string, movements []*models.StockMovement) models.OrderItemLots {
+	lotsByID := make(map[string]*models.StockLot)
+	if lots, err := s.productStorage.ListLots(ctx, variantID, true); err == nil {
+		for _, lot := range lots {
+			lotsByID[lot.ID] = lot
+		}
+	} else {
+		s.logger.Warn("Failed to load lots of reservation", zap.String("variant_id", variantID), zap.Error(err))
+	}
+
+	result := make(models.OrderItemLots, 0, len(movements))
+	for _, movement := range movements {
+		allocation := models.OrderItemLot{LotID: *movement.LotID, Quantity: -movement.Delta}
+		if lot, ok := lotsByID[allocation.LotID]; ok {
+			allocation.LotNumber = lot.LotNumber
+			allocation.ExpiresAt = lot.ExpiresAt
+		}
+		result = append(result, allocation)
+	}
+	return result
+}
+
+// rollbackStockReservation returns the stock of items to the lots they
+// were allocated from. Items reserved before lots were tracked go back
+// to the unlabelled lot of the order's warehouse.
+func (s *OrderService) rollbackStockReservation(ctx context.Context, order *models.Order, items []models.OrderItem, note string) error {
 	for _, item := range items {
-		if err := s.productStorage.UpdateVariantStock(ctx, item.VariantID, item.Quantity); err != nil {
-			s.logger.Error("Failed to rollback stock for item", 
-				zap.String("variant_id", item.VariantID), 
-				zap.Int("quantity", item.Quantity), 
-				zap.Error(err))
+		// Items refunded in full have nothing left to return.
+		if item.Quantity == 0 {
+			continue
 		}
+		s.returnToStock(ctx, order, item.VariantID, item.Lots, item.Quantity, models.StockReasonOrderCancel, note)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// returnToStock puts quantity of a variant back into the lots it was
+// allocated from, or into the unlabelled lot of the order's warehouse
+// if lots were not tracked when it was reserved. Failures are logged.
+func (s *OrderService) returnToStock(ctx context.Context, order *models.Order, variantID string, lots models.OrderItemLots, quantity int, reason models.StockMovementReason, note string) {
+	movements := make([]*models.StockMovement, 0, len(lots))
+	for _, lot := range lots {
+		m := models.NewStockMovement(ctx, variantID, lot.Quantity, reason, order.ID, order.OrderNumber+": "+note)
+		m.LotID = &lot.LotID
+		movements = append(movements, m)
+	}
+	if len(lots) == 0 {
+		m := models.NewStockMovement(ctx, variantID, quantity, reason, order.ID, order.OrderNumber+": "+note)
+		m.WarehouseID = order.WarehouseID
+		movements = append(movements, m)
+	}
+
+	for _, m := range movements {
+		if _, err := s.productStorage.MoveStock(ctx, m); err != nil {
+			s.logger.Error("Failed to return stock for item",
+				zap.String("variant_id", variantID),
+				zap.Int("quantity", m.Delta),
+				zap.Error(err))
+		}
+	}
+}
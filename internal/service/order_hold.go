@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"caviar/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// expiredHoldsBatch caps how many orders one sweep cancels, so a backlog
+// is worked off over several sweeps.
+const expiredHoldsBatch = 100
+
+// OrderHoldConfig sets how long stock stays reserved for an unpaid
+// pending order: card orders by delivery type, bank transfer orders
+// while the invoice is paid. Cash on delivery orders are not paid
+// upfront and are held until confirmed or cancelled, as is any order
+// whose hold is zero.
+type OrderHoldConfig struct {
+	PostOffice   time.Duration
+	Courier      time.Duration
+	Address      time.Duration
+	BankTransfer time.Duration
+}
+
+// For returns the hold for the order.
+func (c OrderHoldConfig) For(order *models.Order) time.Duration {
+	switch order.PaymentMethod {
+	case models.PaymentMethodCOD:
+		return 0
+	case models.PaymentMethodBankTransfer:
+		return c.BankTransfer
+	}
+
+	switch order.DeliveryInfo.Type {
+	case models.DeliveryTypePostOffice:
+		return c.PostOffice
+	case models.DeliveryTypeCourier:
+		return c.Courier
+	case models.DeliveryTypeAddress:
+		return c.Address
+	}
+	return 0
+}
+
+// RunHoldSweeper cancels pending orders whose hold expired, checking
+// every interval until ctx is done. An interval of zero or less disables
+// it, and holds are then only released by hand.
+func (s *OrderService) RunHoldSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.logger.Warn("hold sweeper disabled", zap.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.releaseExpiredHolds(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// releaseCancelledOrder returns the stock of an order the caller has just
+// cancelled. The order is read again, as its items may have changed
+// since the caller loaded it; a cancelled order's items cannot change
+// any more. It returns the order read, or nil when that failed.
+func (s *OrderService) releaseCancelledOrder(ctx context.Context, id, reason string) *models.Order {
+	order, err := s.orderStorage.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to reload cancelled order, its stock was not released",
+			zap.String("order_id", id), zap.Error(err))
+		return nil
+	}
+	if err := s.rollbackStockReservation(ctx, order, order.Items, reason); err != nil {
+		s.logger.Error("Failed to rollback stock of cancelled order", zap.String("order_id", id), zap.Error(err))
+	}
+	return order
+}
+
+// releaseExpiredHolds cancels every pending order past its hold, returns
+// its stock through the usual rollback and tells staff.
+func (s *OrderService) releaseExpiredHolds(ctx context.Context) {
+	now := time.Now().UTC()
+
+	orders, err := s.orderStorage.ListExpiredHolds(ctx, now, expiredHoldsBatch)
+	if err != nil {
+		s.logger.Error("Failed to list expired order holds", zap.Error(err))
+		return
+	}
+
+	for _, order := range orders {
+		cancelled, err := s.orderStorage.CancelExpiredHold(ctx, order.ID, now)
+		if err != nil {
+			s.logger.Error("Failed to cancel order with expired hold", zap.String("order_id", order.ID), zap.Error(err))
+			continue
+		}
+		// Confirmed or cancelled since it was listed.
+		if !cancelled {
+			continue
+		}
+
+		expiredAt := *order.HoldExpiresAt
+		if released := s.releaseCancelledOrder(ctx, order.ID, "hold expired"); released != nil {
+			order = released
+		}
+		order.Status = models.OrderStatusCancelled
+
+		s.logger.Info("Order cancelled, hold expired",
+			zap.String("order_id", order.ID),
+			zap.String("order_number", order.OrderNumber),
+			zap.Time("hold_expires_at", expiredAt))
+
+		if s.notificationService != nil {
+			if err := s.notificationService.SendOrderHoldExpiredNotification(ctx, order); err != nil {
+				s.logger.Error("Failed to send hold expired notification", zap.String("order_id", order.ID), zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+	"caviar/pkg/payment"
+
+	"go.uber.org/zap"
+)
+
+type PaymentStorage interface {
+	Create(ctx context.Context, p *models.Payment) error
+	GetByID(ctx context.Context, id string) (*models.Payment, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Payment, error)
+	Update(ctx context.Context, id string, fn func(p *models.Payment) error) (*models.Payment, error)
+}
+
+// PaymentConfig holds the URLs handed to the payment provider.
+type PaymentConfig struct {
+	// ResultURL is where customers return after paying.
+	ResultURL string
+	// CallbackBaseURL is the public base URL of this API.
+	CallbackBaseURL string
+	// CheckoutHold is how long a held order is kept at least once its
+	// checkout starts.
+	CheckoutHold time.Duration
+}
+
+type paymentService struct {
+	payments      PaymentStorage
+	orders        OrderStorage
+	provider      payment.Provider
+	config        PaymentConfig
+	notifications *NotificationService
+	logger        *zap.Logger
+}
+
+func NewPaymentService(payments PaymentStorage, orders OrderStorage, provider payment.Provider, config PaymentConfig, notifications *NotificationService, logger *zap.Logger) *paymentService {
+	return &paymentService{
+		payments:      payments,
+		orders:        orders,
+		provider:      provider,
+		config:        config,
+		notifications: notifications,
+		logger:        logger,
+	}
+}
+
+// StartCheckout creates a payment of the order's total and a hosted
+// checkout page for it. The order's hold is extended so the sweeper does
+// not cancel it while the customer is paying.
+func (s *paymentService) StartCheckout(ctx context.Context, orderID string) (*models.Payment, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := models.NewPayment(order, s.provider.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	held, err := s.orders.ExtendHold(ctx, orderID, now.Add(s.config.CheckoutHold), now)
+	if err != nil {
+		return nil, err
+	}
+	// Cancelled or expired since it was read.
+	if !held {
+		return nil, apperror.New(apperror.CodeInvalidInput, "order hold has expired")
+	}
+
+	checkout, err := s.provider.CreateCheckout(ctx, payment.CheckoutRequest{
+		PaymentID:   p.ID,
+		Amount:      p.Amount,
+		Currency:    p.Currency,
+		Description: "Order " + order.OrderNumber,
+		Language:    order.CustomerInfo.Language,
+		ResultURL:   s.config.ResultURL,
+		CallbackURL: s.callbackURL(),
+	})
+	if err != nil {
+		s.logger.Error("failed to create checkout", zap.String("order_id", orderID), zap.Error(err))
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to start payment")
+	}
+	p.CheckoutURL = checkout.URL
+	p.ExternalID = checkout.ExternalID
+
+	if err := s.payments.Create(ctx, p); err != nil {
+		s.logger.Error("failed to create payment", zap.String("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("payment started",
+		zap.String("order_id", orderID),
+		zap.String("payment_id", p.ID),
+		zap.String("provider", p.Provider))
+	return p, nil
+}
+
+func (s *paymentService) ListByOrder(ctx context.Context, orderID string) ([]*models.Payment, error) {
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.payments.ListByOrder(ctx, orderID)
+}
+
+// HandleCallback verifies and applies a status callback of the named
+// provider. A succeeded payment marks the order paid and confirms it.
+// Callbacks may be repeated, so applying one twice changes nothing.
+func (s *paymentService) HandleCallback(ctx context.Context, provider string, body []byte, header http.Header) error {
+	if provider != s.provider.Name() {
+		return apperror.New(apperror.CodeNotFound, "unknown payment provider "+provider)
+	}
+
+	cb, err := s.provider.ParseCallback(body, header)
+	switch {
+	case errors.Is(err, payment.ErrInvalidSignature):
+		s.logger.Warn("payment callback with invalid signature", zap.String("provider", provider))
+		return apperror.Wrap(err, apperror.CodeUnauthorized, "invalid callback signature")
+	case err != nil:
+		return apperror.Wrap(err, apperror.CodeInvalidInput, "malformed payment callback")
+	}
+
+	now := time.Now().UTC()
+	var changed bool
+	p, err := s.payments.Update(ctx, cb.PaymentID, func(p *models.Payment) error {
+		if p.Provider != provider {
+			return apperror.New(apperror.CodeInvalidInput, "payment belongs to another provider")
+		}
+		changed, err = p.ApplyStatus(models.PaymentStatus(cb.Status), cb.ExternalID, cb.Amount, cb.Currency, cb.Reason, now)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to apply payment callback", zap.String("payment_id", cb.PaymentID), zap.Error(err))
+		return err
+	}
+	if changed {
+		s.logger.Info("payment status changed",
+			zap.String("payment_id", p.ID),
+			zap.String("order_id", p.OrderID),
+			zap.String("status", string(p.Status)))
+	}
+
+	if p.Status != models.PaymentStatusSucceeded {
+		return nil
+	}
+
+	// Marking the order is idempotent, so a callback retried after a
+	// failure here completes it.
+	confirmed, err := s.orders.MarkPaid(ctx, p.OrderID, *p.PaidAt)
+	if err != nil {
+		s.logger.Error("failed to mark order paid", zap.String("order_id", p.OrderID), zap.Error(err))
+		return err
+	}
+	if confirmed {
+		s.logger.Info("order paid and confirmed", zap.String("order_id", p.OrderID))
+		return nil
+	}
+
+	order, err := s.orders.GetByID(ctx, p.OrderID)
+	if err != nil {
+		s.logger.Error("failed to get paid order", zap.String("order_id", p.OrderID), zap.Error(err))
+		return nil
+	}
+	if order.Status != models.OrderStatusCancelled {
+		return nil
+	}
+
+	// The money has to go back by hand; staff are told once, when the
+	// payment first succeeds, not on every repeated callback.
+	s.logger.Warn("payment received for a cancelled order",
+		zap.String("order_id", order.ID),
+		zap.String("order_number", order.OrderNumber),
+		zap.String("payment_id", p.ID))
+	if changed && s.notifications != nil {
+		if err := s.notifications.SendOrderPaidAfterCancelNotification(ctx, order, p); err != nil {
+			s.logger.Error("failed to send paid after cancel notification", zap.String("order_id", order.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *paymentService) callbackURL() string {
+	return strings.TrimRight(s.config.CallbackBaseURL, "/") + "/api/v1/payments/callback/" + s.provider.Name()
+}
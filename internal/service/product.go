@@ -4,26 +4,36 @@ import (
 	"caviar/internal/dto"
 	"caviar/internal/models"
 	"caviar/internal/types"
+	"caviar/pkg/apperror"
 	"context"
+	"fmt"
 
-	"github.com/minio/minio-go/v7"
 	"go.uber.org/zap"
 )
 
 type productService struct {
 	productStorage ProductStorage
-	minioClient *minio.Client
+	objects ObjectStorage
+	images ImageConfig
+	exchangeRates ExchangeRateStorage
+	pricing PricingConfig
 	logger *zap.Logger
 }
 
 func NewProductService(
 	productStorage ProductStorage,
-	minioClient *minio.Client,
+	objects ObjectStorage,
+	images ImageConfig,
+	exchangeRates ExchangeRateStorage,
+	pricing PricingConfig,
 	logger *zap.Logger,	
 ) *productService {
 	return &productService{
 		productStorage: productStorage,
-		minioClient: minioClient,
+		objects: objects,
+		images: images,
+		exchangeRates: exchangeRates,
+		pricing: pricing,
 		logger: logger,
 	}
 }
@@ -44,6 +54,36 @@ func (s *productService) Create(ctx context.Context, input *dto.ProductCreateDTO
 	return nil
 }
 
+// GetByID returns a product. Inactive products are reported as not found
+// to anonymous callers, the same way List hides them.
+func (s *productService) GetByID(ctx context.Context, isAuthenticated bool, id string) (*models.Product, error) {
+	product, err := s.productStorage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAuthenticated && !product.IsActive {
+		return nil, apperror.New(apperror.CodeNotFound, fmt.Sprintf("product %s not found", id))
+	}
+
+	s.attachProductImageURLs(ctx, product)
+	s.attachDerivedPrices(ctx, product)
+	return product, nil
+}
+
+func (s *productService) GetBySlug(ctx context.Context, isAuthenticated bool, slug string) (*models.Product, error) {
+	product, err := s.productStorage.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !isAuthenticated && !product.IsActive {
+		return nil, apperror.New(apperror.CodeNotFound, fmt.Sprintf("product with slug %q not found", slug))
+	}
+
+	s.attachProductImageURLs(ctx, product)
+	s.attachDerivedPrices(ctx, product)
+	return product, nil
+}
+
 func (s *productService) List(
 	ctx context.Context, 
 	isAuthenticated bool,
@@ -52,9 +92,17 @@ func (s *productService) List(
 	// Only authenticated users can see all products (including inactive ones)
 	if !isAuthenticated {
 		filter.ShowAll = false
+		filter.Deleted = false
+	}
+
+	products, err := s.productStorage.List(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.productStorage.List(ctx, filter)
+	s.attachProductImageURLs(ctx, products...)
+	s.attachDerivedPrices(ctx, products...)
+	return products, nil
 }
 
 func (s *productService) Update(ctx context.Context, input *dto.ProductUpdateDTO) error {
@@ -73,4 +121,13 @@ func (s *productService) Delete(ctx context.Context, id string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *productService) Restore(ctx context.Context, id string) (*models.Product, error) {
+	if err := s.productStorage.Restore(ctx, id); err != nil {
+		s.logger.Error("failed to restore product", zap.Error(err))
+		return nil, err
+	}
+
+	return s.GetByID(ctx, true, id)
+}
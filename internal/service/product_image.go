@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+	minio_db "caviar/pkg/db/minio"
+	"caviar/pkg/imaging"
+
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// ImageConfig controls product image uploads and the URLs returned for
+// them. When PublicBaseURL is set (a CDN or a public bucket), URLs are
+// built from it; otherwise presigned URLs valid for URLExpiry are used.
+// Quality is the JPEG and WebP quality of the renditions, 1-100.
+type ImageConfig struct {
+	MaxSize       int64
+	PublicBaseURL string
+	URLExpiry     time.Duration
+	Quality       int
+}
+
+// imageRenditions are generated for every uploaded image, each in JPEG
+// and WebP, so the storefront can pick one with srcset.
+var imageRenditions = []imaging.RenditionSpec{
+	{Name: "thumb", MaxSide: 320},
+	{Name: "card", MaxSide: 800},
+	{Name: "full", MaxSide: 1600},
+}
+
+func (s *productService) UploadImage(ctx context.Context, productID string, file io.Reader) (*models.ProductImage, error) {
+	// Read one byte past the limit to tell "exactly at the limit" from
+	// "too large" without trusting the client-supplied size.
+	data, err := io.ReadAll(io.LimitReader(file, s.images.MaxSize+1))
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInvalidInput, "failed to read image")
+	}
+	if int64(len(data)) > s.images.MaxSize {
+		return nil, apperror.New(apperror.CodeInvalidInput,
+			fmt.Sprintf("image exceeds the maximum size of %d bytes", s.images.MaxSize))
+	}
+
+	contentType := http.DetectContentType(data)
+	image, err := models.NewProductImage(productID, contentType, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.productStorage.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	processed, err := imaging.Process(data, contentType, imageRenditions, s.images.Quality)
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInvalidInput, "failed to process image")
+	}
+
+	image.Size = int64(len(processed.Original))
+	image.Width = processed.Width
+	image.Height = processed.Height
+
+	objects := []minio_db.PutObjectParams{{
+		ObjectName:  image.ObjectKey,
+		Reader:      bytes.NewReader(processed.Original),
+		Size:        image.Size,
+		ContentType: image.ContentType,
+	}}
+	for _, r := range processed.Renditions {
+		rendition := models.ImageRendition{
+			Name:        r.Name,
+			Format:      r.Format,
+			ObjectKey:   image.RenditionKey(r.Name, r.Format),
+			ContentType: imaging.ContentTypes[r.Format],
+			Width:       r.Width,
+			Height:      r.Height,
+			Size:        int64(len(r.Data)),
+		}
+		image.Renditions = append(image.Renditions, rendition)
+		objects = append(objects, minio_db.PutObjectParams{
+			ObjectName:  rendition.ObjectKey,
+			Reader:      bytes.NewReader(r.Data),
+			Size:        rendition.Size,
+			ContentType: rendition.ContentType,
+		})
+	}
+
+	uploaded := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		if _, err := s.objects.PutObject(ctx, obj); err != nil {
+			s.logger.Error("failed to upload product image",
+				zap.String("product_id", productID), zap.String("key", obj.ObjectName), zap.Error(err))
+			s.removeObjects(uploaded...)
+			return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to store image")
+		}
+		uploaded = append(uploaded, obj.ObjectName)
+	}
+
+	images, err := s.productStorage.ModifyImages(ctx, productID, func(images models.ProductImages) (models.ProductImages, error) {
+		return images.Add(*image)
+	})
+	if err != nil {
+		s.removeObjects(uploaded...)
+		return nil, err
+	}
+
+	s.attachImageURLs(ctx, images)
+	for _, img := range images {
+		if img.ID == image.ID {
+			return &img, nil
+		}
+	}
+
+	return image, nil
+}
+
+func (s *productService) ReorderImages(ctx context.Context, productID string, imageIDs []string) (models.ProductImages, error) {
+	images, err := s.productStorage.ModifyImages(ctx, productID, func(images models.ProductImages) (models.ProductImages, error) {
+		return images.Reorder(imageIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachImageURLs(ctx, images)
+	return images.Sorted(), nil
+}
+
+func (s *productService) SetPrimaryImage(ctx context.Context, productID, imageID string) (models.ProductImages, error) {
+	images, err := s.productStorage.ModifyImages(ctx, productID, func(images models.ProductImages) (models.ProductImages, error) {
+		return images.SetPrimary(imageID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachImageURLs(ctx, images)
+	return images.Sorted(), nil
+}
+
+func (s *productService) DeleteImage(ctx context.Context, productID, imageID string) error {
+	var removed *models.ProductImage
+
+	_, err := s.productStorage.ModifyImages(ctx, productID, func(images models.ProductImages) (models.ProductImages, error) {
+		result, image, err := images.Remove(imageID)
+		removed = image
+		return result, err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.removeObjects(removed.ObjectKeys()...)
+	return nil
+}
+
+// removeObjects deletes objects on a best-effort basis. The database is
+// the source of truth, so a leftover object only wastes space.
+func (s *productService) removeObjects(keys ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := s.objects.RemoveObject(ctx, key, minio.RemoveObjectOptions{}); err != nil {
+			s.logger.Warn("failed to remove image object", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func (s *productService) attachImageURLs(ctx context.Context, images models.ProductImages) {
+	for i := range images {
+		images[i].URL = s.imageURL(ctx, images[i].ObjectKey)
+		for j := range images[i].Renditions {
+			r := &images[i].Renditions[j]
+			r.URL = s.imageURL(ctx, r.ObjectKey)
+		}
+	}
+}
+
+func (s *productService) attachProductImageURLs(ctx context.Context, products ...*models.Product) {
+	for _, p := range products {
+		if p != nil {
+			s.attachImageURLs(ctx, p.Images)
+		}
+	}
+}
+
+func (s *productService) imageURL(ctx context.Context, key string) string {
+	if s.images.PublicBaseURL != "" {
+		return strings.TrimRight(s.images.PublicBaseURL, "/") + "/" + key
+	}
+
+	u, err := s.objects.GetObjectURL(ctx, minio_db.GetObjectURLParams{
+		ObjectName: key,
+		Expiry:     s.images.URLExpiry,
+	})
+	if err != nil {
+		s.logger.Warn("failed to presign image URL", zap.String("key", key), zap.Error(err))
+		return ""
+	}
+	return u
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"caviar/internal/models"
+	"caviar/internal/types"
+
+	"go.uber.org/zap"
+)
+
+// PricingConfig maps price regions to their currency. Variants without a
+// price for one of Regions get it converted from their BaseRegion price
+// at the current exchange rate.
+type PricingConfig struct {
+	BaseRegion string
+	Regions    map[string]string
+}
+
+// attachDerivedPrices fills the derived prices of the products' variants.
+// Products are still returned without them if the rates cannot be read.
+func (s *productService) attachDerivedPrices(ctx context.Context, products ...*models.Product) {
+	if len(s.pricing.Regions) == 0 || len(products) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	rates, err := s.exchangeRates.List(ctx, &types.ExchangeRateFilter{EffectiveBy: now})
+	if err != nil {
+		s.logger.Warn("failed to load exchange rates for derived prices", zap.Error(err))
+		return
+	}
+
+	for _, p := range products {
+		if p == nil {
+			continue
+		}
+		for i := range p.Variants {
+			p.Variants[i].DerivePrices(s.pricing.BaseRegion, s.pricing.Regions, rates, now)
+		}
+	}
+}
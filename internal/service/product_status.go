@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"caviar/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Publish publishes a product now, or schedules it when publishAt is in
+// the future. Both run the pre-publish checks.
+func (s *productService) Publish(ctx context.Context, id string, publishAt *time.Time) (*models.Product, error) {
+	now := time.Now().UTC()
+
+	product, err := s.productStorage.ModifyStatus(ctx, id, func(p *models.Product) error {
+		if publishAt != nil {
+			return p.Schedule(publishAt.UTC(), now)
+		}
+		return p.Publish(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachProductImageURLs(ctx, product)
+	return product, nil
+}
+
+func (s *productService) Unpublish(ctx context.Context, id string) (*models.Product, error) {
+	product, err := s.productStorage.ModifyStatus(ctx, id, func(p *models.Product) error {
+		return p.Unpublish()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachProductImageURLs(ctx, product)
+	return product, nil
+}
+
+func (s *productService) Archive(ctx context.Context, id string) (*models.Product, error) {
+	product, err := s.productStorage.ModifyStatus(ctx, id, func(p *models.Product) error {
+		return p.Archive(time.Now().UTC())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachProductImageURLs(ctx, product)
+	return product, nil
+}
+
+// RunPublishScheduler publishes scheduled products once their time has
+// come, checking every interval until ctx is done. An interval of zero
+// or less disables it.
+func (s *productService) RunPublishScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.logger.Warn("publish scheduler disabled", zap.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDue publishes every product that is due. A product that no
+// longer passes the checks (e.g. it sold out meanwhile) goes back to
+// draft rather than being retried forever.
+func (s *productService) publishDue(ctx context.Context) {
+	now := time.Now().UTC()
+
+	ids, err := s.productStorage.ListDueScheduled(ctx, now)
+	if err != nil {
+		s.logger.Error("failed to list scheduled products", zap.Error(err))
+		return
+	}
+
+	for _, id := range ids {
+		var published bool
+		var validationErr error
+		_, err := s.productStorage.ModifyStatus(ctx, id, func(p *models.Product) error {
+			// Unpublished or archived since it was listed.
+			if p.Status != models.ProductStatusScheduled {
+				return nil
+			}
+			if validationErr = p.Publish(now); validationErr != nil {
+				return p.Unpublish()
+			}
+			published = true
+			return nil
+		})
+		if err != nil {
+			s.logger.Error("failed to publish scheduled product", zap.String("product_id", id), zap.Error(err))
+			continue
+		}
+		if validationErr != nil {
+			s.logger.Warn("scheduled product returned to draft", zap.String("product_id", id), zap.Error(validationErr))
+			continue
+		}
+		if published {
+			s.logger.Info("published scheduled product", zap.String("product_id", id))
+		}
+	}
+}
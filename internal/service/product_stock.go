@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+	"caviar/internal/types"
+
+	"go.uber.org/zap"
+)
+
+// MoveStock records a manual receipt, write-off or adjustment for a
+// variant of the product. It returns one movement per lot touched.
+func (s *productService) MoveStock(ctx context.Context, productID, variantID string, input *dto.StockMovementCreateDTO) ([]*models.StockMovement, error) {
+	if _, err := s.productStorage.GetVariantByID(ctx, productID, variantID); err != nil {
+		return nil, err
+	}
+
+	movement, err := models.NewManualStockMovement(ctx, variantID, *input)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.productStorage.MoveStock(ctx, movement)
+	if err != nil {
+		s.logger.Error("failed to move stock", zap.String("variant_id", variantID), zap.Error(err))
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// ReceiveLot receives a new lot of a variant of the product and books
+// its quantity as a receipt.
+func (s *productService) ReceiveLot(ctx context.Context, productID, variantID string, input *dto.StockLotCreateDTO) (*models.StockLot, error) {
+	if _, err := s.productStorage.GetVariantByID(ctx, productID, variantID); err != nil {
+		return nil, err
+	}
+
+	lot, err := models.NewStockLot(variantID, *input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	note := strings.TrimSpace(input.Note)
+	if note == "" {
+		note = "lot " + lot.LotNumber
+	}
+	receipt := models.NewStockMovement(ctx, variantID, input.Quantity, models.StockReasonReceipt, lot.LotNumber, note)
+
+	if err := s.productStorage.CreateLot(ctx, lot, receipt); err != nil {
+		s.logger.Error("failed to receive lot", zap.String("variant_id", variantID), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("lot received",
+		zap.String("variant_id", variantID),
+		zap.String("lot_number", lot.LotNumber),
+		zap.Int("quantity", input.Quantity))
+	return lot, nil
+}
+
+// TransferStock moves stock of a variant of the product between
+// warehouses. It returns the movements out of and into each lot touched.
+func (s *productService) TransferStock(ctx context.Context, productID, variantID string, input *dto.StockTransferCreateDTO) ([]*models.StockMovement, error) {
+	if _, err := s.productStorage.GetVariantByID(ctx, productID, variantID); err != nil {
+		return nil, err
+	}
+
+	transfer, err := models.NewStockTransfer(variantID, *input)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.productStorage.TransferStock(ctx, transfer)
+	if err != nil {
+		s.logger.Error("failed to transfer stock", zap.String("variant_id", variantID), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("stock transferred",
+		zap.String("variant_id", variantID),
+		zap.String("from_warehouse_id", transfer.FromWarehouseID),
+		zap.String("to_warehouse_id", transfer.ToWarehouseID),
+		zap.Int("quantity", transfer.Quantity))
+	return movements, nil
+}
+
+func (s *productService) ListLots(ctx context.Context, productID, variantID string, includeEmpty bool) ([]*models.StockLot, error) {
+	if _, err := s.productStorage.GetVariantByID(ctx, productID, variantID); err != nil {
+		return nil, err
+	}
+
+	return s.productStorage.ListLots(ctx, variantID, includeEmpty)
+}
+
+// ListExpiringLots returns lots with stock left that expire within the
+// given number of days, including those already expired.
+func (s *productService) ListExpiringLots(ctx context.Context, days int) ([]*models.StockLot, error) {
+	return s.productStorage.ListExpiringLots(ctx, time.Now().UTC().AddDate(0, 0, days))
+}
+
+func (s *productService) ListStockMovements(ctx context.Context, productID string, filter *types.StockMovementFilter) ([]*models.StockMovement, int64, error) {
+	if _, err := s.productStorage.GetVariantByID(ctx, productID, filter.VariantID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.productStorage.ListStockMovements(ctx, filter)
+}
+
+func (s *productService) ReconcileStock(ctx context.Context) ([]models.StockDiscrepancy, error) {
+	discrepancies, err := s.productStorage.ReconcileStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(discrepancies) > 0 {
+		s.logger.Warn("stock does not match the ledger", zap.Int("variants", len(discrepancies)))
+	}
+	return discrepancies, nil
+}
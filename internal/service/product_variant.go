@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func (s *productService) CreateVariant(ctx context.Context, productID string, input *dto.VariantCreateDTO) (*models.Variant, error) {
+	variant, err := models.NewVariant(*input, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.productStorage.CreateVariant(ctx, productID, variant); err != nil {
+		s.logger.Error("failed to create variant", zap.String("product_id", productID), zap.Error(err))
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (s *productService) UpdateVariant(ctx context.Context, productID, variantID string, input *dto.VariantPatchDTO) (*models.Variant, error) {
+	return s.productStorage.UpdateVariant(ctx, productID, variantID, input.Version, func(v *models.Variant) error {
+		return v.ApplyPatch(*input)
+	})
+}
+
+func (s *productService) DeleteVariant(ctx context.Context, productID, variantID string) error {
+	return s.productStorage.DeleteVariant(ctx, productID, variantID)
+}
+
+// LookupVariant finds a variant by SKU or barcode, e.g. from a warehouse
+// scanner. The SKU is normalized the same way as on save.
+func (s *productService) LookupVariant(ctx context.Context, sku, barcode string) (*models.Product, *models.Variant, error) {
+	sku, err := models.NormalizeSKU(sku)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	product, variant, err := s.productStorage.GetVariantByCode(ctx, sku, strings.TrimSpace(barcode))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.attachProductImageURLs(ctx, product)
+	return product, variant, nil
+}
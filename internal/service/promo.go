@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+
+	"go.uber.org/zap"
+)
+
+type promoService struct {
+	storage PromoStorage
+	logger  *zap.Logger
+}
+
+func NewPromoService(storage PromoStorage, logger *zap.Logger) *promoService {
+	return &promoService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s *promoService) List(ctx context.Context) ([]*models.PromoCode, error) {
+	return s.storage.List(ctx)
+}
+
+func (s *promoService) Create(ctx context.Context, input *dto.PromoCodeCreateDTO) (*models.PromoCode, error) {
+	promo, err := models.NewPromoCode(*input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Create(ctx, promo); err != nil {
+		s.logger.Error("failed to create promo code", zap.String("code", promo.Code), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("promo code created", zap.String("id", promo.ID), zap.String("code", promo.Code))
+	return promo, nil
+}
+
+func (s *promoService) Update(ctx context.Context, id string, input *dto.PromoCodePatchDTO) (*models.PromoCode, error) {
+	promo, err := s.storage.Update(ctx, id, func(p *models.PromoCode) error {
+		return p.ApplyPatch(*input)
+	})
+	if err != nil {
+		s.logger.Error("failed to update promo code", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return promo, nil
+}
@@ -4,7 +4,11 @@ import (
 	"caviar/internal/dto"
 	"caviar/internal/models"
 	"caviar/internal/types"
+	minio_db "caviar/pkg/db/minio"
 	"context"
+	"time"
+
+	"github.com/minio/minio-go/v7"
 )
 
 type ProductStorage interface {
@@ -12,19 +16,87 @@ type ProductStorage interface {
 	GetByID(ctx context.Context, id string) (*models.Product, error)
 	GetBySlug(ctx context.Context, slug string) (*models.Product, error)
 	GetVariantByID(ctx context.Context, productID, variantID string) (*models.Variant, error)
+	GetVariantByCode(ctx context.Context, sku, barcode string) (*models.Product, *models.Variant, error)
 	List(ctx context.Context, filter *types.ProductFilter) ([]*models.Product, error)
 	Update(ctx context.Context, input *dto.ProductUpdateDTO) error
-	UpdateVariantStock(ctx context.Context, variantID string, stockChange int) error
+	MoveStock(ctx context.Context, movement *models.StockMovement) ([]*models.StockMovement, error)
+	ListStockMovements(ctx context.Context, filter *types.StockMovementFilter) ([]*models.StockMovement, int64, error)
+	ReconcileStock(ctx context.Context) ([]models.StockDiscrepancy, error)
+	CreateLot(ctx context.Context, lot *models.StockLot, receipt *models.StockMovement) error
+	ListLots(ctx context.Context, variantID string, includeEmpty bool) ([]*models.StockLot, error)
+	ListExpiringLots(ctx context.Context, before time.Time) ([]*models.StockLot, error)
+	TransferStock(ctx context.Context, transfer *models.StockTransfer) ([]*models.StockMovement, error)
+	AvailableStock(ctx context.Context, warehouseID string, variantIDs []string) (map[string]int, error)
+	CreateVariant(ctx context.Context, productID string, variant *models.Variant) error
+	UpdateVariant(ctx context.Context, productID, variantID string, version int, fn func(variant *models.Variant) error) (*models.Variant, error)
+	DeleteVariant(ctx context.Context, productID, variantID string) error
+	ModifyImages(ctx context.Context, productID string, fn func(images models.ProductImages) (models.ProductImages, error)) (models.ProductImages, error)
+	ModifyStatus(ctx context.Context, productID string, fn func(product *models.Product) error) (*models.Product, error)
+	ListDueScheduled(ctx context.Context, now time.Time) ([]string, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 }
 
 type OrderStorage interface {
-	Create(ctx context.Context, order *models.Order) error
+	Create(ctx context.Context, order *models.Order, redemption *models.PromoRedemption) error
 	GetByID(ctx context.Context, id string) (*models.Order, error)
 	GetByOrderNumber(ctx context.Context, orderNumber string) (*models.Order, error)
 	List(ctx context.Context, filter *types.OrderFilter) ([]*models.Order, int64, error)
 	Update(ctx context.Context, order *models.Order) error
-	UpdateStatus(ctx context.Context, id string, status models.OrderStatus) error
+	UpdateStatus(ctx context.Context, id string, from, to models.OrderStatus, trackingNumber string) error
+	ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]*models.Order, error)
+	ExtendHold(ctx context.Context, id string, until, now time.Time) (bool, error)
+	CancelExpiredHold(ctx context.Context, id string, now time.Time) (bool, error)
+	MarkPaid(ctx context.Context, id string, at time.Time) (bool, error)
+	Refund(ctx context.Context, id string, fn func(order *models.Order) (*models.Refund, error)) (*models.Refund, error)
 	Delete(ctx context.Context, id string) error
 	GetOrderStatistics(ctx context.Context) (map[string]any, error)
+	DailyRevenue(ctx context.Context) ([]models.DailyRevenue, error)
+}
+
+type WarehouseStorage interface {
+	List(ctx context.Context) ([]*models.Warehouse, error)
+	GetByID(ctx context.Context, id string) (*models.Warehouse, error)
+	Create(ctx context.Context, warehouse *models.Warehouse) error
+	Update(ctx context.Context, id string, fn func(warehouse *models.Warehouse) error) (*models.Warehouse, error)
+}
+
+type PromoStorage interface {
+	List(ctx context.Context) ([]*models.PromoCode, error)
+	GetByCode(ctx context.Context, code string) (*models.PromoCode, error)
+	Create(ctx context.Context, p *models.PromoCode) error
+	Update(ctx context.Context, id string, fn func(p *models.PromoCode) error) (*models.PromoCode, error)
+}
+
+type ShippingStorage interface {
+	ListZones(ctx context.Context) ([]*models.ShippingZone, error)
+	CreateZone(ctx context.Context, z *models.ShippingZone) error
+	UpdateZone(ctx context.Context, id string, fn func(z *models.ShippingZone) error) (*models.ShippingZone, error)
+	ListRates(ctx context.Context, zoneID string) ([]*models.ShippingRate, error)
+	ListActiveRates(ctx context.Context, deliveryType models.DeliveryType) ([]*models.ShippingRate, error)
+	CreateRate(ctx context.Context, r *models.ShippingRate) error
+	UpdateRate(ctx context.Context, id string, fn func(r *models.ShippingRate) error) (*models.ShippingRate, error)
+	DeleteRate(ctx context.Context, id string) error
+}
+
+type TaxStorage interface {
+	List(ctx context.Context) ([]*models.TaxRate, error)
+	GetByCountry(ctx context.Context, country string) (*models.TaxRate, error)
+	Create(ctx context.Context, t *models.TaxRate) error
+	Update(ctx context.Context, id string, fn func(t *models.TaxRate) error) (*models.TaxRate, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type ExchangeRateStorage interface {
+	List(ctx context.Context, filter *types.ExchangeRateFilter) ([]*models.ExchangeRate, error)
+	Save(ctx context.Context, rates []*models.ExchangeRate) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ObjectStorage stores product images. It is implemented by
+// minio_db.Minio.
+type ObjectStorage interface {
+	PutObject(ctx context.Context, params minio_db.PutObjectParams) (minio.UploadInfo, error)
+	RemoveObject(ctx context.Context, objectName string, opts minio.RemoveObjectOptions) error
+	GetObjectURL(ctx context.Context, params minio_db.GetObjectURLParams) (string, error)
 }
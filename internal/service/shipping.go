@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+
+	"go.uber.org/zap"
+)
+
+// shippingQuoter prices the delivery of order items from the weight of
+// their variants and the packaging they travel in.
+type shippingQuoter struct {
+	shippingStorage ShippingStorage
+	productStorage  ProductStorage
+	packaging       models.Packaging
+}
+
+// Quote prices delivering items to country. subtotal is the value of the
+// items after discounts, for the free shipping threshold.
+func (q *shippingQuoter) Quote(ctx context.Context, deliveryType models.DeliveryType, country string, items []dto.OrderItemDTO, subtotal models.Money) (*models.ShippingQuote, error) {
+	var goods int
+	for i, item := range items {
+		variant, err := q.productStorage.GetVariantByID(ctx, item.ProductID, item.VariantID)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeNotFound,
+				fmt.Sprintf("variant not found for item %d (variant_id: %s)", i+1, item.VariantID))
+		}
+		goods += variant.Mass * item.Quantity
+	}
+
+	rates, err := q.shippingStorage.ListActiveRates(ctx, deliveryType)
+	if err != nil {
+		return nil, err
+	}
+	return models.QuoteShipping(rates, deliveryType, country, q.packaging.ParcelWeight(goods), subtotal)
+}
+
+type shippingService struct {
+	storage ShippingStorage
+	quoter  *shippingQuoter
+	logger  *zap.Logger
+}
+
+func NewShippingService(storage ShippingStorage, productStorage ProductStorage, packaging models.Packaging, logger *zap.Logger) *shippingService {
+	return &shippingService{
+		storage: storage,
+		quoter: &shippingQuoter{
+			shippingStorage: storage,
+			productStorage:  productStorage,
+			packaging:       packaging,
+		},
+		logger: logger,
+	}
+}
+
+func (s *shippingService) ListZones(ctx context.Context) ([]*models.ShippingZone, error) {
+	return s.storage.ListZones(ctx)
+}
+
+func (s *shippingService) CreateZone(ctx context.Context, input *dto.ShippingZoneCreateDTO) (*models.ShippingZone, error) {
+	zone, err := models.NewShippingZone(*input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.CreateZone(ctx, zone); err != nil {
+		s.logger.Error("failed to create shipping zone", zap.String("code", zone.Code), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("shipping zone created", zap.String("id", zone.ID), zap.String("code", zone.Code))
+	return zone, nil
+}
+
+func (s *shippingService) UpdateZone(ctx context.Context, id string, input *dto.ShippingZonePatchDTO) (*models.ShippingZone, error) {
+	zone, err := s.storage.UpdateZone(ctx, id, func(z *models.ShippingZone) error {
+		return z.ApplyPatch(*input)
+	})
+	if err != nil {
+		s.logger.Error("failed to update shipping zone", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (s *shippingService) ListRates(ctx context.Context, zoneID string) ([]*models.ShippingRate, error) {
+	return s.storage.ListRates(ctx, zoneID)
+}
+
+func (s *shippingService) CreateRate(ctx context.Context, input *dto.ShippingRateCreateDTO) (*models.ShippingRate, error) {
+	rate, err := models.NewShippingRate(*input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.CreateRate(ctx, rate); err != nil {
+		s.logger.Error("failed to create shipping rate", zap.String("zone_id", rate.ZoneID), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("shipping rate created", zap.String("id", rate.ID), zap.String("zone_id", rate.ZoneID))
+	return rate, nil
+}
+
+func (s *shippingService) UpdateRate(ctx context.Context, id string, input *dto.ShippingRatePatchDTO) (*models.ShippingRate, error) {
+	rate, err := s.storage.UpdateRate(ctx, id, func(r *models.ShippingRate) error {
+		return r.ApplyPatch(*input)
+	})
+	if err != nil {
+		s.logger.Error("failed to update shipping rate", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (s *shippingService) DeleteRate(ctx context.Context, id string) error {
+	if err := s.storage.DeleteRate(ctx, id); err != nil {
+		s.logger.Error("failed to delete shipping rate", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("shipping rate deleted", zap.String("id", id))
+	return nil
+}
+
+// Quote prices delivering the items before an order is placed. Promo
+// codes are not taken into account.
+func (s *shippingService) Quote(ctx context.Context, input *dto.ShippingQuoteRequestDTO) (*models.ShippingQuote, error) {
+	var subtotal models.Money
+	for i, item := range input.Items {
+		if i == 0 {
+			subtotal.Currency = item.UnitPrice.Currency
+		} else if item.UnitPrice.Currency != subtotal.Currency {
+			return nil, apperror.New(apperror.CodeInvalidInput, "all items must have the same currency")
+		}
+		subtotal.Amount += item.UnitPrice.Amount * item.Quantity
+	}
+
+	return s.quoter.Quote(ctx, models.DeliveryType(input.DeliveryType), input.Country, input.Items, subtotal)
+}
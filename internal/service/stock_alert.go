@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"caviar/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// StockAlertStorage is the part of the product storage the stock alerts
+// need.
+type StockAlertStorage interface {
+	GetByID(ctx context.Context, id string) (*models.Product, error)
+	ClaimLowStockVariants(ctx context.Context, at time.Time) ([]*models.Variant, error)
+	ReleaseLowStockAlerts(ctx context.Context, variantIDs []string, at time.Time) error
+	ClearRecoveredLowStock(ctx context.Context) (int64, error)
+	ClaimExpiringLots(ctx context.Context, before, at time.Time) ([]*models.StockLot, error)
+	ReleaseLotExpiryAlerts(ctx context.Context, lotIDs []string, at time.Time) error
+}
+
+// StockAlertService watches stock and tells staff when a variant drops
+// to its low-stock threshold or a lot nears expiry. Each variant is
+// alerted once until its stock recovers above the threshold, and each
+// lot once. Rows are claimed before the alert is sent, so instances
+// checking at the same time do not send it twice.
+type StockAlertService struct {
+	storage       StockAlertStorage
+	notifications *NotificationService
+	expiryDays    int
+	logger        *zap.Logger
+}
+
+func NewStockAlertService(storage StockAlertStorage, notifications *NotificationService, expiryDays int, logger *zap.Logger) *StockAlertService {
+	return &StockAlertService{
+		storage:       storage,
+		notifications: notifications,
+		expiryDays:    expiryDays,
+		logger:        logger,
+	}
+}
+
+// Run checks stock every interval until ctx is done. An interval of zero
+// or less disables the alerts.
+func (s *StockAlertService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.logger.Warn("stock alerts disabled", zap.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check sends the alerts that are due.
+func (s *StockAlertService) Check(ctx context.Context) {
+	s.checkLowStock(ctx)
+	s.checkExpiringLots(ctx)
+}
+
+func (s *StockAlertService) checkLowStock(ctx context.Context) {
+	if cleared, err := s.storage.ClearRecoveredLowStock(ctx); err != nil {
+		s.logger.Error("failed to clear recovered low stock alerts", zap.Error(err))
+	} else if cleared > 0 {
+		s.logger.Info("stock recovered", zap.Int64("variants", cleared))
+	}
+
+	// Postgres keeps microseconds; the claim time is matched on release.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	variants, err := s.storage.ClaimLowStockVariants(ctx, now)
+	if err != nil {
+		s.logger.Error("failed to claim low stock variants", zap.Error(err))
+		return
+	}
+	if len(variants) == 0 {
+		return
+	}
+
+	names := make(map[string]string)
+	ids := make([]string, 0, len(variants))
+	for _, v := range variants {
+		ids = append(ids, v.ID)
+		if _, ok := names[v.ProductID]; ok {
+			continue
+		}
+		names[v.ProductID] = v.ProductID
+		if p, err := s.storage.GetByID(ctx, v.ProductID); err == nil {
+			names[v.ProductID] = p.Name
+		}
+	}
+
+	// Release the claim if the alert did not go out, so it is retried on
+	// the next check.
+	if err := s.notifications.SendLowStockAlert(ctx, variants, names); err != nil {
+		s.logger.Error("failed to send low stock alert", zap.Error(err))
+		if err := s.storage.ReleaseLowStockAlerts(ctx, ids, now); err != nil {
+			s.logger.Error("failed to release low stock alerts", zap.Error(err))
+		}
+	}
+}
+
+func (s *StockAlertService) checkExpiringLots(ctx context.Context) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	lots, err := s.storage.ClaimExpiringLots(ctx, now.AddDate(0, 0, s.expiryDays), now)
+	if err != nil {
+		s.logger.Error("failed to claim expiring lots", zap.Error(err))
+		return
+	}
+	if len(lots) == 0 {
+		return
+	}
+
+	if err := s.notifications.SendLotExpiryAlert(ctx, lots); err != nil {
+		s.logger.Error("failed to send lot expiry alert", zap.Error(err))
+
+		ids := make([]string, 0, len(lots))
+		for _, lot := range lots {
+			ids = append(ids, lot.ID)
+		}
+		if err := s.storage.ReleaseLotExpiryAlerts(ctx, ids, now); err != nil {
+			s.logger.Error("failed to release lot expiry alerts", zap.Error(err))
+		}
+	}
+}
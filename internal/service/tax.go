@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+
+	"go.uber.org/zap"
+)
+
+type taxService struct {
+	storage TaxStorage
+	logger  *zap.Logger
+}
+
+func NewTaxService(storage TaxStorage, logger *zap.Logger) *taxService {
+	return &taxService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s *taxService) List(ctx context.Context) ([]*models.TaxRate, error) {
+	return s.storage.List(ctx)
+}
+
+func (s *taxService) Create(ctx context.Context, input *dto.TaxRateCreateDTO) (*models.TaxRate, error) {
+	rate, err := models.NewTaxRate(*input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Create(ctx, rate); err != nil {
+		s.logger.Error("failed to create tax rate", zap.String("country", rate.Country), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("tax rate created", zap.String("id", rate.ID), zap.String("country", rate.Country))
+	return rate, nil
+}
+
+func (s *taxService) Update(ctx context.Context, id string, input *dto.TaxRatePatchDTO) (*models.TaxRate, error) {
+	rate, err := s.storage.Update(ctx, id, func(t *models.TaxRate) error {
+		return t.ApplyPatch(*input)
+	})
+	if err != nil {
+		s.logger.Error("failed to update tax rate", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (s *taxService) Delete(ctx context.Context, id string) error {
+	if err := s.storage.Delete(ctx, id); err != nil {
+		s.logger.Error("failed to delete tax rate", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("tax rate deleted", zap.String("id", id))
+	return nil
+}
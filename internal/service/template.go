@@ -0,0 +1,408 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+	"caviar/internal/templates"
+	"caviar/pkg/apperror"
+
+	"go.uber.org/zap"
+)
+
+type TemplateStorage interface {
+	Get(ctx context.Context, event, channel, locale string) (*models.NotificationTemplate, error)
+	List(ctx context.Context) ([]*models.NotificationTemplate, error)
+	Upsert(ctx context.Context, t *models.NotificationTemplate) error
+	Delete(ctx context.Context, event, channel, locale string) error
+}
+
+// OrderTemplateData is passed to every order-related template.
+type OrderTemplateData struct {
+	Order        *models.Order
+	CustomerName string
+	Items        []OrderItemLine
+}
+
+// OrderItemLine is a flattened order item for message templates.
+type OrderItemLine struct {
+	Name      string
+	Mass      int
+	Quantity  int
+	UnitPrice models.Money
+	Total     models.Money
+}
+
+// PaidAfterCancelTemplateData is passed to the template telling staff
+// about a payment for a cancelled order.
+type PaidAfterCancelTemplateData struct {
+	OrderTemplateData
+	Payment *models.Payment
+}
+
+// LowStockTemplateData is passed to the low stock alert template.
+type LowStockTemplateData struct {
+	Items []LowStockLine
+}
+
+// LowStockLine is a variant at or below its low-stock threshold.
+type LowStockLine struct {
+	Name      string
+	Mass      int
+	SKU       string
+	Stock     int
+	Threshold int
+}
+
+// LotExpiryTemplateData is passed to the lot expiry alert template.
+type LotExpiryTemplateData struct {
+	Lots []LotExpiryLine
+}
+
+// LotExpiryLine is a lot nearing or past its expiry date. DaysLeft is
+// negative once it has expired.
+type LotExpiryLine struct {
+	Name      string
+	Mass      int
+	SKU       string
+	LotNumber string
+	Warehouse string
+	ExpiresAt time.Time
+	DaysLeft  int
+	Quantity  int
+}
+
+type executableTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// TemplateService renders notification templates. Database overrides
+// win over the built-in templates, and the default locale is used when
+// nothing exists for the requested one. Templates are read on every
+// render, so edits take effect without a restart.
+type TemplateService struct {
+	storage       TemplateStorage
+	defaultLocale string
+	logger        *zap.Logger
+}
+
+func NewTemplateService(storage TemplateStorage, defaultLocale string, logger *zap.Logger) *TemplateService {
+	if !models.IsSupportedLocale(defaultLocale) {
+		defaultLocale = models.DefaultLocale
+	}
+
+	return &TemplateService{
+		storage:       storage,
+		defaultLocale: defaultLocale,
+		logger:        logger,
+	}
+}
+
+func (s *TemplateService) DefaultLocale() string {
+	return s.defaultLocale
+}
+
+func (s *TemplateService) Render(ctx context.Context, event string, channel NotificationChannel, locale string, data any) (string, error) {
+	body, err := s.resolve(ctx, event, string(channel), locale)
+	if err != nil {
+		return "", err
+	}
+
+	return s.execute(event, string(channel), body, data)
+}
+
+// List returns the effective template for every event, channel and
+// locale: the stored override when there is one, the built-in otherwise.
+func (s *TemplateService) List(ctx context.Context) ([]*models.NotificationTemplate, error) {
+	overrides, err := s.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]*models.NotificationTemplate, len(overrides))
+	for _, t := range overrides {
+		stored[templateKey(t.Event, t.Channel, t.Locale)] = t
+	}
+
+	events := make([]string, 0, len(models.TemplateEvents))
+	for event := range models.TemplateEvents {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+
+	var result []*models.NotificationTemplate
+	for _, event := range events {
+		for _, channel := range models.TemplateEvents[event] {
+			for _, locale := range models.SupportedLocales {
+				if t, ok := stored[templateKey(event, channel, locale)]; ok {
+					result = append(result, t)
+					continue
+				}
+				if body, ok := templates.Default(event, channel, locale); ok {
+					result = append(result, &models.NotificationTemplate{
+						Event:     event,
+						Channel:   channel,
+						Locale:    locale,
+						Body:      body,
+						IsDefault: true,
+					})
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *TemplateService) Upsert(ctx context.Context, input *dto.NotificationTemplateUpsertDTO) (*models.NotificationTemplate, error) {
+	t, err := models.NewNotificationTemplate(*input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Render against sample data so typos in field names are caught
+	// here rather than when the next real event comes in.
+	if _, err := s.execute(t.Event, t.Channel, t.Body, sampleTemplateData(t.Event)); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Upsert(ctx, t); err != nil {
+		s.logger.Error("failed to save notification template", zap.Error(err))
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *TemplateService) Delete(ctx context.Context, event, channel, locale string) error {
+	if err := s.storage.Delete(ctx, event, channel, locale); err != nil {
+		s.logger.Error("failed to delete notification template", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *TemplateService) resolve(ctx context.Context, event, channel, locale string) (string, error) {
+	locales := []string{locale}
+	if locale != s.defaultLocale {
+		locales = append(locales, s.defaultLocale)
+	}
+
+	for _, l := range locales {
+		if l == "" {
+			continue
+		}
+
+		t, err := s.storage.Get(ctx, event, channel, l)
+		if err == nil {
+			return t.Body, nil
+		}
+		if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != apperror.CodeNotFound {
+			s.logger.Warn("Failed to load template override, using built-in",
+				zap.String("event", event),
+				zap.String("channel", channel),
+				zap.String("locale", l),
+				zap.Error(err))
+		}
+
+		if body, ok := templates.Default(event, channel, l); ok {
+			return body, nil
+		}
+	}
+
+	return "", apperror.New(apperror.CodeNotFound, "no template for "+templateKey(event, channel, locale))
+}
+
+// execute parses body with html/template for Telegram (HTML parse mode)
+// so that customer input is escaped, and with text/template elsewhere.
+func (s *TemplateService) execute(event, channel, body string, data any) (string, error) {
+	var tmpl executableTemplate
+	var err error
+
+	if channel == string(ChannelTelegram) {
+		tmpl, err = htmltemplate.New(event).Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(body)
+	} else {
+		tmpl, err = texttemplate.New(event).Funcs(texttemplate.FuncMap(templateFuncs)).Parse(body)
+	}
+	if err != nil {
+		return "", apperror.Wrap(err, apperror.CodeInvalidInput, "failed to parse template")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", apperror.Wrap(err, apperror.CodeInvalidInput, "failed to render template")
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+var templateFuncs = map[string]any{
+	"date": func(t time.Time) string {
+		return t.Format(dateTimeFormat)
+	},
+	"day": func(t time.Time) string {
+		return t.Format(dayFormat)
+	},
+	"upper": strings.ToUpper,
+}
+
+func templateKey(event, channel, locale string) string {
+	return event + "/" + channel + "/" + locale
+}
+
+func newOrderTemplateData(order *models.Order) OrderTemplateData {
+	name := order.CustomerInfo.FullName
+	if name == "" {
+		name = strings.TrimSpace(order.CustomerInfo.FirstName + " " + order.CustomerInfo.LastName)
+	}
+
+	items := make([]OrderItemLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		// Items refunded in full are no longer part of the order.
+		if item.Quantity == 0 {
+			continue
+		}
+		line := OrderItemLine{
+			Name:      item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Total:     item.TotalPrice,
+		}
+		if item.Product != nil {
+			line.Name = item.Product.Name
+		}
+		if item.Variant != nil {
+			line.Mass = item.Variant.Mass
+		}
+		items = append(items, line)
+	}
+
+	return OrderTemplateData{
+		Order:        order,
+		CustomerName: name,
+		Items:        items,
+	}
+}
+
+func newLowStockTemplateData(variants []*models.Variant, productNames map[string]string) LowStockTemplateData {
+	items := make([]LowStockLine, 0, len(variants))
+	for _, v := range variants {
+		items = append(items, LowStockLine{
+			Name:      productNames[v.ProductID],
+			Mass:      v.Mass,
+			SKU:       v.SKU,
+			Stock:     v.Stock,
+			Threshold: v.LowStockThreshold,
+		})
+	}
+	return LowStockTemplateData{Items: items}
+}
+
+func newLotExpiryTemplateData(lots []*models.StockLot, now time.Time) LotExpiryTemplateData {
+	lines := make([]LotExpiryLine, 0, len(lots))
+	for _, lot := range lots {
+		line := LotExpiryLine{
+			LotNumber: lot.LotNumber,
+			Quantity:  lot.Quantity,
+		}
+		if lot.ExpiresAt != nil {
+			line.ExpiresAt = *lot.ExpiresAt
+		}
+		if days := lot.DaysToExpiry(now); days != nil {
+			line.DaysLeft = *days
+		}
+		if lot.Product != nil {
+			line.Name = lot.Product.Name
+		}
+		if lot.Variant != nil {
+			line.Mass = lot.Variant.Mass
+			line.SKU = lot.Variant.SKU
+		}
+		if lot.Warehouse != nil {
+			line.Warehouse = lot.Warehouse.Code
+		}
+		lines = append(lines, line)
+	}
+	return LotExpiryTemplateData{Lots: lines}
+}
+
+// sampleTemplateData returns data of the shape the event's template
+// receives.
+func sampleTemplateData(event string) any {
+	switch event {
+	case models.TemplateEventLowStock:
+		return LowStockTemplateData{Items: []LowStockLine{{
+			Name:      "Sample Caviar",
+			Mass:      50,
+			SKU:       "SAMPLE-50",
+			Stock:     2,
+			Threshold: 5,
+		}}}
+	case models.TemplateEventLotExpiring:
+		return LotExpiryTemplateData{Lots: []LotExpiryLine{{
+			Name:      "Sample Caviar",
+			Mass:      50,
+			SKU:       "SAMPLE-50",
+			LotNumber: "L-0001",
+			Warehouse: "MAIN",
+			ExpiresAt: time.Now().AddDate(0, 0, 7),
+			DaysLeft:  7,
+			Quantity:  10,
+		}}}
+	}
+
+	order := newOrderTemplateData(&models.Order{
+		ID:          "00000000-0000-0000-0000-000000000000",
+		OrderNumber: "ORD00000000-0000",
+		CustomerInfo: models.CustomerInfo{
+			FullName: "Sample Customer",
+			Phone:    "+380000000000",
+		},
+		DeliveryInfo: models.DeliveryInfo{
+			Type:       models.DeliveryTypePostOffice,
+			Country:    "UA",
+			City:       "Kyiv",
+			PostOffice: "1",
+		},
+		Items: []models.OrderItem{{
+			Quantity:   1,
+			UnitPrice:  models.Money{Amount: 1000, Currency: "UAH"},
+			TotalPrice: models.Money{Amount: 1000, Currency: "UAH"},
+			Product:    &models.Product{Name: "Sample Caviar"},
+			Variant:    &models.Variant{Mass: 50},
+		}},
+		TotalAmount:    models.Money{Amount: 1110, Currency: "UAH"},
+		Status:         models.OrderStatusPending,
+		PaymentMethod:  models.PaymentMethodCOD,
+		PaymentFee:     40,
+		ShippingCost:   70,
+		ShippingWeight: 450,
+		TrackingNumber: "20400000000000",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	})
+
+	if event == models.TemplateEventOrderPaidAfterCancel {
+		paidAt := time.Now()
+		return PaidAfterCancelTemplateData{
+			OrderTemplateData: order,
+			Payment: &models.Payment{
+				Provider:   "liqpay",
+				ExternalID: "1234567890",
+				Amount:     1110,
+				Currency:   "UAH",
+				Status:     models.PaymentStatusSucceeded,
+				PaidAt:     &paidAt,
+			},
+		}
+	}
+	return order
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"caviar/internal/dto"
+	"caviar/internal/models"
+
+	"go.uber.org/zap"
+)
+
+type warehouseService struct {
+	storage WarehouseStorage
+	logger  *zap.Logger
+}
+
+func NewWarehouseService(storage WarehouseStorage, logger *zap.Logger) *warehouseService {
+	return &warehouseService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s *warehouseService) List(ctx context.Context) ([]*models.Warehouse, error) {
+	return s.storage.List(ctx)
+}
+
+// Create adds a warehouse. A warehouse created as the default replaces
+// the current default.
+func (s *warehouseService) Create(ctx context.Context, input *dto.WarehouseCreateDTO) (*models.Warehouse, error) {
+	warehouse, err := models.NewWarehouse(*input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Create(ctx, warehouse); err != nil {
+		s.logger.Error("failed to create warehouse", zap.String("code", warehouse.Code), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("warehouse created", zap.String("id", warehouse.ID), zap.String("code", warehouse.Code))
+	return warehouse, nil
+}
+
+func (s *warehouseService) Update(ctx context.Context, id string, input *dto.WarehousePatchDTO) (*models.Warehouse, error) {
+	warehouse, err := s.storage.Update(ctx, id, func(w *models.Warehouse) error {
+		return w.ApplyPatch(*input)
+	})
+	if err != nil {
+		s.logger.Error("failed to update warehouse", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return warehouse, nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/internal/types"
+	"caviar/pkg/apperror"
+)
+
+type exchangeRateStorage struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateStorage(db *gorm.DB) *exchangeRateStorage {
+	return &exchangeRateStorage{
+		db: db.Session(&gorm.Session{
+			PrepareStmt: true,
+		}),
+	}
+}
+
+// List returns the rates matching the filter, latest first.
+func (s *exchangeRateStorage) List(ctx context.Context, filter *types.ExchangeRateFilter) ([]*models.ExchangeRate, error) {
+	query := s.db.WithContext(ctx).Order("effective_on DESC, base, quote")
+	if filter.Currency != "" {
+		query = query.Where("base = ? OR quote = ?", filter.Currency, filter.Currency)
+	}
+	if !filter.EffectiveBy.IsZero() {
+		query = query.Where("effective_on <= ?", filter.EffectiveBy.Format(models.DateLayout))
+	}
+
+	var rates []*models.ExchangeRate
+	if err := query.Find(&rates).Error; err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list exchange rates")
+	}
+	return rates, nil
+}
+
+// exchangeRateBatch is the rows per INSERT of Save, well below the
+// 65535 bind parameters of a postgres statement.
+const exchangeRateBatch = 1000
+
+// Save stores the rates, replacing those already set for the same pair
+// and day, and fills in the stored rows. The rates are inserted in
+// batches, all or none.
+func (s *exchangeRateStorage) Save(ctx context.Context, rates []*models.ExchangeRate) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.
+			Clauses(
+				clause.OnConflict{
+					Columns:   []clause.Column{{Name: "base"}, {Name: "quote"}, {Name: "effective_on"}},
+					DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+				},
+				clause.Returning{},
+			).
+			CreateInBatches(&rates, exchangeRateBatch).
+			Error
+	})
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to save exchange rates")
+	}
+	return nil
+}
+
+func (s *exchangeRateStorage) Delete(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Delete(&models.ExchangeRate{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Wrap(result.Error, apperror.CodeInternal, "failed to delete exchange rate")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.New(apperror.CodeNotFound, "exchange rate not found")
+	}
+	return nil
+}
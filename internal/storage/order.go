@@ -2,12 +2,16 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
 	"caviar/internal/models"
 	"caviar/internal/types"
 	"caviar/pkg/apperror"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderStorage struct {
@@ -20,18 +24,47 @@ func NewOrderStorage(db *gorm.DB) *OrderStorage {
 	}
 }
 
-func (s *OrderStorage) Create(ctx context.Context, order *models.Order) error {
-	if err := s.db.WithContext(ctx).Create(order).Error; err != nil {
-		return apperror.New(apperror.CodeInternal, "failed to create order: "+err.Error())
-	}
-	return nil
+func orderedByCreation(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at, id")
+}
+
+// withDeleted lets orders resolve products and variants that were
+// deleted after the order was placed.
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// Create stores the order together with the redemption of its promo
+// code, if any, so a code is only used up by orders that were placed.
+func (s *OrderStorage) Create(ctx context.Context, order *models.Order, redemption *models.PromoRedemption) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if redemption != nil {
+			if err := checkPromoLimits(tx, redemption); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return apperror.New(apperror.CodeInternal, "failed to create order: "+err.Error())
+		}
+
+		if redemption != nil {
+			if err := tx.Create(redemption).Error; err != nil {
+				return apperror.Wrap(err, apperror.CodeInternal, "failed to redeem promo code")
+			}
+		}
+		return nil
+	})
 }
 
 func (s *OrderStorage) GetByID(ctx context.Context, id string) (*models.Order, error) {
 	var order models.Order
 	err := s.db.WithContext(ctx).
 		Preload("Items").
-		Preload("Items.Product").
+		Preload("Items.Product", withDeleted).
+		Preload("Items.Variant", withDeleted).
+		Preload("Payments", orderedByCreation).
+		Preload("Refunds", orderedByCreation).
 		Where("id = ?", id).
 		First(&order).Error
 
@@ -49,7 +82,10 @@ func (s *OrderStorage) GetByOrderNumber(ctx context.Context, orderNumber string)
 	var order models.Order
 	err := s.db.WithContext(ctx).
 		Preload("Items").
-		Preload("Items.Product").
+		Preload("Items.Product", withDeleted).
+		Preload("Items.Variant", withDeleted).
+		Preload("Payments", orderedByCreation).
+		Preload("Refunds", orderedByCreation).
 		Where("order_number = ?", orderNumber).
 		First(&order).Error
 
@@ -81,6 +117,16 @@ func (s *OrderStorage) List(ctx context.Context, filter *types.OrderFilter) ([]*
 		query = query.Where("delivery_info->>'country' = ?", filter.Country)
 	}
 
+	if filter.LotID != "" {
+		lot, _ := json.Marshal([]map[string]string{{"lot_id": filter.LotID}})
+		query = query.Where("id IN (?)",
+			s.db.Table("order_items").Select("order_id").Where("lots @> ?::jsonb", string(lot)))
+	}
+
+	if filter.WarehouseID != "" {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+
 	if !filter.CreatedFrom.IsZero() {
 		query = query.Where("created_at >= ?", filter.CreatedFrom)
 	}
@@ -94,7 +140,8 @@ func (s *OrderStorage) List(ctx context.Context, filter *types.OrderFilter) ([]*
 	}
 
 	query = query.Preload("Items").
-		Preload("Items.Product").
+		Preload("Items.Product", withDeleted).
+		Preload("Items.Variant", withDeleted).
 		Order("created_at DESC")
 
 	if filter.Limit > 0 {
@@ -118,26 +165,235 @@ func (s *OrderStorage) Update(ctx context.Context, order *models.Order) error {
 	return nil
 }
 
-func (s *OrderStorage) UpdateStatus(ctx context.Context, id string, status models.OrderStatus) error {
+// UpdateStatus sets the order's status, and its tracking number in the
+// same write unless trackingNumber is empty. An order leaving pending no
+// longer has a hold to expire.
+// UpdateStatus moves the order from status from to status to. It fails
+// with a conflict when the order is no longer in from, so a change made
+// meanwhile, such as the hold sweeper cancelling it, is not overwritten
+// and only one caller goes on to release the stock of a cancelled order.
+func (s *OrderStorage) UpdateStatus(ctx context.Context, id string, from, to models.OrderStatus, trackingNumber string) error {
+	updates := map[string]any{
+		"status":     to,
+		"updated_at": "NOW()",
+	}
+	if trackingNumber != "" {
+		updates["tracking_number"] = trackingNumber
+	}
+	if to != models.OrderStatusPending {
+		updates["hold_expires_at"] = nil
+	}
+
 	result := s.db.WithContext(ctx).
 		Model(&models.Order{}).
-		Where("id = ?", id).
-		Updates(map[string]any{
-			"status":     status,
-			"updated_at": "NOW()",
-		})
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
 
 	if result.Error != nil {
 		return apperror.New(apperror.CodeInternal, "failed to update order status: "+result.Error.Error())
 	}
 
 	if result.RowsAffected == 0 {
-		return apperror.New(apperror.CodeNotFound, "order not found")
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.Order{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to check order")
+		}
+		if count == 0 {
+			return apperror.New(apperror.CodeNotFound, "order not found")
+		}
+		return apperror.New(apperror.CodeConflict, "order status was changed meanwhile, reload the order and try again")
 	}
 
 	return nil
 }
 
+// MarkPaid records that the order was paid at the given time and
+// confirms it if it is still pending. It is idempotent and reports
+// whether the order was confirmed; a paid order cancelled meanwhile
+// (e.g. its hold expired) stays cancelled.
+func (s *OrderStorage) MarkPaid(ctx context.Context, id string, at time.Time) (bool, error) {
+	var confirmed bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&models.Order{}).
+			Where("id = ? AND paid_at IS NULL", id).
+			Updates(map[string]any{
+				"paid_at":    at,
+				"updated_at": at,
+			})
+		if result.Error != nil {
+			return apperror.Wrap(result.Error, apperror.CodeInternal, "failed to mark order paid")
+		}
+
+		result = tx.
+			Model(&models.Order{}).
+			Where("id = ? AND status = ?", id, models.OrderStatusPending).
+			Updates(map[string]any{
+				"status":          models.OrderStatusConfirmed,
+				"hold_expires_at": nil,
+				"updated_at":      at,
+			})
+		if result.Error != nil {
+			return apperror.Wrap(result.Error, apperror.CodeInternal, "failed to confirm order")
+		}
+		confirmed = result.RowsAffected == 1
+		return nil
+	})
+	return confirmed, err
+}
+
+// Refund locks the order, lets fn take items out of it and saves the
+// changed items, order totals and payment together with the refund fn
+// returns, so concurrent refunds cannot take the same items twice.
+func (s *OrderStorage) Refund(ctx context.Context, id string, fn func(order *models.Order) (*models.Refund, error)) (*models.Refund, error) {
+	var refund *models.Refund
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Items").
+			Preload("Payments", orderedByCreation).
+			Where("id = ?", id).
+			First(&order).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "order not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get order")
+		}
+
+		refund, err = fn(&order)
+		if err != nil {
+			return err
+		}
+
+		for _, refunded := range refund.Items {
+			for _, item := range order.Items {
+				if item.ID != refunded.OrderItemID {
+					continue
+				}
+				err := tx.
+					Model(&models.OrderItem{}).
+					Where("id = ?", item.ID).
+					Updates(map[string]any{
+						"quantity":     item.Quantity,
+						"total_price":  item.TotalPrice,
+						"net_amount":   item.NetAmount,
+						"tax_amount":   item.TaxAmount,
+						"gross_amount": item.GrossAmount,
+						"lots":         item.Lots,
+					}).
+					Error
+				if err != nil {
+					return apperror.Wrap(err, apperror.CodeInternal, "failed to update order item")
+				}
+			}
+		}
+
+		err = tx.
+			Model(&models.Order{}).
+			Where("id = ?", id).
+			Updates(map[string]any{
+				"total_amount":    order.TotalAmount,
+				"net_amount":      order.NetAmount,
+				"tax_amount":      order.TaxAmount,
+				"payment_fee":     order.PaymentFee,
+				"shipping_cost":   order.ShippingCost,
+				"discounts":       order.Discounts,
+				"status":          order.Status,
+				"hold_expires_at": order.HoldExpiresAt,
+				"updated_at":      order.UpdatedAt,
+			}).
+			Error
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to update order")
+		}
+
+		for _, p := range order.Payments {
+			if refund.PaymentID == nil || p.ID != *refund.PaymentID {
+				continue
+			}
+			err := tx.
+				Model(&models.Payment{}).
+				Where("id = ?", p.ID).
+				Updates(map[string]any{
+					"refunded_amount": p.RefundedAmount,
+					"updated_at":      p.UpdatedAt,
+				}).
+				Error
+			if err != nil {
+				return apperror.Wrap(err, apperror.CodeInternal, "failed to update payment")
+			}
+		}
+
+		if err := tx.Create(refund).Error; err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to create refund")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// ListExpiredHolds returns pending orders whose stock hold expired at or
+// before now, oldest first.
+func (s *OrderStorage) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]*models.Order, error) {
+	var orders []*models.Order
+	err := s.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Items.Product", withDeleted).
+		Preload("Items.Variant", withDeleted).
+		Where("status = ? AND hold_expires_at <= ?", models.OrderStatusPending, now).
+		Order("hold_expires_at, id").
+		Limit(limit).
+		Find(&orders).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list expired order holds")
+	}
+	return orders, nil
+}
+
+// ExtendHold makes the hold of a pending order last until at least
+// until. It reports false when the order is no longer pending or its
+// hold has already expired; an order without a hold keeps none.
+func (s *OrderStorage) ExtendHold(ctx context.Context, id string, until, now time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Where("id = ? AND status = ?", id, models.OrderStatusPending).
+		Where("hold_expires_at IS NULL OR hold_expires_at > ?", now).
+		Updates(map[string]any{
+			"hold_expires_at": gorm.Expr("CASE WHEN hold_expires_at IS NULL THEN NULL ELSE GREATEST(hold_expires_at, ?) END", until),
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return false, apperror.Wrap(result.Error, apperror.CodeInternal, "failed to extend order hold")
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CancelExpiredHold cancels the order if it is still pending past its
+// hold, and reports whether it did. Orders confirmed or cancelled in the
+// meantime are left alone, so their stock is released at most once.
+func (s *OrderStorage) CancelExpiredHold(ctx context.Context, id string, now time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Where("id = ? AND status = ? AND hold_expires_at <= ?", id, models.OrderStatusPending, now).
+		Updates(map[string]any{
+			"status":     models.OrderStatusCancelled,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return false, apperror.Wrap(result.Error, apperror.CodeInternal, "failed to cancel order")
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func (s *OrderStorage) Delete(ctx context.Context, id string) error {
 	result := s.db.WithContext(ctx).Delete(&models.Order{}, "id = ?", id)
 
@@ -197,5 +453,63 @@ func (s *OrderStorage) GetOrderStatistics(ctx context.Context) (map[string]inter
 	}
 	stats["country_counts"] = countryMap
 
+	// Revenue and VAT of orders that were not cancelled, per currency and
+	// per delivery country, for invoicing and VAT returns.
+	var taxTotals []struct {
+		Country  string
+		Currency string
+		Net      int64
+		Tax      int64
+		Gross    int64
+	}
+	if err := s.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Select("delivery_info->>'country' as country, total_amount->>'currency' as currency, "+
+			"sum(net_amount) as net, sum(tax_amount) as tax, sum(net_amount + tax_amount) as gross").
+		Where("status <> ?", models.OrderStatusCancelled).
+		Group("delivery_info->>'country', total_amount->>'currency'").
+		Find(&taxTotals).Error; err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to get tax totals: "+err.Error())
+	}
+
+	revenueMap := make(map[string]map[string]int64)
+	countryTaxMap := make(map[string]map[string]map[string]int64)
+	for _, t := range taxTotals {
+		revenue, ok := revenueMap[t.Currency]
+		if !ok {
+			revenue = map[string]int64{"net": 0, "tax": 0, "gross": 0}
+			revenueMap[t.Currency] = revenue
+		}
+		revenue["net"] += t.Net
+		revenue["tax"] += t.Tax
+		revenue["gross"] += t.Gross
+
+		if countryTaxMap[t.Country] == nil {
+			countryTaxMap[t.Country] = make(map[string]map[string]int64)
+		}
+		countryTaxMap[t.Country][t.Currency] = map[string]int64{"net": t.Net, "tax": t.Tax, "gross": t.Gross}
+	}
+	stats["revenue"] = revenueMap
+	stats["tax_by_country"] = countryTaxMap
+
 	return stats, nil
+}
+
+// DailyRevenue returns the revenue of the orders that were not cancelled
+// per day and currency, for converting it at the rates of each day.
+func (s *OrderStorage) DailyRevenue(ctx context.Context) ([]models.DailyRevenue, error) {
+	var days []models.DailyRevenue
+	err := s.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Select("date_trunc('day', created_at AT TIME ZONE 'UTC') as day, total_amount->>'currency' as currency, "+
+			"sum(net_amount) as net, sum(tax_amount) as tax, sum(net_amount + tax_amount) as gross").
+		Where("status <> ?", models.OrderStatusCancelled).
+		Group("1, 2").
+		Order("1").
+		Find(&days).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get daily revenue")
+	}
+	return days, nil
 }
\ No newline at end of file
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+type paymentStorage struct {
+	db *gorm.DB
+}
+
+func NewPaymentStorage(db *gorm.DB) *paymentStorage {
+	return &paymentStorage{
+		db: db.Session(&gorm.Session{
+			PrepareStmt: true,
+		}),
+	}
+}
+
+func (s *paymentStorage) Create(ctx context.Context, p *models.Payment) error {
+	err := s.db.WithContext(ctx).Create(p).Error
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return apperror.Wrap(err, apperror.CodeNotFound, "order not found")
+	}
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to create payment")
+	}
+	return nil
+}
+
+func (s *paymentStorage) GetByID(ctx context.Context, id string) (*models.Payment, error) {
+	var p models.Payment
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.New(apperror.CodeNotFound, "payment not found")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get payment")
+	}
+	return &p, nil
+}
+
+// ListByOrder returns the payments of an order, oldest first.
+func (s *paymentStorage) ListByOrder(ctx context.Context, orderID string) ([]*models.Payment, error) {
+	var payments []*models.Payment
+	err := s.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at, id").
+		Find(&payments).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list payments")
+	}
+	return payments, nil
+}
+
+// Update applies fn to the payment, locked against concurrent callbacks,
+// and saves it.
+func (s *paymentStorage) Update(ctx context.Context, id string, fn func(p *models.Payment) error) (*models.Payment, error) {
+	var p models.Payment
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&p).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "payment not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get payment")
+		}
+
+		if err := fn(&p); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&p).Error; err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to update payment")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"caviar/internal/dto"
 	"caviar/internal/models"
@@ -27,17 +29,58 @@ func NewProductStorage(db *gorm.DB) *productStorage {
     }
 }
 
+// byWarehouseCode orders preloaded stock levels.
+func byWarehouseCode(db *gorm.DB) *gorm.DB {
+    return db.Order("warehouse_code")
+}
+
 func (s *productStorage) Create(ctx context.Context, p *models.Product) error {
     // Set ProductID for all variants
     for i := range p.Variants {
         p.Variants[i].ProductID = p.ID
     }
     
-    tx := s.db.WithContext(ctx).Create(p)
-    if tx.Error != nil {
-        return apperror.Wrap(tx.Error, apperror.CodeInternal, "failed to create product")
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        err := tx.Create(p).Error
+        if errors.Is(err, gorm.ErrDuplicatedKey) {
+            return apperror.Wrap(err, apperror.CodeConflict, "slug, SKU or barcode is already used by another product")
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to create product")
+        }
+
+        for i := range p.Variants {
+            if err := recordInitialStock(ctx, tx, &p.Variants[i]); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// recordInitialStock books the stock a variant was created with as a
+// receipt into its unlabelled lot in the default warehouse, so the
+// ledger and the lots start in balance.
+func recordInitialStock(ctx context.Context, tx *gorm.DB, v *models.Variant) error {
+    if v.Stock == 0 {
+        return nil
+    }
+    lot, err := findOrCreateLot(tx, &models.StockLot{
+        VariantID:        v.ID,
+        ProductID:        v.ProductID,
+        ReceivedQuantity: v.Stock,
+        Quantity:         v.Stock,
+    })
+    if err != nil {
+        return err
     }
-    return nil
+
+    m := models.NewStockMovement(ctx, v.ID, v.Stock, models.StockReasonReceipt, "", "initial stock")
+    m.ProductID = v.ProductID
+    m.LotID = &lot.ID
+    m.WarehouseID = &lot.WarehouseID
+    m.Balance = v.Stock
+    return recordMovement(tx, m)
 }
 
 func (s *productStorage) GetByID(ctx context.Context, id string) (*models.Product, error) {
@@ -45,6 +88,7 @@ func (s *productStorage) GetByID(ctx context.Context, id string) (*models.Produc
     err := s.db.
         WithContext(ctx).
         Preload("Variants").
+        Preload("Variants.Levels", byWarehouseCode).
         Where("id = ?", id).
         First(&p).
         Error
@@ -66,6 +110,7 @@ func (s *productStorage) GetBySlug(ctx context.Context, slug string) (*models.Pr
     err := s.db.
         WithContext(ctx).
         Preload("Variants").
+        Preload("Variants.Levels", byWarehouseCode).
         Where("slug = ?", slug).
         First(&p).
         Error
@@ -94,12 +139,15 @@ func (s *productStorage) List(ctx context.Context, filter *types.ProductFilter)
     }
     
     tx := s.db.WithContext(ctx)
+    if filter.Deleted {
+        tx = tx.Unscoped().Where("deleted_at IS NOT NULL")
+    }
     
     // Always apply filters, even if filter.IsEmpty() returns true
     tx = s.applyProductFilters(tx, filter)
     
     if filter.IncludeVariants {
-        tx = tx.Preload("Variants")
+        tx = tx.Preload("Variants").Preload("Variants.Levels", byWarehouseCode)
     }
     
     sortField := s.getSortField(filter.SortBy)
@@ -133,6 +181,9 @@ func (s *productStorage) applyProductFilters(tx *gorm.DB, filter *types.ProductF
 	if !filter.ShowAll {
 		tx = tx.Where("is_active = ?", true)
 	}
+    if filter.Status != "" {
+        tx = tx.Where("status = ?", filter.Status)
+    }
     
     if !filter.CreatedAfter.Equal(zeroTime) {
         tx = tx.Where("created_at >= ?", filter.CreatedAfter)
@@ -149,8 +200,12 @@ func (s *productStorage) applyProductFilters(tx *gorm.DB, filter *types.ProductF
     
     if filter.Search != "" {
         searchTerm := "%" + filter.Search + "%"
-        tx = tx.Where("name ILIKE ? OR subtitle ILIKE ? OR description ILIKE ?", 
-            searchTerm, searchTerm, searchTerm)
+        // SKUs and barcodes are matched too, so a scanned code finds its product
+        tx = tx.Where(
+            "name ILIKE ? OR subtitle ILIKE ? OR description ILIKE ? OR EXISTS ("+
+                "SELECT 1 FROM product_variants v WHERE v.product_id = products.id "+
+                "AND v.deleted_at IS NULL AND (v.sku ILIKE ? OR v.barcode = ?))",
+            searchTerm, searchTerm, searchTerm, searchTerm, strings.TrimSpace(filter.Search))
     }
     
     return tx
@@ -172,6 +227,13 @@ func (s *productStorage) getSortField(sortBy string) string {
 }
 
 func (s *productStorage) Update(ctx context.Context, input *dto.ProductUpdateDTO) error {
+    if input.Variants != nil {
+        return apperror.New(
+            apperror.CodeInvalidInput,
+            "variants cannot be changed through product update, use the /products/{id}/variants endpoints",
+        )
+    }
+
     existingProduct, err := s.GetByID(ctx, input.ID)
     if err != nil {
         return err
@@ -207,43 +269,36 @@ func (s *productStorage) Update(ctx context.Context, input *dto.ProductUpdateDTO
         }
     }
     
-    if input.Variants != nil {
-        var variants []models.Variant
-        for _, v := range input.Variants {
-            variant := models.Variant{
-                ID:        v.ID,
-                ProductID: input.ID,
-                Mass:      v.Mass,
-                Stock:     v.Stock,
-                Prices:    make(models.MoneyMap),
-            }
-            
-            for region, price := range v.Prices {
-                variant.Prices[region] = models.Money{
-                    Amount:   price.Amount,
-                    Currency: price.Currency,
-                }
-            }
-            
-            variants = append(variants, variant)
-        }
-        existingProduct.Variants = variants
-    }
-    
     existingProduct.UpdatedAt = time.Now().UTC()
     
-    tx := s.db.WithContext(ctx).Save(existingProduct)
+    // Only the editable columns are written: images and status are
+    // changed under a row lock by ModifyImages and ModifyStatus, and the
+    // copy read above may already be stale.
+    tx := s.db.
+        WithContext(ctx).
+        Model(existingProduct).
+        Select("slug", "name", "subtitle", "description", "details", "updated_at").
+        Updates(existingProduct)
     if tx.Error != nil {
         return apperror.Wrap(tx.Error, apperror.CodeInternal, "failed to update product")
     }
+    if tx.RowsAffected == 0 {
+        return apperror.New(
+            apperror.CodeNotFound,
+            fmt.Sprintf("product %s not found", input.ID),
+        )
+    }
     
     return nil
 }
 
+// Delete soft-deletes a product. Variants and order items keep pointing
+// at it, and Restore brings it back.
 func (s *productStorage) Delete(ctx context.Context, id string) error {
     tx := s.db.
         WithContext(ctx).
-        Delete(&models.Product{}, id)
+        Where("id = ?", id).
+        Delete(&models.Product{})
 
     if tx.Error != nil {
         return apperror.Wrap(tx.Error, apperror.CodeInternal, "failed to delete product")
@@ -257,6 +312,56 @@ func (s *productStorage) Delete(ctx context.Context, id string) error {
     return nil
 }
 
+// Restore undoes Delete. It fails when another product has taken the
+// slug in the meantime.
+func (s *productStorage) Restore(ctx context.Context, id string) error {
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        var p models.Product
+        err := tx.
+            Unscoped().
+            Clauses(clause.Locking{Strength: "UPDATE"}).
+            Where("id = ? AND deleted_at IS NOT NULL", id).
+            First(&p).
+            Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return apperror.New(apperror.CodeNotFound, fmt.Sprintf("deleted product %s not found", id))
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to retrieve deleted product")
+        }
+
+        var taken int64
+        err = tx.
+            Model(&models.Product{}).
+            Where("slug = ?", p.Slug).
+            Count(&taken).
+            Error
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to check product slug")
+        }
+        if taken > 0 {
+            return apperror.New(
+                apperror.CodeInvalidInput,
+                fmt.Sprintf("slug %q is used by another product, change it before restoring", p.Slug),
+            )
+        }
+
+        err = tx.
+            Unscoped().
+            Model(&models.Product{}).
+            Where("id = ?", id).
+            Updates(map[string]any{
+                "deleted_at": nil,
+                "updated_at": time.Now().UTC(),
+            }).
+            Error
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to restore product")
+        }
+        return nil
+    })
+}
+
 func (s *productStorage) GetVariantByID(ctx context.Context, productID, variantID string) (*models.Variant, error) {
     var variant models.Variant
     err := s.db.WithContext(ctx).
@@ -273,19 +378,359 @@ func (s *productStorage) GetVariantByID(ctx context.Context, productID, variantI
     return &variant, nil
 }
 
-func (s *productStorage) UpdateVariantStock(ctx context.Context, variantID string, stockChange int) error {
-    result := s.db.WithContext(ctx).
-        Model(&models.Variant{}).
-        Where("id = ?", variantID).
-        Update("stock", gorm.Expr("stock + ?", stockChange))
+// ModifyImages applies fn to the product's image list inside a
+// transaction, holding a row lock so concurrent uploads to the same
+// product do not overwrite each other.
+func (s *productStorage) ModifyImages(
+    ctx context.Context,
+    productID string,
+    fn func(images models.ProductImages) (models.ProductImages, error),
+) (models.ProductImages, error) {
+    var result models.ProductImages
 
-    if result.Error != nil {
-        return apperror.Wrap(result.Error, apperror.CodeInternal, "failed to update variant stock")
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        var p models.Product
+        err := tx.
+            Clauses(clause.Locking{Strength: "UPDATE"}).
+            Select("id", "images").
+            Where("id = ?", productID).
+            First(&p).
+            Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return apperror.New(apperror.CodeNotFound, fmt.Sprintf("product %s not found", productID))
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to lock product images")
+        }
+
+        images, err := fn(p.Images)
+        if err != nil {
+            return err
+        }
+
+        err = tx.
+            Model(&models.Product{}).
+            Where("id = ?", productID).
+            Updates(map[string]any{
+                "images":     images,
+                "updated_at": time.Now().UTC(),
+            }).
+            Error
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to update product images")
+        }
+
+        result = images
+        return nil
+    })
+    if err != nil {
+        return nil, err
     }
 
-    if result.RowsAffected == 0 {
-        return apperror.New(apperror.CodeNotFound, "variant not found")
+    return result, nil
+}
+
+// ModifyStatus applies fn to the product under a row lock and saves the
+// publishing fields it changed.
+func (s *productStorage) ModifyStatus(
+    ctx context.Context,
+    productID string,
+    fn func(product *models.Product) error,
+) (*models.Product, error) {
+    var p models.Product
+
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        err := tx.
+            Clauses(clause.Locking{Strength: "UPDATE"}).
+            Preload("Variants").
+            Where("id = ?", productID).
+            First(&p).
+            Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return apperror.New(apperror.CodeNotFound, fmt.Sprintf("product %s not found", productID))
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to lock product")
+        }
+
+        if err := fn(&p); err != nil {
+            return err
+        }
+
+        p.UpdatedAt = time.Now().UTC()
+        err = tx.
+            Model(&models.Product{}).
+            Where("id = ?", productID).
+            Updates(map[string]any{
+                "status":       p.Status,
+                "is_active":    p.IsActive,
+                "publish_at":   p.PublishAt,
+                "published_at": p.PublishedAt,
+                "archived_at":  p.ArchivedAt,
+                "updated_at":   p.UpdatedAt,
+            }).
+            Error
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to update product status")
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
     }
 
-    return nil
+    return &p, nil
+}
+
+// ListDueScheduled returns the IDs of scheduled products whose publish
+// time has passed.
+func (s *productStorage) ListDueScheduled(ctx context.Context, now time.Time) ([]string, error) {
+    var ids []string
+    err := s.db.
+        WithContext(ctx).
+        Model(&models.Product{}).
+        Where("status = ? AND publish_at <= ?", models.ProductStatusScheduled, now).
+        Order("publish_at").
+        Pluck("id", &ids).
+        Error
+    if err != nil {
+        return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list scheduled products")
+    }
+    return ids, nil
+}
+
+func (s *productStorage) CreateVariant(ctx context.Context, productID string, variant *models.Variant) error {
+    if _, err := s.GetByID(ctx, productID); err != nil {
+        return err
+    }
+
+    variant.ProductID = productID
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := checkVariantCodes(tx, variant); err != nil {
+            return err
+        }
+
+        err := tx.Create(variant).Error
+        if errors.Is(err, gorm.ErrDuplicatedKey) {
+            return apperror.Wrap(err, apperror.CodeConflict, "SKU or barcode is already used by another variant")
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to create variant")
+        }
+        return recordInitialStock(ctx, tx, variant)
+    })
+}
+
+// checkVariantCodes reports a conflict when another live variant already
+// has the SKU or barcode of v. The unique indexes are the final guard;
+// this only produces a clearer message.
+func checkVariantCodes(tx *gorm.DB, v *models.Variant) error {
+    check := func(column, value, label string) error {
+        if value == "" {
+            return nil
+        }
+        var count int64
+        q := tx.Model(&models.Variant{}).Where(column+" = ?", value)
+        if v.ID != "" {
+            q = q.Where("id <> ?", v.ID)
+        }
+        if err := q.Count(&count).Error; err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to check variant "+label)
+        }
+        if count > 0 {
+            return apperror.New(apperror.CodeConflict, fmt.Sprintf("%s %s is already used by another variant", label, value))
+        }
+        return nil
+    }
+
+    if err := check("sku", v.SKU, "SKU"); err != nil {
+        return err
+    }
+    return check("barcode", v.Barcode, "barcode")
+}
+
+// GetVariantByCode finds a live variant by SKU or barcode, whichever is
+// given, and returns it with its product.
+func (s *productStorage) GetVariantByCode(ctx context.Context, sku, barcode string) (*models.Product, *models.Variant, error) {
+    q := s.db.WithContext(ctx)
+    switch {
+    case sku != "":
+        q = q.Where("sku = ?", sku)
+    case barcode != "":
+        q = q.Where("barcode = ?", barcode)
+    default:
+        return nil, nil, apperror.New(apperror.CodeInvalidInput, "SKU or barcode is required")
+    }
+
+    var variant models.Variant
+    err := q.First(&variant).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, nil, apperror.New(apperror.CodeNotFound, "variant not found")
+    }
+    if err != nil {
+        return nil, nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get variant")
+    }
+
+    product, err := s.GetByID(ctx, variant.ProductID)
+    if err != nil {
+        return nil, nil, err
+    }
+    return product, &variant, nil
+}
+
+// UpdateVariant applies fn to the variant if its version still equals
+// version, and bumps the version. A mismatch means someone else (an
+// order, or another operator) changed the variant since it was read.
+func (s *productStorage) UpdateVariant(
+    ctx context.Context,
+    productID, variantID string,
+    version int,
+    fn func(variant *models.Variant) error,
+) (*models.Variant, error) {
+    var v models.Variant
+
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        err := tx.
+            Clauses(clause.Locking{Strength: "UPDATE"}).
+            Where("id = ? AND product_id = ?", variantID, productID).
+            First(&v).
+            Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return apperror.New(apperror.CodeNotFound, "variant not found")
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to get variant")
+        }
+        if v.Version != version {
+            return apperror.New(
+                apperror.CodeConflict,
+                fmt.Sprintf("variant was modified (version %d, expected %d), reload and try again", v.Version, version),
+            )
+        }
+
+        stockBefore := v.Stock
+        if err := fn(&v); err != nil {
+            return err
+        }
+        if err := checkVariantCodes(tx, &v); err != nil {
+            return err
+        }
+
+        // A stock change goes through moveStock, which adjusts the lots
+        // and bumps the version itself.
+        delta := v.Stock - stockBefore
+        if delta == 0 {
+            v.Version++
+        }
+        v.UpdatedAt = time.Now().UTC()
+        err = tx.
+            Model(&models.Variant{}).
+            Where("id = ?", variantID).
+            Updates(map[string]any{
+                "mass":                v.Mass,
+                "sku":                 v.SKU,
+                "barcode":             v.Barcode,
+                "low_stock_threshold": v.LowStockThreshold,
+                "prices":              v.Prices,
+                "version":             v.Version,
+                "updated_at":          v.UpdatedAt,
+            }).
+            Error
+        if errors.Is(err, gorm.ErrDuplicatedKey) {
+            return apperror.Wrap(err, apperror.CodeConflict, "SKU or barcode is already used by another variant")
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to update variant")
+        }
+
+        if delta != 0 {
+            m := models.NewStockMovement(ctx, v.ID, delta, models.StockReasonAdjustment, "", "stock set to "+strconv.Itoa(v.Stock))
+            if _, err := moveStock(tx, m); err != nil {
+                return err
+            }
+            v.Version++
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &v, nil
+}
+
+// DeleteVariant soft-deletes a variant so past orders still resolve it,
+// writing off its remaining stock. It refuses while unfinished orders
+// reference the variant or when it is the product's last one.
+func (s *productStorage) DeleteVariant(ctx context.Context, productID, variantID string) error {
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        var v models.Variant
+        err := tx.
+            Clauses(clause.Locking{Strength: "UPDATE"}).
+            Where("id = ? AND product_id = ?", variantID, productID).
+            First(&v).
+            Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return apperror.New(apperror.CodeNotFound, "variant not found")
+        }
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to get variant")
+        }
+
+        var remaining int64
+        err = tx.
+            Model(&models.Variant{}).
+            Where("product_id = ? AND id <> ?", productID, variantID).
+            Count(&remaining).
+            Error
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to count variants")
+        }
+        if remaining == 0 {
+            return apperror.New(apperror.CodeInvalidInput, "a product must keep at least one variant")
+        }
+
+        var openOrders int64
+        err = tx.
+            Table("order_items").
+            Joins("JOIN orders ON orders.id = order_items.order_id").
+            Where("order_items.variant_id = ? AND orders.status IN ?", variantID, models.OpenOrderStatuses).
+            Distinct("orders.id").
+            Count(&openOrders).
+            Error
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to check orders for variant")
+        }
+        if openOrders > 0 {
+            return apperror.New(
+                apperror.CodeConflict,
+                fmt.Sprintf("variant is referenced by %d open order(s)", openOrders),
+            )
+        }
+
+        // Whatever is left on the shelf is written off lot by lot, so the
+        // ledger and the lots of the deleted variant end at zero.
+        var lots []*models.StockLot
+        err = tx.
+            Where("variant_id = ? AND quantity > 0", variantID).
+            Order("expires_at NULLS LAST, created_at, id").
+            Find(&lots).
+            Error
+        if err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to list stock lots")
+        }
+        for _, lot := range lots {
+            lotID := lot.ID
+            m := models.NewStockMovement(ctx, variantID, -lot.Quantity, models.StockReasonWriteOff, "", "variant deleted")
+            m.LotID = &lotID
+            if _, err := moveStock(tx, m); err != nil {
+                return err
+            }
+        }
+
+        if err := tx.Delete(&v).Error; err != nil {
+            return apperror.Wrap(err, apperror.CodeInternal, "failed to delete variant")
+        }
+        return nil
+    })
 }
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+type promoStorage struct {
+	db *gorm.DB
+}
+
+func NewPromoStorage(db *gorm.DB) *promoStorage {
+	return &promoStorage{
+		db: db.Session(&gorm.Session{
+			PrepareStmt: true,
+		}),
+	}
+}
+
+func (s *promoStorage) List(ctx context.Context) ([]*models.PromoCode, error) {
+	var promos []*models.PromoCode
+	err := s.db.WithContext(ctx).
+		Order("created_at DESC, id").
+		Find(&promos).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list promo codes")
+	}
+	return promos, nil
+}
+
+func (s *promoStorage) GetByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	var p models.PromoCode
+	err := s.db.WithContext(ctx).Where("code = ?", code).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.New(apperror.CodeNotFound, "promo code not found")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get promo code")
+	}
+	return &p, nil
+}
+
+func (s *promoStorage) Create(ctx context.Context, p *models.PromoCode) error {
+	err := s.db.WithContext(ctx).Create(p).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperror.Wrap(err, apperror.CodeConflict, "promo code "+p.Code+" already exists")
+	}
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to create promo code")
+	}
+	return nil
+}
+
+// Update applies fn to the promo code and saves it.
+func (s *promoStorage) Update(ctx context.Context, id string, fn func(p *models.PromoCode) error) (*models.PromoCode, error) {
+	var p models.PromoCode
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&p).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "promo code not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get promo code")
+		}
+
+		if err := fn(&p); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&p).Error; err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to update promo code")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// checkPromoLimits fails if the promo code of the redemption has no uses
+// left. The code is locked until the transaction ends, so concurrent
+// orders cannot both take its last use. Redemptions of cancelled orders
+// do not count.
+func checkPromoLimits(tx *gorm.DB, r *models.PromoRedemption) error {
+	var p models.PromoCode
+	err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", r.PromoCodeID).
+		First(&p).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.New(apperror.CodeNotFound, "promo code not found")
+	}
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to lock promo code")
+	}
+
+	if p.UsageLimit > 0 {
+		used, err := countRedemptions(tx, p.ID, "")
+		if err != nil {
+			return err
+		}
+		if used >= int64(p.UsageLimit) {
+			return apperror.New(apperror.CodeConflict, "promo code "+p.Code+" has been used up")
+		}
+	}
+	if p.PerCustomerLimit > 0 {
+		used, err := countRedemptions(tx, p.ID, r.CustomerPhone)
+		if err != nil {
+			return err
+		}
+		if used >= int64(p.PerCustomerLimit) {
+			return apperror.New(apperror.CodeConflict, "promo code "+p.Code+" was already used by this customer")
+		}
+	}
+	return nil
+}
+
+// countRedemptions counts the redemptions of a promo code, by one
+// customer if phone is set, whose order was not cancelled.
+func countRedemptions(tx *gorm.DB, promoCodeID, phone string) (int64, error) {
+	query := tx.
+		Table("promo_redemptions AS r").
+		Joins("JOIN orders o ON o.id = r.order_id").
+		Where("r.promo_code_id = ?", promoCodeID).
+		Where("o.status <> ?", models.OrderStatusCancelled)
+	if phone != "" {
+		query = query.Where("r.customer_phone = ?", phone)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, apperror.Wrap(err, apperror.CodeInternal, "failed to count promo code redemptions")
+	}
+	return count, nil
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+type shippingStorage struct {
+	db *gorm.DB
+}
+
+func NewShippingStorage(db *gorm.DB) *shippingStorage {
+	return &shippingStorage{
+		db: db.Session(&gorm.Session{
+			PrepareStmt: true,
+		}),
+	}
+}
+
+func (s *shippingStorage) ListZones(ctx context.Context) ([]*models.ShippingZone, error) {
+	var zones []*models.ShippingZone
+	if err := s.db.WithContext(ctx).Order("code").Find(&zones).Error; err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list shipping zones")
+	}
+	return zones, nil
+}
+
+func (s *shippingStorage) CreateZone(ctx context.Context, z *models.ShippingZone) error {
+	err := s.db.WithContext(ctx).Create(z).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperror.Wrap(err, apperror.CodeConflict, "shipping zone code "+z.Code+" is already used")
+	}
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to create shipping zone")
+	}
+	return nil
+}
+
+// UpdateZone applies fn to the zone and saves it.
+func (s *shippingStorage) UpdateZone(ctx context.Context, id string, fn func(z *models.ShippingZone) error) (*models.ShippingZone, error) {
+	var z models.ShippingZone
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&z).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "shipping zone not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get shipping zone")
+		}
+
+		if err := fn(&z); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&z).Error; err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to update shipping zone")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &z, nil
+}
+
+// ListRates returns the rates of a zone, or of every zone if zoneID is
+// empty, by zone, delivery type and weight.
+func (s *shippingStorage) ListRates(ctx context.Context, zoneID string) ([]*models.ShippingRate, error) {
+	query := s.db.WithContext(ctx).
+		Preload("Zone").
+		Order("zone_id, delivery_type, max_weight = 0, max_weight")
+	if zoneID != "" {
+		query = query.Where("zone_id = ?", zoneID)
+	}
+
+	var rates []*models.ShippingRate
+	if err := query.Find(&rates).Error; err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list shipping rates")
+	}
+	return rates, nil
+}
+
+// ListActiveRates returns the active rates of a delivery type with
+// their zones, for quoting.
+func (s *shippingStorage) ListActiveRates(ctx context.Context, deliveryType models.DeliveryType) ([]*models.ShippingRate, error) {
+	var rates []*models.ShippingRate
+	err := s.db.WithContext(ctx).
+		Preload("Zone").
+		Where("delivery_type = ? AND is_active", deliveryType).
+		Find(&rates).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list shipping rates")
+	}
+	return rates, nil
+}
+
+func (s *shippingStorage) CreateRate(ctx context.Context, r *models.ShippingRate) error {
+	err := s.db.WithContext(ctx).Omit("Zone").Create(r).Error
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return apperror.Wrap(err, apperror.CodeNotFound, "shipping zone not found")
+	}
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to create shipping rate")
+	}
+	return nil
+}
+
+// UpdateRate applies fn to the rate and saves it.
+func (s *shippingStorage) UpdateRate(ctx context.Context, id string, fn func(r *models.ShippingRate) error) (*models.ShippingRate, error) {
+	var r models.ShippingRate
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&r).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "shipping rate not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get shipping rate")
+		}
+
+		if err := fn(&r); err != nil {
+			return err
+		}
+
+		if err := tx.Omit("Zone").Save(&r).Error; err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to update shipping rate")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (s *shippingStorage) DeleteRate(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Delete(&models.ShippingRate{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Wrap(result.Error, apperror.CodeInternal, "failed to delete shipping rate")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.New(apperror.CodeNotFound, "shipping rate not found")
+	}
+	return nil
+}
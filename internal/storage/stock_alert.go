@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+// ClaimLowStockVariants marks live variants of unarchived products that
+// are at or below their low-stock threshold and have not been alerted
+// yet as alerted at the given time, and returns them. The mark is set
+// and checked in one statement, so when several instances check at once
+// each variant is claimed by only one of them.
+func (s *productStorage) ClaimLowStockVariants(ctx context.Context, at time.Time) ([]*models.Variant, error) {
+	var ids []string
+	err := s.db.WithContext(ctx).Raw(`
+		UPDATE product_variants v
+		SET low_stock_alerted_at = ?
+		FROM products p
+		WHERE p.id = v.product_id
+		  AND p.deleted_at IS NULL
+		  AND p.status <> ?
+		  AND v.deleted_at IS NULL
+		  AND v.low_stock_threshold > 0
+		  AND v.stock <= v.low_stock_threshold
+		  AND v.low_stock_alerted_at IS NULL
+		RETURNING v.id`, at, models.ProductStatusArchived).
+		Scan(&ids).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to claim low stock variants")
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var variants []*models.Variant
+	err = s.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Order("stock, id").
+		Find(&variants).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list low stock variants")
+	}
+	return variants, nil
+}
+
+// ReleaseLowStockAlerts undoes a claim made at the given time whose
+// alert could not be sent, so the next check claims the variants again.
+func (s *productStorage) ReleaseLowStockAlerts(ctx context.Context, variantIDs []string, at time.Time) error {
+	err := s.db.WithContext(ctx).
+		Model(&models.Variant{}).
+		Where("id IN ? AND low_stock_alerted_at = ?", variantIDs, at).
+		UpdateColumn("low_stock_alerted_at", gorm.Expr("NULL")).
+		Error
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to release low stock alerts")
+	}
+	return nil
+}
+
+// ClearRecoveredLowStock re-arms the alert of variants whose stock is
+// back above the threshold (or whose threshold was removed) and returns
+// how many there were.
+func (s *productStorage) ClearRecoveredLowStock(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Model(&models.Variant{}).
+		Where("low_stock_alerted_at IS NOT NULL").
+		Where("low_stock_threshold = 0 OR stock > low_stock_threshold").
+		UpdateColumn("low_stock_alerted_at", gorm.Expr("NULL"))
+	if result.Error != nil {
+		return 0, apperror.Wrap(result.Error, apperror.CodeInternal, "failed to clear low stock alerts")
+	}
+	return result.RowsAffected, nil
+}
+
+// ClaimExpiringLots marks lots with stock left that expire on or before
+// the given date and have not been alerted yet as alerted at the given
+// time, and returns them. Like ClaimLowStockVariants, each lot is
+// claimed by only one instance.
+func (s *productStorage) ClaimExpiringLots(ctx context.Context, before, at time.Time) ([]*models.StockLot, error) {
+	var ids []string
+	err := s.db.WithContext(ctx).Raw(`
+		UPDATE stock_lots
+		SET expiry_alerted_at = ?
+		WHERE quantity > 0
+		  AND expires_at <= ?
+		  AND expiry_alerted_at IS NULL
+		RETURNING id`, at, before.Format(models.DateLayout)).
+		Scan(&ids).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to claim expiring lots")
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var lots []*models.StockLot
+	err = s.db.WithContext(ctx).
+		Preload("Variant", withDeleted).
+		Preload("Product", withDeleted).
+		Preload("Warehouse").
+		Where("id IN ?", ids).
+		Order("expires_at, product_id, lot_number").
+		Find(&lots).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list expiring lots")
+	}
+	return lots, nil
+}
+
+// ReleaseLotExpiryAlerts undoes a claim made at the given time whose
+// alert could not be sent, so the next check claims the lots again.
+func (s *productStorage) ReleaseLotExpiryAlerts(ctx context.Context, lotIDs []string, at time.Time) error {
+	err := s.db.WithContext(ctx).
+		Model(&models.StockLot{}).
+		Where("id IN ? AND expiry_alerted_at = ?", lotIDs, at).
+		UpdateColumn("expiry_alerted_at", gorm.Expr("NULL")).
+		Error
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to release lot expiry alerts")
+	}
+	return nil
+}
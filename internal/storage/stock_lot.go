@@ -0,0 +1,331 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+// lotPart is the share of a stock movement booked against one lot.
+type lotPart struct {
+	lot   *models.StockLot
+	delta int
+}
+
+// allocateLots decides which lots a movement of v's stock is booked
+// against. A movement naming a lot uses only that lot. Otherwise stock
+// coming in goes to the unlabelled lot of m's warehouse (the default one
+// when not set), and stock going out is taken first expired, first out
+// from m's warehouse or from all of them; order reservations skip
+// expired lots.
+func allocateLots(tx *gorm.DB, v *models.Variant, m *models.StockMovement) ([]lotPart, error) {
+	if m.LotID != nil {
+		var lot models.StockLot
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND variant_id = ?", *m.LotID, v.ID).
+			First(&lot).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.CodeNotFound, "lot not found for this variant")
+		}
+		if err != nil {
+			return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get stock lot")
+		}
+		if m.WarehouseID != nil && *m.WarehouseID != lot.WarehouseID {
+			return nil, apperror.New(apperror.CodeInvalidInput, "lot is in another warehouse")
+		}
+		if lot.Quantity+m.Delta < 0 {
+			return nil, apperror.New(apperror.CodeInvalidInput,
+				fmt.Sprintf("insufficient stock in lot %s: available %d", lotLabel(&lot), lot.Quantity))
+		}
+		return []lotPart{{lot: &lot, delta: m.Delta}}, nil
+	}
+
+	if m.WarehouseID != nil {
+		if err := checkWarehouse(tx, *m.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.Delta > 0 {
+		warehouseID := ""
+		if m.WarehouseID != nil {
+			warehouseID = *m.WarehouseID
+		}
+		lot, err := findOrCreateLot(tx, &models.StockLot{
+			VariantID:   v.ID,
+			ProductID:   v.ProductID,
+			WarehouseID: warehouseID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []lotPart{{lot: lot, delta: m.Delta}}, nil
+	}
+
+	q := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("variant_id = ? AND quantity > 0", v.ID)
+	if m.WarehouseID != nil {
+		q = q.Where("warehouse_id = ?", *m.WarehouseID)
+	}
+	if m.Reason == models.StockReasonOrderReserve {
+		q = q.Where("expires_at IS NULL OR expires_at >= CURRENT_DATE")
+	}
+
+	var lots []*models.StockLot
+	if err := q.Order("expires_at NULLS LAST, created_at, id").Find(&lots).Error; err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list stock lots")
+	}
+
+	need := -m.Delta
+	parts := make([]lotPart, 0, 1)
+	for _, lot := range lots {
+		if need == 0 {
+			break
+		}
+		take := min(lot.Quantity, need)
+		parts = append(parts, lotPart{lot: lot, delta: -take})
+		need -= take
+	}
+	if need > 0 {
+		if m.Reason == models.StockReasonOrderReserve {
+			return nil, apperror.New(apperror.CodeInvalidInput, "insufficient stock: the remaining stock has expired")
+		}
+		if m.WarehouseID != nil {
+			return nil, apperror.New(apperror.CodeInvalidInput, "insufficient stock in this warehouse")
+		}
+		return nil, apperror.New(apperror.CodeInvalidInput, "insufficient stock")
+	}
+	return parts, nil
+}
+
+// findOrCreateLot returns the lot of the variant with lot's warehouse and
+// number, locked for update, creating it from lot when it does not exist
+// yet. An empty warehouse stands for the default warehouse.
+func findOrCreateLot(tx *gorm.DB, lot *models.StockLot) (*models.StockLot, error) {
+	if lot.WarehouseID == "" {
+		id, err := defaultWarehouseID(tx)
+		if err != nil {
+			return nil, err
+		}
+		lot.WarehouseID = id
+	}
+	if lot.ID == "" {
+		lot.ID = uuid.New().String()
+	}
+
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(lot).Error
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return nil, apperror.Wrap(err, apperror.CodeNotFound, "warehouse not found")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to create stock lot")
+	}
+
+	var existing models.StockLot
+	err = tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("variant_id = ? AND warehouse_id = ? AND lot_number = ?", lot.VariantID, lot.WarehouseID, lot.LotNumber).
+		First(&existing).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get stock lot")
+	}
+	return &existing, nil
+}
+
+func lotLabel(lot *models.StockLot) string {
+	if lot.LotNumber == "" {
+		return "(unlabelled)"
+	}
+	return lot.LotNumber
+}
+
+// CreateLot stores a new lot for its variant and books m, its receipt,
+// against it. A lot without a warehouse goes to the default warehouse.
+func (s *productStorage) CreateLot(ctx context.Context, lot *models.StockLot, m *models.StockMovement) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var v models.Variant
+		err := tx.Where("id = ?", lot.VariantID).First(&v).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "variant not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get variant")
+		}
+
+		if lot.WarehouseID == "" {
+			if lot.WarehouseID, err = defaultWarehouseID(tx); err != nil {
+				return err
+			}
+		} else if err := checkWarehouse(tx, lot.WarehouseID); err != nil {
+			return err
+		}
+
+		lot.ProductID = v.ProductID
+		err = tx.Create(lot).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return apperror.Wrap(err, apperror.CodeConflict, fmt.Sprintf("lot %s already exists for this variant in the warehouse", lot.LotNumber))
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to create stock lot")
+		}
+
+		m.LotID = &lot.ID
+		if _, err := moveStock(tx, m); err != nil {
+			return err
+		}
+		lot.ReceivedQuantity = m.Delta
+		lot.Quantity = m.Delta
+		return nil
+	})
+}
+
+// TransferStock moves stock of a variant from one warehouse to another.
+// Every source lot touched gets a counterpart with the same number and
+// dates in the destination warehouse, so expiry dates travel with the
+// stock.
+func (s *productStorage) TransferStock(ctx context.Context, t *models.StockTransfer) ([]*models.StockMovement, error) {
+	var movements []*models.StockMovement
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkWarehouse(tx, t.ToWarehouseID); err != nil {
+			return err
+		}
+
+		out := models.NewStockMovement(ctx, t.VariantID, -t.Quantity, models.StockReasonTransfer, t.ID, t.Note)
+		out.WarehouseID = &t.FromWarehouseID
+		out.LotID = t.LotID
+		outs, err := moveStock(tx, out)
+		if err != nil {
+			return err
+		}
+		movements = append(movements, outs...)
+
+		for _, o := range outs {
+			var source models.StockLot
+			if err := tx.Where("id = ?", *o.LotID).First(&source).Error; err != nil {
+				return apperror.Wrap(err, apperror.CodeInternal, "failed to get stock lot")
+			}
+
+			target, err := findOrCreateLot(tx, &models.StockLot{
+				VariantID:   source.VariantID,
+				ProductID:   source.ProductID,
+				WarehouseID: t.ToWarehouseID,
+				LotNumber:   source.LotNumber,
+				PackedAt:    source.PackedAt,
+				ExpiresAt:   source.ExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			in := models.NewStockMovement(ctx, t.VariantID, -o.Delta, models.StockReasonTransfer, t.ID, t.Note)
+			in.LotID = &target.ID
+			ins, err := moveStock(tx, in)
+			if err != nil {
+				return err
+			}
+			movements = append(movements, ins...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// AvailableStock returns the unexpired stock of each variant in the
+// warehouse, i.e. what an order shipped from there could take.
+func (s *productStorage) AvailableStock(ctx context.Context, warehouseID string, variantIDs []string) (map[string]int, error) {
+	var rows []struct {
+		VariantID string
+		Quantity  int
+	}
+	err := s.db.WithContext(ctx).
+		Model(&models.StockLot{}).
+		Select("variant_id, SUM(quantity) AS quantity").
+		Where("warehouse_id = ? AND variant_id IN ? AND quantity > 0", warehouseID, variantIDs).
+		Where("expires_at IS NULL OR expires_at >= CURRENT_DATE").
+		Group("variant_id").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get available stock")
+	}
+
+	result := make(map[string]int, len(rows))
+	for _, r := range rows {
+		result[r.VariantID] = r.Quantity
+	}
+	return result, nil
+}
+
+// defaultWarehouseID returns the warehouse that receives stock when no
+// warehouse is given.
+func defaultWarehouseID(tx *gorm.DB) (string, error) {
+	var id string
+	err := tx.Model(&models.Warehouse{}).Where("is_default").Limit(1).Pluck("id", &id).Error
+	if err != nil {
+		return "", apperror.Wrap(err, apperror.CodeInternal, "failed to get default warehouse")
+	}
+	if id == "" {
+		return "", apperror.New(apperror.CodeInternal, "no default warehouse is configured")
+	}
+	return id, nil
+}
+
+// checkWarehouse reports a not-found error unless the warehouse exists.
+func checkWarehouse(tx *gorm.DB, id string) error {
+	var count int64
+	if err := tx.Model(&models.Warehouse{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to get warehouse")
+	}
+	if count == 0 {
+		return apperror.New(apperror.CodeNotFound, "warehouse not found")
+	}
+	return nil
+}
+
+// ListLots returns the lots of a variant in all warehouses in FEFO order. Used-up lots are
+// left out unless includeEmpty is set.
+func (s *productStorage) ListLots(ctx context.Context, variantID string, includeEmpty bool) ([]*models.StockLot, error) {
+	q := s.db.WithContext(ctx).Preload("Warehouse").Where("variant_id = ?", variantID)
+	if !includeEmpty {
+		q = q.Where("quantity > 0")
+	}
+
+	var lots []*models.StockLot
+	if err := q.Order("expires_at NULLS LAST, created_at, id").Find(&lots).Error; err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list stock lots")
+	}
+	return lots, nil
+}
+
+// ListExpiringLots returns lots with stock left that expire on or before
+// the given date, already expired ones included, soonest first.
+func (s *productStorage) ListExpiringLots(ctx context.Context, before time.Time) ([]*models.StockLot, error) {
+	var lots []*models.StockLot
+	err := s.db.WithContext(ctx).
+		Preload("Variant", withDeleted).
+		Preload("Product", withDeleted).
+		Preload("Warehouse").
+		Where("quantity > 0 AND expires_at <= ?", before.Format(models.DateLayout)).
+		Order("expires_at, product_id, lot_number").
+		Find(&lots).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list expiring lots")
+	}
+	return lots, nil
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/internal/types"
+	"caviar/pkg/apperror"
+)
+
+// MoveStock changes a variant's stock by m.Delta and records it in the
+// ledger, atomically. Stock never goes below zero. The change is booked
+// against lots (see allocateLots), so it may be split into one movement
+// per lot; those are returned.
+func (s *productStorage) MoveStock(ctx context.Context, m *models.StockMovement) ([]*models.StockMovement, error) {
+	var movements []*models.StockMovement
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		movements, err = moveStock(tx, m)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+func moveStock(tx *gorm.DB, m *models.StockMovement) ([]*models.StockMovement, error) {
+	var v models.Variant
+	err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", m.VariantID).
+		First(&v).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.New(apperror.CodeNotFound, "variant not found")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get variant")
+	}
+	if v.Stock+m.Delta < 0 {
+		return nil, apperror.New(apperror.CodeInvalidInput, "insufficient stock")
+	}
+
+	parts, err := allocateLots(tx, &v, m)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	movements := make([]*models.StockMovement, 0, len(parts))
+	balance := v.Stock
+	for i, part := range parts {
+		updates := map[string]any{
+			"quantity":   gorm.Expr("quantity + ?", part.delta),
+			"updated_at": now,
+		}
+		if m.Reason == models.StockReasonReceipt {
+			updates["received_quantity"] = gorm.Expr("received_quantity + ?", part.delta)
+		}
+		err := tx.Model(&models.StockLot{}).Where("id = ?", part.lot.ID).Updates(updates).Error
+		if err != nil {
+			return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to update stock lot")
+		}
+
+		entry := *m
+		if i > 0 {
+			entry.ID = uuid.New().String()
+		}
+		lotID, warehouseID := part.lot.ID, part.lot.WarehouseID
+		entry.LotID = &lotID
+		entry.WarehouseID = &warehouseID
+		entry.ProductID = v.ProductID
+		entry.Delta = part.delta
+		balance += part.delta
+		entry.Balance = balance
+		if err := recordMovement(tx, &entry); err != nil {
+			return nil, err
+		}
+		movements = append(movements, &entry)
+	}
+
+	err = tx.
+		Model(&models.Variant{}).
+		Where("id = ?", v.ID).
+		Updates(map[string]any{
+			"stock":      gorm.Expr("stock + ?", m.Delta),
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": now,
+		}).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to update variant stock")
+	}
+
+	return movements, nil
+}
+
+// recordMovement only writes the ledger entry; the caller has already
+// changed the stock in the same transaction.
+func recordMovement(tx *gorm.DB, m *models.StockMovement) error {
+	if err := tx.Create(m).Error; err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to record stock movement")
+	}
+	return nil
+}
+
+func (s *productStorage) ListStockMovements(ctx context.Context, filter *types.StockMovementFilter) ([]*models.StockMovement, int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.StockMovement{})
+
+	if filter.VariantID != "" {
+		query = query.Where("variant_id = ?", filter.VariantID)
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+	if filter.WarehouseID != "" {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.LotID != "" {
+		query = query.Where("lot_id = ?", filter.LotID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, apperror.Wrap(err, apperror.CodeInternal, "failed to count stock movements")
+	}
+
+	var movements []*models.StockMovement
+	err := query.
+		Order("created_at DESC, id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&movements).
+		Error
+	if err != nil {
+		return nil, 0, apperror.Wrap(err, apperror.CodeInternal, "failed to list stock movements")
+	}
+
+	return movements, total, nil
+}
+
+// ReconcileStock returns every variant, deleted ones included, whose
+// stock does not equal the sum of its ledger or of its lots.
+func (s *productStorage) ReconcileStock(ctx context.Context) ([]models.StockDiscrepancy, error) {
+	var result []models.StockDiscrepancy
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT v.id AS variant_id, v.product_id, v.sku, v.stock,
+			       COALESCE((SELECT SUM(m.delta) FROM stock_movements m WHERE m.variant_id = v.id), 0) AS ledger_stock,
+			       COALESCE((SELECT SUM(l.quantity) FROM stock_lots l WHERE l.variant_id = v.id), 0) AS lot_stock
+			FROM product_variants v
+		) t
+		WHERE stock <> ledger_stock OR stock <> lot_stock
+		ORDER BY product_id, variant_id`).
+		Scan(&result).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to reconcile stock")
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+type taxStorage struct {
+	db *gorm.DB
+}
+
+func NewTaxStorage(db *gorm.DB) *taxStorage {
+	return &taxStorage{
+		db: db.Session(&gorm.Session{
+			PrepareStmt: true,
+		}),
+	}
+}
+
+func (s *taxStorage) List(ctx context.Context) ([]*models.TaxRate, error) {
+	var rates []*models.TaxRate
+	if err := s.db.WithContext(ctx).Order("country").Find(&rates).Error; err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list tax rates")
+	}
+	return rates, nil
+}
+
+func (s *taxStorage) GetByCountry(ctx context.Context, country string) (*models.TaxRate, error) {
+	var t models.TaxRate
+	err := s.db.WithContext(ctx).Where("country = ?", country).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.New(apperror.CodeNotFound, "tax rate not found")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get tax rate")
+	}
+	return &t, nil
+}
+
+func (s *taxStorage) Create(ctx context.Context, t *models.TaxRate) error {
+	err := s.db.WithContext(ctx).Create(t).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperror.Wrap(err, apperror.CodeConflict, "tax rate of "+t.Country+" already exists")
+	}
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to create tax rate")
+	}
+	return nil
+}
+
+// Update applies fn to the tax rate and saves it.
+func (s *taxStorage) Update(ctx context.Context, id string, fn func(t *models.TaxRate) error) (*models.TaxRate, error) {
+	var t models.TaxRate
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&t).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "tax rate not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get tax rate")
+		}
+
+		if err := fn(&t); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&t).Error; err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to update tax rate")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *taxStorage) Delete(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Delete(&models.TaxRate{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Wrap(result.Error, apperror.CodeInternal, "failed to delete tax rate")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.New(apperror.CodeNotFound, "tax rate not found")
+	}
+	return nil
+}
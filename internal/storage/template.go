@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+type templateStorage struct {
+	db *gorm.DB
+}
+
+func NewTemplateStorage(db *gorm.DB) *templateStorage {
+	return &templateStorage{
+		db: db.Session(&gorm.Session{
+			PrepareStmt: true,
+		}),
+	}
+}
+
+func (s *templateStorage) Get(ctx context.Context, event, channel, locale string) (*models.NotificationTemplate, error) {
+	var t models.NotificationTemplate
+	err := s.db.WithContext(ctx).
+		Where("event = ? AND channel = ? AND locale = ?", event, channel, locale).
+		First(&t).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.New(
+			apperror.CodeNotFound,
+			fmt.Sprintf("template %s/%s/%s not found", event, channel, locale),
+		)
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to retrieve notification template")
+	}
+	return &t, nil
+}
+
+func (s *templateStorage) List(ctx context.Context) ([]*models.NotificationTemplate, error) {
+	var templates []*models.NotificationTemplate
+	err := s.db.WithContext(ctx).
+		Order("event, channel, locale").
+		Find(&templates).Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list notification templates")
+	}
+	return templates, nil
+}
+
+// Upsert stores t, replacing the body of an existing override. t is
+// updated with the stored row, whose ID and CreatedAt are kept.
+func (s *templateStorage) Upsert(ctx context.Context, t *models.NotificationTemplate) error {
+	tx := s.db.WithContext(ctx).
+		Clauses(clause.Returning{}, clause.OnConflict{
+			Columns: []clause.Column{{Name: "event"}, {Name: "channel"}, {Name: "locale"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"body":       t.Body,
+				"updated_at": time.Now().UTC(),
+			}),
+		}).
+		Create(t)
+	if tx.Error != nil {
+		return apperror.Wrap(tx.Error, apperror.CodeInternal, "failed to save notification template")
+	}
+	return nil
+}
+
+func (s *templateStorage) Delete(ctx context.Context, event, channel, locale string) error {
+	tx := s.db.WithContext(ctx).
+		Where("event = ? AND channel = ? AND locale = ?", event, channel, locale).
+		Delete(&models.NotificationTemplate{})
+
+	if tx.Error != nil {
+		return apperror.Wrap(tx.Error, apperror.CodeInternal, "failed to delete notification template")
+	}
+	if tx.RowsAffected == 0 {
+		return apperror.New(
+			apperror.CodeNotFound,
+			fmt.Sprintf("template %s/%s/%s not found", event, channel, locale),
+		)
+	}
+	return nil
+}
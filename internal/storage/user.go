@@ -143,6 +143,23 @@ func (s *userStorage) UpdateTelegramID(ctx context.Context, userID string, teleg
 	return nil
 }
 
+func (s *userStorage) UpdateLanguage(ctx context.Context, telegramID string, language string) error {
+	result := s.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("telegram_id = ?", telegramID).
+		Update("language", language)
+	
+	if result.Error != nil {
+		return apperror.Wrap(result.Error, apperror.CodeInternal, "failed to update user language")
+	}
+	
+	if result.RowsAffected == 0 {
+		return apperror.New(apperror.CodeNotFound, "user not found")
+	}
+	
+	return nil
+}
+
 func (s *userStorage) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	
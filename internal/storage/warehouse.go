@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"caviar/internal/models"
+	"caviar/pkg/apperror"
+)
+
+type warehouseStorage struct {
+	db *gorm.DB
+}
+
+func NewWarehouseStorage(db *gorm.DB) *warehouseStorage {
+	return &warehouseStorage{
+		db: db.Session(&gorm.Session{
+			PrepareStmt: true,
+		}),
+	}
+}
+
+func (s *warehouseStorage) List(ctx context.Context) ([]*models.Warehouse, error) {
+	var warehouses []*models.Warehouse
+	err := s.db.WithContext(ctx).
+		Order("priority, code").
+		Find(&warehouses).
+		Error
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to list warehouses")
+	}
+	return warehouses, nil
+}
+
+func (s *warehouseStorage) GetByID(ctx context.Context, id string) (*models.Warehouse, error) {
+	var w models.Warehouse
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&w).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.New(apperror.CodeNotFound, "warehouse not found")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternal, "failed to get warehouse")
+	}
+	return &w, nil
+}
+
+// Create stores w. When w is the new default, the previous default
+// stops being one.
+func (s *warehouseStorage) Create(ctx context.Context, w *models.Warehouse) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if w.IsDefault {
+			if err := clearDefaultWarehouse(tx, w.ID); err != nil {
+				return err
+			}
+		}
+
+		err := tx.Create(w).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return apperror.Wrap(err, apperror.CodeConflict, "warehouse code "+w.Code+" is already used")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to create warehouse")
+		}
+		return nil
+	})
+}
+
+// Update applies fn to the warehouse and saves it.
+func (s *warehouseStorage) Update(ctx context.Context, id string, fn func(w *models.Warehouse) error) (*models.Warehouse, error) {
+	var w models.Warehouse
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&w).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.CodeNotFound, "warehouse not found")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to get warehouse")
+		}
+
+		if err := fn(&w); err != nil {
+			return err
+		}
+
+		if w.IsDefault {
+			if err := clearDefaultWarehouse(tx, w.ID); err != nil {
+				return err
+			}
+		}
+
+		err = tx.Save(&w).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return apperror.Wrap(err, apperror.CodeConflict, "warehouse code "+w.Code+" is already used")
+		}
+		if err != nil {
+			return apperror.Wrap(err, apperror.CodeInternal, "failed to update warehouse")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func clearDefaultWarehouse(tx *gorm.DB, exceptID string) error {
+	err := tx.
+		Model(&models.Warehouse{}).
+		Where("is_default AND id <> ?", exceptID).
+		Update("is_default", false).
+		Error
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternal, "failed to change default warehouse")
+	}
+	return nil
+}
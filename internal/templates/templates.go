@@ -0,0 +1,26 @@
+// Package templates holds the built-in notification templates. They are
+// used whenever no override for the event, channel and locale is stored
+// in the database.
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed files
+var files embed.FS
+
+// Default returns the built-in template body for the given key.
+func Default(event, channel, locale string) (string, bool) {
+	body, err := fs.ReadFile(files, path(event, channel, locale))
+	if err != nil {
+		return "", false
+	}
+	return string(body), true
+}
+
+func path(event, channel, locale string) string {
+	return fmt.Sprintf("files/%s/%s.%s.tmpl", locale, event, channel)
+}
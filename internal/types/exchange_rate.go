@@ -0,0 +1,10 @@
+package types
+
+import "time"
+
+type ExchangeRateFilter struct {
+	// Currency matches rates with it as base or quote
+	Currency string
+	// EffectiveBy keeps the rates effective on or before it
+	EffectiveBy time.Time
+}
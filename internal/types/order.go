@@ -6,6 +6,8 @@ type OrderFilter struct {
 	Status        string
 	CustomerPhone string
 	Country       string
+	LotID         string
+	WarehouseID   string
 	CreatedFrom   time.Time
 	CreatedTo     time.Time
 	Limit         int
@@ -8,6 +8,9 @@ type ProductFilter struct {
 	Subtitle    string `json:"subtitle,omitempty"`
 	Description string `json:"description,omitempty"`
 	ShowAll     bool   `json:"showAll,omitempty"`
+	Status      string `json:"status,omitempty"`
+	// Deleted lists soft-deleted products instead of live ones.
+	Deleted     bool   `json:"deleted,omitempty"`
 	
 	CreatedAfter  time.Time `json:"createdAfter,omitempty"`
 	CreatedBefore time.Time `json:"createdBefore,omitempty"`
@@ -58,9 +61,10 @@ func (f *ProductFilter) IsEmpty() bool {
 		f.Name == "" &&
 		f.Subtitle == "" &&
 		f.Description == "" &&
+		f.Status == "" &&
 		f.CreatedAfter.Equal(zeroTime) &&
 		f.CreatedBefore.Equal(zeroTime) &&
 		f.UpdatedAfter.Equal(zeroTime) &&
 		f.UpdatedBefore.Equal(zeroTime) &&
 		f.Search == ""
-}
\ No newline at end of file
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+type StockMovementFilter struct {
+	VariantID   string
+	Reason      string
+	WarehouseID string
+	LotID       string
+	From        time.Time
+	To          time.Time
+	Limit       int
+	Offset      int
+}
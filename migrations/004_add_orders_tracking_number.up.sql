@@ -0,0 +1,4 @@
+-- Migration: Add tracking number to orders
+-- Description: Carrier tracking number sent to customers when an order ships
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tracking_number VARCHAR(64);
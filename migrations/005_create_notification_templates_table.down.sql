@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS language;
+
+DROP TRIGGER IF EXISTS trigger_notification_templates_updated_at ON notification_templates;
+DROP TABLE IF EXISTS notification_templates;
+
+COMMIT;
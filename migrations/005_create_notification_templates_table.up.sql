@@ -0,0 +1,27 @@
+-- Migration: Notification templates and recipient language
+-- Description: Operator-editable notification templates keyed by event,
+-- channel and locale; preferred language for staff users
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notification_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event VARCHAR(64) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    locale VARCHAR(8) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_templates_key
+ON notification_templates (event, channel, locale);
+
+CREATE TRIGGER trigger_notification_templates_updated_at
+    BEFORE UPDATE ON notification_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(8) NOT NULL DEFAULT 'uk';
+
+COMMIT;
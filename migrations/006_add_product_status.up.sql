@@ -0,0 +1,22 @@
+-- Migration: Product publishing workflow
+-- Description: Draft/scheduled/published/archived status with an optional
+-- scheduled publish time; is_active mirrors status = 'published'
+
+BEGIN;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'draft';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE products SET status = 'published', published_at = updated_at WHERE is_active;
+
+ALTER TABLE products ALTER COLUMN is_active SET DEFAULT FALSE;
+
+ALTER TABLE products ADD CONSTRAINT chk_products_status
+    CHECK (status IN ('draft', 'scheduled', 'published', 'archived'));
+
+CREATE INDEX IF NOT EXISTS idx_products_scheduled
+ON products (publish_at) WHERE status = 'scheduled';
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Deleted rows may still be referenced by orders, so keep them hidden
+-- and free their slugs for the full unique index
+UPDATE products
+SET slug = slug || '-deleted-' || LEFT(id::text, 8), is_active = FALSE
+WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_products_deleted_at;
+DROP INDEX IF EXISTS idx_products_slug;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug
+ON products (slug);
+
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
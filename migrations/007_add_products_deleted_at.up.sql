@@ -0,0 +1,16 @@
+-- Migration: Soft delete for products
+-- Description: Deleted products keep their row so order items still
+-- resolve them; the slug only has to be unique among live products
+
+BEGIN;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+DROP INDEX IF EXISTS idx_products_slug;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug
+ON products (slug) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at
+ON products (deleted_at);
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- Migration: Variant versions and soft delete
+-- Description: Optimistic concurrency for variant edits; removed variants
+-- keep their row so past orders still resolve them
+
+BEGIN;
+
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_deleted_at
+ON product_variants (deleted_at);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_product_variants_barcode;
+DROP INDEX IF EXISTS idx_product_variants_sku;
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS chk_product_variants_barcode;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS barcode;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS sku;
+
+COMMIT;
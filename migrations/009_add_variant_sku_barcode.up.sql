@@ -0,0 +1,19 @@
+-- Migration: Variant SKU and barcode
+-- Description: Optional SKU and EAN-13 barcode per variant, unique among
+-- live variants
+
+BEGIN;
+
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS barcode VARCHAR(13) NOT NULL DEFAULT '';
+
+ALTER TABLE product_variants ADD CONSTRAINT chk_product_variants_barcode
+    CHECK (barcode = '' OR barcode ~ '^[0-9]{13}$');
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_sku
+ON product_variants (sku) WHERE sku <> '' AND deleted_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_barcode
+ON product_variants (barcode) WHERE barcode <> '' AND deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,33 @@
+-- Migration: Inventory ledger
+-- Description: Every change of product_variants.stock is recorded with
+-- its reason, reference and resulting balance. Existing stock is booked
+-- as an opening balance so the ledger starts in sync.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    variant_id UUID NOT NULL REFERENCES product_variants(id) ON DELETE RESTRICT,
+    product_id UUID NOT NULL,
+    delta INTEGER NOT NULL CHECK (delta <> 0),
+    reason VARCHAR(20) NOT NULL
+        CHECK (reason IN ('order_reserve', 'order_cancel', 'adjustment', 'receipt', 'write_off')),
+    reference_id VARCHAR(64),
+    actor VARCHAR(64) NOT NULL,
+    note TEXT,
+    balance INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_variant_created
+ON stock_movements (variant_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference
+ON stock_movements (reference_id) WHERE reference_id <> '';
+
+INSERT INTO stock_movements (variant_id, product_id, delta, reason, actor, note, balance)
+SELECT id, product_id, stock, 'adjustment', 'migration', 'opening balance', stock
+FROM product_variants
+WHERE stock <> 0;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_order_items_lots;
+ALTER TABLE order_items DROP COLUMN IF EXISTS lots;
+
+DROP INDEX IF EXISTS idx_stock_movements_lot;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS lot_id;
+
+DROP TABLE IF EXISTS stock_lots;
+
+COMMIT;
@@ -0,0 +1,54 @@
+-- Migration: Stock lots
+-- Description: Stock is held in lots with a packing and expiry date and
+-- allocated to orders first expired, first out. Ledger entries and order
+-- items record the lot they touched. Existing stock moves into each
+-- variant's unlabelled lot (empty lot number, unknown dates).
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS stock_lots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    variant_id UUID NOT NULL REFERENCES product_variants(id) ON DELETE RESTRICT,
+    product_id UUID NOT NULL,
+    lot_number VARCHAR(64) NOT NULL DEFAULT '',
+    packed_at DATE,
+    expires_at DATE,
+    received_quantity INTEGER NOT NULL DEFAULT 0 CHECK (received_quantity >= 0),
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (packed_at IS NULL OR expires_at IS NULL OR expires_at > packed_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_lots_variant_lot_number
+ON stock_lots (variant_id, lot_number);
+
+CREATE INDEX IF NOT EXISTS idx_stock_lots_fefo
+ON stock_lots (variant_id, expires_at) WHERE quantity > 0;
+
+CREATE INDEX IF NOT EXISTS idx_stock_lots_expiring
+ON stock_lots (expires_at) WHERE quantity > 0;
+
+ALTER TABLE stock_movements
+ADD COLUMN IF NOT EXISTS lot_id UUID REFERENCES stock_lots(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_lot
+ON stock_movements (lot_id) WHERE lot_id IS NOT NULL;
+
+ALTER TABLE order_items
+ADD COLUMN IF NOT EXISTS lots JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_order_items_lots
+ON order_items USING GIN (lots jsonb_path_ops);
+
+INSERT INTO stock_lots (variant_id, product_id, received_quantity, quantity)
+SELECT id, product_id, stock, stock
+FROM product_variants
+WHERE stock > 0;
+
+UPDATE stock_movements m
+SET lot_id = l.id
+FROM stock_lots l
+WHERE l.variant_id = m.variant_id AND l.lot_number = '';
+
+COMMIT;
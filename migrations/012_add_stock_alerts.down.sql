@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE stock_lots DROP COLUMN IF EXISTS expiry_alerted_at;
+
+ALTER TABLE product_variants DROP COLUMN IF EXISTS low_stock_alerted_at;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS low_stock_threshold;
+
+COMMIT;
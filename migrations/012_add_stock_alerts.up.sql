@@ -0,0 +1,15 @@
+-- Migration: Stock alerts
+-- Description: Per-variant low-stock threshold (0 disables the alert)
+-- and markers that keep low-stock and lot expiry alerts from repeating.
+
+BEGIN;
+
+ALTER TABLE product_variants
+ADD COLUMN IF NOT EXISTS low_stock_threshold INTEGER NOT NULL DEFAULT 0
+    CHECK (low_stock_threshold >= 0),
+ADD COLUMN IF NOT EXISTS low_stock_alerted_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE stock_lots
+ADD COLUMN IF NOT EXISTS expiry_alerted_at TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- Fails if a variant has lots with the same number in several
+-- warehouses; merge or empty them first.
+
+BEGIN;
+
+DROP VIEW IF EXISTS stock_levels;
+
+DROP INDEX IF EXISTS idx_orders_warehouse;
+ALTER TABLE orders DROP COLUMN IF EXISTS warehouse_id;
+
+UPDATE stock_movements SET reason = 'adjustment' WHERE reason = 'transfer';
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+CHECK (reason IN ('order_reserve', 'order_cancel', 'adjustment', 'receipt', 'write_off'));
+
+DROP INDEX IF EXISTS idx_stock_movements_warehouse;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS warehouse_id;
+
+DROP INDEX IF EXISTS idx_stock_lots_fefo;
+DROP INDEX IF EXISTS idx_stock_lots_variant_warehouse_lot_number;
+ALTER TABLE stock_lots DROP COLUMN IF EXISTS warehouse_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_lots_variant_lot_number
+ON stock_lots (variant_id, lot_number);
+
+CREATE INDEX IF NOT EXISTS idx_stock_lots_fefo
+ON stock_lots (variant_id, expires_at) WHERE quantity > 0;
+
+DROP TABLE IF EXISTS warehouses;
+
+COMMIT;
@@ -0,0 +1,81 @@
+-- Migration: Warehouses
+-- Description: Stock lots belong to a warehouse and per-warehouse stock
+-- levels are read from the stock_levels view. Stock can be transferred
+-- between warehouses, and each order is fulfilled from one warehouse
+-- chosen by delivery country. Existing stock moves into the MAIN
+-- warehouse, which becomes the default.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS warehouses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    countries JSONB NOT NULL DEFAULT '[]',
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (NOT is_default OR is_active)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_code ON warehouses (code);
+
+-- At most one default warehouse.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_default
+ON warehouses (is_default) WHERE is_default;
+
+INSERT INTO warehouses (code, name, is_default)
+VALUES ('MAIN', 'Main warehouse', TRUE)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE stock_lots
+ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id) ON DELETE RESTRICT;
+
+UPDATE stock_lots
+SET warehouse_id = (SELECT id FROM warehouses WHERE is_default)
+WHERE warehouse_id IS NULL;
+
+ALTER TABLE stock_lots ALTER COLUMN warehouse_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_stock_lots_variant_lot_number;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_lots_variant_warehouse_lot_number
+ON stock_lots (variant_id, warehouse_id, lot_number);
+
+DROP INDEX IF EXISTS idx_stock_lots_fefo;
+CREATE INDEX IF NOT EXISTS idx_stock_lots_fefo
+ON stock_lots (variant_id, warehouse_id, expires_at) WHERE quantity > 0;
+
+ALTER TABLE stock_movements
+ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id) ON DELETE RESTRICT;
+
+UPDATE stock_movements m
+SET warehouse_id = l.warehouse_id
+FROM stock_lots l
+WHERE l.id = m.lot_id AND m.warehouse_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_warehouse
+ON stock_movements (warehouse_id, created_at DESC);
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+CHECK (reason IN ('order_reserve', 'order_cancel', 'adjustment', 'receipt', 'write_off', 'transfer'));
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_orders_warehouse
+ON orders (warehouse_id) WHERE warehouse_id IS NOT NULL;
+
+CREATE OR REPLACE VIEW stock_levels AS
+SELECT l.variant_id,
+       l.warehouse_id,
+       w.code AS warehouse_code,
+       SUM(l.quantity)::INTEGER AS quantity
+FROM stock_lots l
+JOIN warehouses w ON w.id = l.warehouse_id
+GROUP BY l.variant_id, l.warehouse_id, w.code
+HAVING SUM(l.quantity) > 0;
+
+COMMIT;
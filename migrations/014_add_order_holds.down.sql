@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_orders_hold_expires_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS hold_expires_at;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration: Order stock holds
+-- Description: Stock reserved for a pending order is held until
+-- hold_expires_at; past it the order is cancelled and the stock
+-- released. Existing pending orders keep their stock without expiry.
+
+BEGIN;
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS hold_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_orders_hold_expires_at
+ON orders (hold_expires_at) WHERE status = 'pending' AND hold_expires_at IS NOT NULL;
+
+COMMIT;
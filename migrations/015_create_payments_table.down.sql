@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_at;
+
+DROP TABLE IF EXISTS payments;
+
+COMMIT;
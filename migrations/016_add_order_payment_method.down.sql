@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_fee_check;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_fee;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_method;
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- Migration: Order payment methods
+-- Description: Orders are paid cash on delivery, by card online or by
+-- bank transfer. Cash on delivery carries a fee that is included in
+-- total_amount and kept in payment_fee. Existing orders were all cash
+-- on delivery.
+
+BEGIN;
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS payment_method VARCHAR(20) NOT NULL DEFAULT 'cod',
+ADD COLUMN IF NOT EXISTS payment_fee INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE orders ALTER COLUMN payment_method DROP DEFAULT;
+
+ALTER TABLE orders
+ADD CONSTRAINT orders_payment_method_check
+CHECK (payment_method IN ('cod', 'card', 'bank_transfer'));
+
+ALTER TABLE orders
+ADD CONSTRAINT orders_payment_fee_check CHECK (payment_fee >= 0);
+
+COMMIT;
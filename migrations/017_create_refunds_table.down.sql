@@ -0,0 +1,17 @@
+BEGIN;
+
+UPDATE stock_movements SET reason = 'order_cancel' WHERE reason = 'order_return';
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+CHECK (reason IN ('order_reserve', 'order_cancel', 'adjustment', 'receipt', 'write_off', 'transfer'));
+
+DELETE FROM order_items WHERE quantity = 0;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_quantity_check;
+ALTER TABLE order_items ADD CONSTRAINT order_items_quantity_check CHECK (quantity > 0);
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_refunded_amount_check;
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_amount;
+
+DROP TABLE IF EXISTS refunds;
+
+COMMIT;
@@ -0,0 +1,38 @@
+-- Migration: Refunds
+-- Description: Refunds record order items taken out of an order by a
+-- partial cancellation or a return, and the money owed back for them.
+-- Order items keep what is left, so a fully refunded item has quantity
+-- 0. Returned items that go back on sale are booked as order_return.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('cancellation', 'return')),
+    amount INTEGER NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    currency VARCHAR(3) NOT NULL,
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    items JSONB NOT NULL DEFAULT '[]'::jsonb,
+    reason TEXT NOT NULL,
+    actor VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds (order_id);
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS refunded_amount INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_refunded_amount_check
+CHECK (refunded_amount >= 0 AND refunded_amount <= amount);
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_quantity_check;
+ALTER TABLE order_items ADD CONSTRAINT order_items_quantity_check CHECK (quantity >= 0);
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+CHECK (reason IN ('order_reserve', 'order_cancel', 'order_return', 'adjustment', 'receipt', 'write_off', 'transfer'));
+
+COMMIT;
@@ -0,0 +1,44 @@
+-- Migration: Promo codes
+-- Description: Promo codes take a percentage or a fixed amount off an
+-- order's items, or make its delivery free. Redemptions record the
+-- orders that used a code, for its usage limits. Orders keep the
+-- applied code and its discount lines.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL UNIQUE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'free_shipping')),
+    value INTEGER NOT NULL DEFAULT 0 CHECK (value >= 0),
+    currency VARCHAR(3),
+    min_order_amount INTEGER NOT NULL DEFAULT 0 CHECK (min_order_amount >= 0),
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    usage_limit INTEGER NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+    per_customer_limit INTEGER NOT NULL DEFAULT 0 CHECK (per_customer_limit >= 0),
+    product_ids JSONB NOT NULL DEFAULT '[]'::jsonb,
+    variant_ids JSONB NOT NULL DEFAULT '[]'::jsonb,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- order_id has no foreign key: the redemption is written before the
+-- order, and one without an order is in flight.
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    promo_code_id UUID NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE,
+    customer_phone VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_redemptions_code_phone
+ON promo_redemptions (promo_code_id, customer_phone);
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS promo_code VARCHAR(32),
+ADD COLUMN IF NOT EXISTS discounts JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMIT;
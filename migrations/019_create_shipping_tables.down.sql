@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_weight;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_cost;
+
+DROP TABLE IF EXISTS shipping_rates;
+DROP TABLE IF EXISTS shipping_zones;
+
+COMMIT;
@@ -0,0 +1,51 @@
+-- Migration: Shipping zones and rates
+-- Description: Shipping rates price a delivery type within a zone of
+-- countries by parcel weight, with an optional free shipping threshold.
+-- A zone without countries covers every country no other zone lists.
+-- Orders keep the shipping they were charged apart from the goods. A
+-- catch-all zone with free rates is seeded so checkout keeps working
+-- until real rates are set up; a free rate, with neither a price nor a
+-- threshold, quotes orders in any currency.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS shipping_zones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    countries JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS shipping_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    zone_id UUID NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    delivery_type VARCHAR(20) NOT NULL CHECK (delivery_type IN ('post_office', 'courier', 'address')),
+    max_weight INTEGER NOT NULL DEFAULT 0 CHECK (max_weight >= 0),
+    price INTEGER NOT NULL CHECK (price >= 0),
+    currency VARCHAR(3) NOT NULL,
+    free_from INTEGER NOT NULL DEFAULT 0 CHECK (free_from >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipping_rates_zone_id ON shipping_rates (zone_id);
+CREATE INDEX IF NOT EXISTS idx_shipping_rates_delivery_type ON shipping_rates (delivery_type) WHERE is_active;
+
+WITH zone AS (
+    INSERT INTO shipping_zones (code, name)
+    VALUES ('ALL', 'All countries')
+    ON CONFLICT (code) DO NOTHING
+    RETURNING id
+)
+INSERT INTO shipping_rates (zone_id, delivery_type, price, currency)
+SELECT zone.id, delivery_type, 0, 'UAH'
+FROM zone, (VALUES ('post_office'), ('courier'), ('address')) AS types (delivery_type);
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS shipping_cost INTEGER NOT NULL DEFAULT 0 CHECK (shipping_cost >= 0),
+ADD COLUMN IF NOT EXISTS shipping_weight INTEGER NOT NULL DEFAULT 0 CHECK (shipping_weight >= 0);
+
+COMMIT;
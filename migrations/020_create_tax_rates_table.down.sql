@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS gross_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS net_amount;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS net_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_inclusive;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_rate;
+
+DROP TABLE IF EXISTS tax_rates;
+
+COMMIT;
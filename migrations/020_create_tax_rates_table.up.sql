@@ -0,0 +1,42 @@
+-- Migration: Tax rates
+-- Description: VAT rates per delivery country, in basis points, either
+-- included in prices or added on top of them. Orders keep the rate they
+-- were placed with and split every line and the order into net and tax
+-- amounts. Existing orders were not taxed: they are backfilled with a
+-- zero inclusive rate, their gross amounts being all net.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    country VARCHAR(2) NOT NULL UNIQUE,
+    rate INTEGER NOT NULL CHECK (rate BETWEEN 0 AND 10000),
+    inclusive BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS tax_rate INTEGER NOT NULL DEFAULT 0 CHECK (tax_rate BETWEEN 0 AND 10000),
+ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT TRUE,
+ADD COLUMN IF NOT EXISTS net_amount INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE order_items
+ADD COLUMN IF NOT EXISTS net_amount INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS gross_amount INTEGER NOT NULL DEFAULT 0;
+
+UPDATE orders SET net_amount = (total_amount->>'amount')::INTEGER;
+
+-- Items are paid what is left of their price after their discount lines.
+UPDATE order_items i
+SET gross_amount = (i.total_price->>'amount')::INTEGER - COALESCE((
+        SELECT SUM((d->>'amount')::INTEGER)
+        FROM orders o, jsonb_array_elements(o.discounts) d
+        WHERE o.id = i.order_id AND d->>'order_item_id' = i.id::TEXT
+    ), 0);
+
+UPDATE order_items SET net_amount = gross_amount;
+
+COMMIT;
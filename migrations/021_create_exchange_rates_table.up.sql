@@ -0,0 +1,23 @@
+-- Migration: Exchange rates
+-- Description: Dated exchange rates between currency pairs, set by staff
+-- or imported from CSV. A rate applies from its date until the pair's
+-- next one; there is one rate per pair and day.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    base VARCHAR(3) NOT NULL,
+    quote VARCHAR(3) NOT NULL,
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0),
+    effective_on DATE NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('manual', 'import')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (base, quote, effective_on),
+    CHECK (base <> quote)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_effective_on ON exchange_rates (effective_on);
+
+COMMIT;
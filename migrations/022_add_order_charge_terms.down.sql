@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS cod_fee_percent;
+ALTER TABLE orders DROP COLUMN IF EXISTS cod_fee_fixed;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_free_from;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_price;
+
+COMMIT;
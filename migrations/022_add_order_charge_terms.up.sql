@@ -0,0 +1,19 @@
+-- Migration: Order charge terms
+-- Description: Orders keep the shipping rate's price and free shipping
+-- threshold and the cash on delivery fee terms they were charged on, so
+-- a partial cancellation can recompute both on the goods left. Existing
+-- orders keep the charges they have: their shipping price is what they
+-- were charged without a threshold, and their fee a fixed one.
+
+BEGIN;
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS shipping_price INTEGER NOT NULL DEFAULT 0 CHECK (shipping_price >= 0),
+ADD COLUMN IF NOT EXISTS shipping_free_from INTEGER NOT NULL DEFAULT 0 CHECK (shipping_free_from >= 0),
+ADD COLUMN IF NOT EXISTS cod_fee_fixed INTEGER NOT NULL DEFAULT 0 CHECK (cod_fee_fixed >= 0),
+ADD COLUMN IF NOT EXISTS cod_fee_percent INTEGER NOT NULL DEFAULT 0 CHECK (cod_fee_percent >= 0);
+
+UPDATE orders SET shipping_price = shipping_cost;
+UPDATE orders SET cod_fee_fixed = payment_fee WHERE payment_method = 'cod';
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+UPDATE products
+SET images = (
+    SELECT COALESCE(jsonb_agg(
+        jsonb_build_object(
+            'ID', img->'id',
+            'ObjectKey', img->'object_key',
+            'ContentType', img->'content_type',
+            'Size', img->'size',
+            'Width', img->'width',
+            'Height', img->'height',
+            'Position', img->'position',
+            'IsPrimary', img->'is_primary',
+            'Renditions', CASE
+                WHEN jsonb_typeof(img->'renditions') = 'array' THEN (
+                    SELECT COALESCE(jsonb_agg(
+                        jsonb_build_object(
+                            'Name', r->'name',
+                            'Format', r->'format',
+                            'ObjectKey', r->'object_key',
+                            'ContentType', r->'content_type',
+                            'Width', r->'width',
+                            'Height', r->'height',
+                            'Size', r->'size'
+                        ) ORDER BY r_ord), '[]'::jsonb)
+                    FROM jsonb_array_elements(img->'renditions') WITH ORDINALITY AS rs (r, r_ord)
+                )
+                ELSE '[]'::jsonb
+            END
+        ) ORDER BY ord), '[]'::jsonb)
+    FROM jsonb_array_elements(images) WITH ORDINALITY AS t (img, ord)
+)
+WHERE jsonb_typeof(images) = 'array'
+  AND EXISTS (SELECT 1 FROM jsonb_array_elements(images) e WHERE e ? 'id');
+
+COMMIT;
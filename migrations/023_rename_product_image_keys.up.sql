@@ -0,0 +1,43 @@
+-- Migration: Product image keys
+-- Description: Product images in products.images were stored with Go
+-- field names as keys ("ObjectKey"); they are renamed to the snake_case
+-- keys the model now uses ("object_key"). Rows already renamed are left
+-- alone.
+
+BEGIN;
+
+UPDATE products
+SET images = (
+    SELECT COALESCE(jsonb_agg(
+        jsonb_build_object(
+            'id', img->'ID',
+            'object_key', img->'ObjectKey',
+            'content_type', img->'ContentType',
+            'size', img->'Size',
+            'width', img->'Width',
+            'height', img->'Height',
+            'position', img->'Position',
+            'is_primary', img->'IsPrimary',
+            'renditions', CASE
+                WHEN jsonb_typeof(img->'Renditions') = 'array' THEN (
+                    SELECT COALESCE(jsonb_agg(
+                        jsonb_build_object(
+                            'name', r->'Name',
+                            'format', r->'Format',
+                            'object_key', r->'ObjectKey',
+                            'content_type', r->'ContentType',
+                            'width', r->'Width',
+                            'height', r->'Height',
+                            'size', r->'Size'
+                        ) ORDER BY r_ord), '[]'::jsonb)
+                    FROM jsonb_array_elements(img->'Renditions') WITH ORDINALITY AS rs (r, r_ord)
+                )
+                ELSE '[]'::jsonb
+            END
+        ) ORDER BY ord), '[]'::jsonb)
+    FROM jsonb_array_elements(images) WITH ORDINALITY AS t (img, ord)
+)
+WHERE jsonb_typeof(images) = 'array'
+  AND EXISTS (SELECT 1 FROM jsonb_array_elements(images) e WHERE e ? 'ID');
+
+COMMIT;
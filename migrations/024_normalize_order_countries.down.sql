@@ -0,0 +1,5 @@
+BEGIN;
+
+-- The original spelling of normalized country codes is not kept.
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration: Order delivery countries
+-- Description: Delivery countries are now stored as upper-case ISO
+-- 3166-1 alpha-2 codes. Existing codes in another case or with spaces
+-- around them are normalized so the country filter finds them; other
+-- values are left as they are.
+
+BEGIN;
+
+UPDATE orders
+SET delivery_info = jsonb_set(delivery_info, '{country}', to_jsonb(upper(btrim(delivery_info->>'country'))))
+WHERE btrim(delivery_info->>'country') ~* '^[a-z]{2}$'
+  AND delivery_info->>'country' <> upper(btrim(delivery_info->>'country'));
+
+COMMIT;
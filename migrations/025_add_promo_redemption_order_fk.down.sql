@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE promo_redemptions
+DROP CONSTRAINT IF EXISTS fk_promo_redemptions_order;
+
+-- Removed redemptions without an order are not restored.
+
+COMMIT;
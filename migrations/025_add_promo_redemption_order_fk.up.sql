@@ -0,0 +1,15 @@
+-- Migration: Promo redemption orders
+-- Description: Redemptions are now stored in the same transaction as
+-- their order. Redemptions left behind by orders that were never placed
+-- are removed, and deleting an order deletes its redemption.
+
+BEGIN;
+
+DELETE FROM promo_redemptions r
+WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = r.order_id);
+
+ALTER TABLE promo_redemptions
+ADD CONSTRAINT fk_promo_redemptions_order
+FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
+
+COMMIT;
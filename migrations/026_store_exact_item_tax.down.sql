@@ -0,0 +1,10 @@
+BEGIN;
+
+-- Amounts are rounded to the currency unit again; net takes what is
+-- left of the rounded gross.
+ALTER TABLE order_items
+ALTER COLUMN net_amount TYPE INTEGER USING ROUND(gross_amount) - ROUND(tax_amount),
+ALTER COLUMN tax_amount TYPE INTEGER USING ROUND(tax_amount),
+ALTER COLUMN gross_amount TYPE INTEGER USING ROUND(gross_amount);
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Migration: Exact item tax
+-- Description: Order items keep their net, tax and gross amounts as
+-- exact decimals; only the order's totals are rounded to the currency
+-- unit. Existing items are split again at their order's rate. Order
+-- totals are left as they were charged.
+
+BEGIN;
+
+ALTER TABLE order_items
+ALTER COLUMN net_amount TYPE NUMERIC(20,10),
+ALTER COLUMN tax_amount TYPE NUMERIC(20,10),
+ALTER COLUMN gross_amount TYPE NUMERIC(20,10);
+
+UPDATE order_items i
+SET tax_amount = ROUND(i.gross_amount * o.tax_rate / (10000 + o.tax_rate), 10),
+    net_amount = i.gross_amount - ROUND(i.gross_amount * o.tax_rate / (10000 + o.tax_rate), 10)
+FROM orders o
+WHERE o.id = i.order_id AND o.tax_rate > 0 AND o.tax_inclusive;
+
+UPDATE order_items i
+SET tax_amount = i.net_amount * o.tax_rate / 10000,
+    gross_amount = i.net_amount + i.net_amount * o.tax_rate / 10000
+FROM orders o
+WHERE o.id = i.order_id AND o.tax_rate > 0 AND NOT o.tax_inclusive;
+
+COMMIT;
@@ -18,6 +18,7 @@ const (
 	CodeNotFound     Code = "NOT_FOUND"
 	CodeInvalidInput Code = "INVALID_INPUT"
 	CodeUnauthorized Code = "UNAUTHORIZED"
+	CodeConflict     Code = "CONFLICT"
 	CodeInternal     Code = "INTERNAL_ERROR"
 	// extend as needed…
 )
@@ -68,6 +69,8 @@ func (e *AppError) HTTPStatus() int {
 		return http.StatusBadRequest
 	case CodeUnauthorized:
 		return http.StatusUnauthorized
+	case CodeConflict:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
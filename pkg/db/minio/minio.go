@@ -1,8 +1,11 @@
 package minio_db
 
 import (
+	"bytes"
 	"caviar/internal/config"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/url"
@@ -10,9 +13,21 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
 	"github.com/pkg/errors"
 )
 
+const (
+	// abortUploadsRuleID identifies the lifecycle rule managed by the app,
+	// so rules added by operators are left alone.
+	abortUploadsRuleID = "caviar-abort-incomplete-uploads"
+	// publicReadSid identifies the bucket policy statement managed by the
+	// app, for the same reason.
+	publicReadSid   = "caviar-public-read"
+	probeObjectName = ".caviar-startup-check"
+)
+
 type PutObjectParams struct {
 	ObjectName  string
 	Reader      io.Reader
@@ -31,28 +46,48 @@ type Minio struct {
 	BucketName string
 }
 
+// NewMinio connects to MinIO, creates the bucket if needed, applies the
+// configured lifecycle and access policy, and verifies that objects can
+// be written and removed. Any failure is reported with a hint about the
+// setting that is most likely wrong.
 func NewMinio(ctx context.Context, cfg config.Minio) (*Minio, error) {
+	if err := s3utils.CheckValidBucketNameStrict(cfg.BucketName); err != nil {
+		return nil, errors.Wrapf(err, "invalid MINIO_BUCKET_NAME %q", cfg.BucketName)
+	}
+
 	client, err := minio.New(cfg.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
 		Secure: cfg.UseSSL,
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to initialize MinIO client")
+		return nil, errors.Wrapf(err, "failed to initialize MinIO client for %q", cfg.Endpoint)
 	}
 
 	m := &Minio{Client: client, BucketName: cfg.BucketName}
 
 	exists, err := client.BucketExists(ctx, m.BucketName)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not check if bucket %q exists", m.BucketName)
+		return nil, m.describe(err, cfg, "could not check if bucket %q exists", m.BucketName)
 	}
 	if !exists {
 		if err := client.MakeBucket(ctx, m.BucketName, minio.MakeBucketOptions{}); err != nil {
-			return nil, errors.Wrapf(err, "failed to create bucket %q", m.BucketName)
+			return nil, m.describe(err, cfg, "failed to create bucket %q", m.BucketName)
 		}
 		log.Printf("[MinIO] created bucket %s", m.BucketName)
 	}
 
+	if err := m.applyLifecycle(ctx, cfg.AbortUploadsAfterDays); err != nil {
+		return nil, m.describe(err, cfg, "failed to configure lifecycle of bucket %q", m.BucketName)
+	}
+
+	if err := m.applyPublicRead(ctx, cfg.PublicRead, cfg.PublicReadPrefix); err != nil {
+		return nil, m.describe(err, cfg, "failed to set public-read policy on bucket %q", m.BucketName)
+	}
+
+	if err := m.Check(ctx); err != nil {
+		return nil, m.describe(err, cfg, "bucket %q is not writable", m.BucketName)
+	}
+
 	return m, nil
 }
 
@@ -60,6 +95,163 @@ func (m *Minio) Close() error {
 	return nil
 }
 
+// Check writes and removes a small probe object, which catches
+// credentials that can list but not write.
+func (m *Minio) Check(ctx context.Context) error {
+	_, err := m.Client.PutObject(ctx, m.BucketName, probeObjectName, bytes.NewReader(nil), 0, minio.PutObjectOptions{})
+	if err != nil {
+		return err
+	}
+	return m.Client.RemoveObject(ctx, m.BucketName, probeObjectName, minio.RemoveObjectOptions{})
+}
+
+// applyLifecycle makes the bucket abort multipart uploads that were never
+// completed after the given number of days. Zero removes the rule.
+func (m *Minio) applyLifecycle(ctx context.Context, days int) error {
+	current, err := m.Client.GetBucketLifecycle(ctx, m.BucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchLifecycleConfiguration" {
+			return err
+		}
+		current = lifecycle.NewConfiguration()
+	}
+
+	updated := lifecycle.NewConfiguration()
+	for _, rule := range current.Rules {
+		if rule.ID != abortUploadsRuleID {
+			updated.Rules = append(updated.Rules, rule)
+		}
+	}
+	if days > 0 {
+		updated.Rules = append(updated.Rules, lifecycle.Rule{
+			ID:         abortUploadsRuleID,
+			Status:     "Enabled",
+			RuleFilter: lifecycle.Filter{Prefix: ""},
+			AbortIncompleteMultipartUpload: lifecycle.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: lifecycle.ExpirationDays(days),
+			},
+		})
+	}
+
+	if len(updated.Rules) == len(current.Rules) && days <= 0 {
+		return nil
+	}
+	return m.Client.SetBucketLifecycle(ctx, m.BucketName, updated)
+}
+
+// applyPublicRead adds a statement to the bucket policy that allows
+// anonymous downloads of objects under prefix, so image URLs can be
+// served without presigning, or removes it when enabled is false.
+// Statements added by operators are kept.
+func (m *Minio) applyPublicRead(ctx context.Context, enabled bool, prefix string) error {
+	current, err := m.Client.GetBucketPolicy(ctx, m.BucketName)
+	if err != nil {
+		return err
+	}
+
+	var statement map[string]any
+	if enabled {
+		statement = map[string]any{
+			"Sid":       publicReadSid,
+			"Effect":    "Allow",
+			"Principal": map[string]any{"AWS": []string{"*"}},
+			"Action":    []string{"s3:GetObject"},
+			"Resource":  []string{fmt.Sprintf("arn:aws:s3:::%s/%s*", m.BucketName, prefix)},
+		}
+	}
+
+	updated, changed, err := mergePolicyStatement(current, publicReadSid, statement)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the current bucket policy")
+	}
+	if !changed {
+		return nil
+	}
+	return m.Client.SetBucketPolicy(ctx, m.BucketName, updated)
+}
+
+// mergePolicyStatement replaces the statement with the given Sid in a
+// JSON bucket policy, or removes it when statement is nil. It returns an
+// empty policy, which deletes the bucket policy, when no statements are
+// left, and reports whether the policy changed.
+func mergePolicyStatement(current, sid string, statement map[string]any) (string, bool, error) {
+	policy := map[string]any{}
+	if current != "" {
+		if err := json.Unmarshal([]byte(current), &policy); err != nil {
+			return "", false, err
+		}
+	}
+
+	// A policy with a single statement may hold it as an object.
+	var statements []any
+	switch v := policy["Statement"].(type) {
+	case []any:
+		statements = v
+	case map[string]any:
+		statements = []any{v}
+	}
+
+	kept := make([]any, 0, len(statements)+1)
+	var previous any
+	for _, s := range statements {
+		if st, ok := s.(map[string]any); ok && st["Sid"] == sid {
+			previous = st
+			continue
+		}
+		kept = append(kept, s)
+	}
+
+	if statement == nil && previous == nil {
+		return current, false, nil
+	}
+	if statement != nil && previous != nil {
+		// Compare through JSON, as the parsed statement holds []any
+		// where ours holds []string.
+		a, _ := json.Marshal(previous)
+		b, _ := json.Marshal(statement)
+		if bytes.Equal(a, b) {
+			return current, false, nil
+		}
+	}
+
+	if statement != nil {
+		kept = append(kept, statement)
+	}
+	if len(kept) == 0 {
+		return "", true, nil
+	}
+
+	if _, ok := policy["Version"]; !ok {
+		policy["Version"] = "2012-10-17"
+	}
+	policy["Statement"] = kept
+	updated, err := json.Marshal(policy)
+	if err != nil {
+		return "", false, err
+	}
+	return string(updated), true, nil
+}
+
+// describe wraps a startup error with a hint based on the S3 error code.
+func (m *Minio) describe(err error, cfg config.Minio, format string, args ...any) error {
+	var hint string
+	switch minio.ToErrorResponse(err).Code {
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		hint = "check MINIO_ACCESS_KEY_ID and MINIO_SECRET_ACCESS_KEY"
+	case "AccessDenied":
+		hint = "the access key lacks permissions on this bucket"
+	case "NotImplemented":
+		hint = "the storage server does not support this operation"
+	case "":
+		hint = fmt.Sprintf("check that MinIO is reachable at %s (MINIO_USE_SSL=%t)", cfg.Endpoint, cfg.UseSSL)
+	}
+
+	err = errors.Wrapf(err, format, args...)
+	if hint != "" {
+		err = errors.Wrap(err, hint)
+	}
+	return err
+}
 
 func (m *Minio) PutObject(ctx context.Context, params PutObjectParams) (minio.UploadInfo, error) {
 	info, err := m.Client.PutObject(
@@ -75,7 +267,6 @@ func (m *Minio) PutObject(ctx context.Context, params PutObjectParams) (minio.Up
 	}
 	return info, nil
 }
-
 func (m *Minio) GetObject(
 	ctx context.Context,
 	objectName string,
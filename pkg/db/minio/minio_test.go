@@ -0,0 +1,73 @@
+package minio_db
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergePolicyStatement(t *testing.T) {
+	ours := map[string]any{
+		"Sid":      publicReadSid,
+		"Effect":   "Allow",
+		"Action":   []string{"s3:GetObject"},
+		"Resource": []string{"arn:aws:s3:::media/products/*"},
+	}
+	const operator = `{"Sid":"backup","Effect":"Allow","Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::media"]}`
+	const oursJSON = `{"Sid":"caviar-public-read","Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::media/products/*"]}`
+
+	tests := []struct {
+		name      string
+		current   string
+		statement map[string]any
+		want      []string
+		changed   bool
+	}{
+		{"adds to an empty bucket", "", ours, []string{publicReadSid}, true},
+		{"keeps operator statements", `{"Version":"2012-10-17","Statement":[` + operator + `]}`, ours, []string{"backup", publicReadSid}, true},
+		{"accepts a single statement object", `{"Version":"2012-10-17","Statement":` + operator + `}`, ours, []string{"backup", publicReadSid}, true},
+		{"leaves an up-to-date policy alone", `{"Version":"2012-10-17","Statement":[` + oursJSON + `]}`, ours, []string{publicReadSid}, false},
+		{"removes ours", `{"Version":"2012-10-17","Statement":[` + operator + `,` + oursJSON + `]}`, nil, []string{"backup"}, true},
+		{"deletes a policy left empty", `{"Version":"2012-10-17","Statement":[` + oursJSON + `]}`, nil, nil, true},
+		{"nothing to remove", "", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, changed, err := mergePolicyStatement(tt.current, publicReadSid, tt.statement)
+			if err != nil {
+				t.Fatalf("mergePolicyStatement: %v", err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if updated == "" {
+				if len(tt.want) > 0 {
+					t.Fatalf("policy deleted, want statements %v", tt.want)
+				}
+				return
+			}
+
+			var policy struct {
+				Statement []struct{ Sid string }
+			}
+			if err := json.Unmarshal([]byte(updated), &policy); err != nil {
+				t.Fatalf("result is not a policy: %v", err)
+			}
+			var sids []string
+			for _, s := range policy.Statement {
+				sids = append(sids, s.Sid)
+			}
+			if len(sids) != len(tt.want) {
+				t.Fatalf("statements %v, want %v", sids, tt.want)
+			}
+			for i := range sids {
+				if sids[i] != tt.want[i] {
+					t.Errorf("statements %v, want %v", sids, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, _, err := mergePolicyStatement("not json", publicReadSid, ours); err == nil {
+		t.Error("mergePolicyStatement accepted a malformed policy")
+	}
+}
@@ -47,6 +47,9 @@ func New(
 	gormConfig := &gorm.Config{
 		Logger:         newLogger,
 		NamingStrategy: schema.NamingStrategy{SingularTable: false},
+		// Report unique and foreign key violations as gorm.ErrDuplicatedKey
+		// and gorm.ErrForeignKeyViolated instead of raw driver errors.
+		TranslateError: true,
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
@@ -0,0 +1,77 @@
+package imaging
+
+// boolEncoder is the VP8 boolean entropy encoder (RFC 6386, section 7).
+type boolEncoder struct {
+	buf      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func newBoolEncoder() *boolEncoder {
+	return &boolEncoder{rng: 255, bitCount: 24}
+}
+
+// putBit writes bit, which is false with probability prob/256.
+func (e *boolEncoder) putBit(bit bool, prob uint8) {
+	split := 1 + ((e.rng - 1) * uint32(prob) >> 8)
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			e.carry()
+		}
+		e.bottom <<= 1
+		e.bitCount--
+		if e.bitCount == 0 {
+			e.buf = append(e.buf, byte(e.bottom>>24))
+			e.bottom &= 1<<24 - 1
+			e.bitCount = 8
+		}
+	}
+}
+
+// carry propagates an overflow into the bytes already written.
+func (e *boolEncoder) carry() {
+	for i := len(e.buf) - 1; i >= 0; i-- {
+		if e.buf[i] != 255 {
+			e.buf[i]++
+			return
+		}
+		e.buf[i] = 0
+	}
+}
+
+// putLiteral writes the n low bits of v, most significant first, each
+// with even probability.
+func (e *boolEncoder) putLiteral(v uint32, n int) {
+	for n > 0 {
+		n--
+		e.putBit(v>>uint(n)&1 == 1, 128)
+	}
+}
+
+// putSigned writes a magnitude of n bits followed by a sign bit.
+func (e *boolEncoder) putSigned(v int32, n int) {
+	if v < 0 {
+		e.putLiteral(uint32(-v), n)
+		e.putBit(true, 128)
+		return
+	}
+	e.putLiteral(uint32(v), n)
+	e.putBit(false, 128)
+}
+
+// flush pads the stream so that the decoder never reads past its end.
+func (e *boolEncoder) flush() []byte {
+	for i := 0; i < 32; i++ {
+		e.putBit(false, 128)
+	}
+	return e.buf
+}
@@ -0,0 +1,22 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Common imaging package errors
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+)
+
+// ErrDecode creates an image decoding error
+func ErrDecode(err error) error {
+	return fmt.Errorf("cannot decode image: %w", err)
+}
+
+// ErrEncode creates a rendition encoding error
+func ErrEncode(format string, err error) error {
+	return fmt.Errorf("cannot encode %s rendition: %w", format, err)
+}
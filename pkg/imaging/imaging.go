@@ -0,0 +1,180 @@
+// Package imaging prepares uploaded photos for the storefront: it strips
+// metadata from the original and produces resized JPEG and WebP
+// renditions, all in pure Go.
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+
+	// maxPixels guards against decompression bombs; 60 MP covers any
+	// camera we are likely to see.
+	maxPixels = 60_000_000
+)
+
+var ContentTypes = map[string]string{
+	FormatJPEG: "image/jpeg",
+	FormatWebP: "image/webp",
+}
+
+// RenditionSpec describes a rendition that fits into a MaxSide x MaxSide
+// box. Images smaller than the box are not upscaled.
+type RenditionSpec struct {
+	Name    string
+	MaxSide int
+}
+
+type Rendition struct {
+	Name   string
+	Format string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+type Result struct {
+	// Original is the uploaded file with metadata removed.
+	Original []byte
+	// Width and Height are the display dimensions, after orientation.
+	Width      int
+	Height     int
+	Renditions []Rendition
+}
+
+// Process strips metadata from data and renders every spec as JPEG and
+// WebP. Renditions are produced from the largest spec down, each from
+// the previous one, which keeps resampling of large photos cheap.
+func Process(data []byte, contentType string, specs []RenditionSpec, quality int) (*Result, error) {
+	original, orientation, err := StripMetadata(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrDecode(err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrDecode(err)
+	}
+
+	result := &Result{Original: original}
+	result.Width, result.Height = cfg.Width, cfg.Height
+	if orientation >= 5 {
+		result.Width, result.Height = cfg.Height, cfg.Width
+	}
+
+	sorted := make([]RenditionSpec, len(specs))
+	copy(sorted, specs)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j].MaxSide > sorted[j-1].MaxSide; j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+
+	var current image.Image = src
+	for _, spec := range sorted {
+		resized := fit(current, spec.MaxSide)
+		current = resized
+		oriented := orient(resized, orientation)
+
+		var jpegBuf bytes.Buffer
+		if err := jpeg.Encode(&jpegBuf, oriented, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, ErrEncode(FormatJPEG, err)
+		}
+		var webpBuf bytes.Buffer
+		if err := EncodeWebP(&webpBuf, oriented, quality); err != nil {
+			return nil, ErrEncode(FormatWebP, err)
+		}
+
+		w, h := oriented.Bounds().Dx(), oriented.Bounds().Dy()
+		result.Renditions = append(result.Renditions,
+			Rendition{Name: spec.Name, Format: FormatJPEG, Width: w, Height: h, Data: jpegBuf.Bytes()},
+			Rendition{Name: spec.Name, Format: FormatWebP, Width: w, Height: h, Data: webpBuf.Bytes()},
+		)
+	}
+
+	return result, nil
+}
+
+// fit scales img to fit into a maxSide box on a white background, so
+// transparent PNGs do not turn black in JPEG renditions.
+func fit(img image.Image, maxSide int) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			h = max(1, h*maxSide/w)
+			w = maxSide
+		} else {
+			w = max(1, w*maxSide/h)
+			h = maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	if w == b.Dx() && h == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+	}
+	return dst
+}
+
+// orient applies an EXIF orientation so that the image displays upright
+// once the metadata is gone.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-dx, dy
+			case 3: // rotated 180
+				sx, sy = w-1-dx, h-1-dy
+			case 4: // mirrored vertically
+				sx, sy = dx, h-1-dy
+			case 5: // transposed
+				sx, sy = dy, dx
+			case 6: // rotated 90 clockwise
+				sx, sy = dy, h-1-dx
+			case 7: // transversed
+				sx, sy = w-1-dy, h-1-dx
+			case 8: // rotated 90 counter-clockwise
+				sx, sy = w-1-dy, dx
+			}
+			si := img.PixOffset(sx, sy)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+	return dst
+}
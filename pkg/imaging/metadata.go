@@ -0,0 +1,273 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+const orientationTag = 0x0112
+
+// VP8X flags of the metadata chunks a WebP file carries.
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// StripMetadata removes EXIF, XMP, IPTC and text metadata, which can carry
+// GPS coordinates and camera serial numbers, from an encoded JPEG, PNG or
+// WebP file without re-encoding it. It also returns the EXIF orientation
+// (1 when absent). An orientation other than 1 is written back as a
+// minimal EXIF block so the original still displays upright.
+func StripMetadata(data []byte, contentType string) ([]byte, int, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	default:
+		return nil, 0, ErrUnsupportedFormat
+	}
+}
+
+func stripJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, 0, ErrDecode(errMalformed("jpeg"))
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xff, 0xd8)
+	orientation := 1
+	insertAt := len(out)
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xff {
+			return nil, 0, ErrDecode(errMalformed("jpeg"))
+		}
+		marker := data[pos+1]
+		if marker == 0xff {
+			pos++ // fill byte
+			continue
+		}
+		if marker == 0xda {
+			// Start of scan: the rest is entropy-coded data.
+			out = append(out, data[pos:]...)
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, ErrDecode(errMalformed("jpeg"))
+		}
+		payload := data[pos+4 : end]
+
+		switch marker {
+		case 0xe1: // APP1: EXIF or XMP
+			if bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+				orientation = exifOrientation(payload[6:])
+			}
+		case 0xed, 0xfe: // APP13 (IPTC), comment
+		default:
+			out = append(out, data[pos:end]...)
+			if marker == 0xe0 {
+				insertAt = len(out)
+			}
+		}
+		pos = end
+	}
+
+	if orientation != 1 {
+		segment := orientationSegment(orientation)
+		out = append(out[:insertAt], append(segment, out[insertAt:]...)...)
+	}
+
+	return out, orientation, nil
+}
+
+func stripPNG(data []byte) ([]byte, int, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, 0, ErrDecode(errMalformed("png"))
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	orientation := 1
+	insertAt := -1
+
+	pos := len(pngSignature)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		if length > len(data) {
+			return nil, 0, ErrDecode(errMalformed("png"))
+		}
+		end := pos + 12 + length
+		if end > len(data) {
+			return nil, 0, ErrDecode(errMalformed("png"))
+		}
+		chunkType := string(data[pos+4 : pos+8])
+
+		switch chunkType {
+		case "eXIf":
+			orientation = exifOrientation(data[pos+8 : pos+8+length])
+		case "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[pos:end]...)
+			if chunkType == "IHDR" {
+				insertAt = len(out)
+			}
+		}
+		pos = end
+	}
+
+	// eXIf has to come before the image data, so it goes right after the
+	// header.
+	if orientation != 1 && insertAt > 0 {
+		chunk := pngChunk("eXIf", orientationTIFF(orientation))
+		out = append(out[:insertAt], append(chunk, out[insertAt:]...)...)
+	}
+
+	return out, orientation, nil
+}
+
+func stripWebP(data []byte) ([]byte, int, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, 0, ErrDecode(errMalformed("webp"))
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	orientation := 1
+	flagsAt := -1
+
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkType := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if length > len(data) {
+			return nil, 0, ErrDecode(errMalformed("webp"))
+		}
+		end := pos + 8 + length + length&1
+		if end > len(data) {
+			return nil, 0, ErrDecode(errMalformed("webp"))
+		}
+
+		switch chunkType {
+		case "EXIF":
+			payload := bytes.TrimPrefix(data[pos+8:pos+8+length], []byte("Exif\x00\x00"))
+			orientation = exifOrientation(payload)
+		case "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			if length > 0 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+				flagsAt = len(out) + 8
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	// Only the extended format can carry EXIF, which goes after the image
+	// data.
+	if orientation != 1 && flagsAt > 0 {
+		out[flagsAt] |= webpFlagEXIF
+		out = append(out, webpChunk("EXIF", orientationTIFF(orientation))...)
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, orientation, nil
+}
+
+// exifOrientation reads the orientation tag from a TIFF-structured EXIF
+// block, returning 1 when it is missing or invalid.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			v := int(order.Uint16(tiff[entry+8:]))
+			if v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orientationTIFF builds an EXIF block with the orientation tag only.
+func orientationTIFF(orientation int) []byte {
+	return []byte{
+		'M', 'M', 0x00, 0x2a, 0x00, 0x00, 0x00, 0x08, // header, IFD0 at offset 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, // orientation, SHORT, count 1
+		0x00, byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+}
+
+// orientationSegment builds a JPEG APP1 segment holding the EXIF block
+// of orientationTIFF.
+func orientationSegment(orientation int) []byte {
+	payload := append([]byte("Exif\x00\x00"), orientationTIFF(orientation)...)
+
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// pngChunk builds a PNG chunk with its CRC.
+func pngChunk(chunkType string, payload []byte) []byte {
+	chunk := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(chunk, uint32(len(payload)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// webpChunk builds a RIFF chunk, padded to an even size.
+func webpChunk(chunkType string, payload []byte) []byte {
+	chunk := make([]byte, 8, 9+len(payload))
+	copy(chunk, chunkType)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(payload)))
+	chunk = append(chunk, payload...)
+	if len(payload)&1 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+type errMalformed string
+
+func (e errMalformed) Error() string {
+	return "malformed " + string(e) + " file"
+}
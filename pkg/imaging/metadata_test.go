@@ -0,0 +1,283 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// exifWithGPS builds a little-endian EXIF block holding an orientation
+// and a GPS IFD pointer, as phones write them.
+func exifWithGPS(orientation int) []byte {
+	tiff := []byte{'I', 'I', 0x2a, 0x00, 0x08, 0x00, 0x00, 0x00}
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = append(tiff, 0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, byte(orientation), 0x00, 0x00, 0x00)
+	tiff = append(tiff, 0x25, 0x88, 0x04, 0x00, 0x01, 0x00, 0x00, 0x00, 0x26, 0x00, 0x00, 0x00)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+	return append(tiff, "GPS 50.4501 30.5234"...)
+}
+
+const testXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF/></x:xmpmeta>`
+
+func testJPEG(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(40, 20), nil); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	segment := func(marker byte, payload []byte) []byte {
+		s := []byte{0xff, marker, 0, 0}
+		binary.BigEndian.PutUint16(s[2:], uint16(len(payload)+2))
+		return append(s, payload...)
+	}
+	out := append([]byte(nil), encoded[:2]...)
+	out = append(out, segment(0xe1, append([]byte("Exif\x00\x00"), exifWithGPS(orientation)...))...)
+	out = append(out, segment(0xe1, []byte("http://ns.adobe.com/xap/1.0/\x00"+testXMP))...)
+	out = append(out, segment(0xfe, []byte("shot on a phone"))...)
+	return append(out, encoded[2:]...)
+}
+
+func testPNG(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(40, 20)); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	ihdrEnd := len(pngSignature) + 12 + 13
+	out := append([]byte(nil), encoded[:ihdrEnd]...)
+	out = append(out, pngChunk("eXIf", exifWithGPS(orientation))...)
+	out = append(out, pngChunk("tEXt", []byte("Comment\x00shot on a phone"))...)
+	out = append(out, pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"+testXMP))...)
+	return append(out, encoded[ihdrEnd:]...)
+}
+
+func testWebP(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, testImage(40, 20), 80); err != nil {
+		t.Fatal(err)
+	}
+	vp8 := buf.Bytes()[12:]
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpFlagEXIF | webpFlagXMP
+	vp8x[4], vp8x[7] = 40-1, 20-1
+
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	out = append(out, webpChunk("VP8X", vp8x)...)
+	out = append(out, vp8...)
+	out = append(out, webpChunk("EXIF", exifWithGPS(orientation))...)
+	out = append(out, webpChunk("XMP ", []byte(testXMP))...)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
+
+func TestStripMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		build       func(*testing.T, int) []byte
+		decode      func([]byte) (image.Image, error)
+	}{
+		{"jpeg", "image/jpeg", testJPEG, func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }},
+		{"png", "image/png", testPNG, func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }},
+		{"webp", "image/webp", testWebP, func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) }},
+	}
+	for _, tt := range tests {
+		for _, orientation := range []int{1, 6} {
+			t.Run(tt.name, func(t *testing.T) {
+				data := tt.build(t, orientation)
+				out, got, err := StripMetadata(data, tt.contentType)
+				if err != nil {
+					t.Fatalf("StripMetadata: %v", err)
+				}
+				if got != orientation {
+					t.Errorf("orientation = %d, want %d", got, orientation)
+				}
+
+				for _, leak := range []string{"GPS", "xmpmeta", "shot on a phone"} {
+					if bytes.Contains(out, []byte(leak)) {
+						t.Errorf("output still contains %q", leak)
+					}
+				}
+				kept := bytes.Contains(out, orientationTIFF(orientation))
+				if want := orientation != 1; kept != want {
+					t.Errorf("orientation block kept = %v, want %v", kept, want)
+				}
+
+				img, err := tt.decode(out)
+				if err != nil {
+					t.Fatalf("decoding the stripped file: %v", err)
+				}
+				if size := img.Bounds().Size(); size != image.Pt(40, 20) {
+					t.Errorf("size %v, want 40x20", size)
+				}
+				if _, again, err := StripMetadata(out, tt.contentType); err != nil || again != orientation {
+					t.Errorf("stripping again: orientation %d, %v", again, err)
+				}
+			})
+		}
+	}
+}
+
+func TestStripWebPFlags(t *testing.T) {
+	for _, orientation := range []int{1, 6} {
+		out, _, err := StripMetadata(testWebP(t, orientation), "image/webp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+			t.Errorf("RIFF size %d, want %d", size, len(out)-8)
+		}
+		want := byte(0)
+		if orientation != 1 {
+			want = webpFlagEXIF
+		}
+		if flags := out[20]; flags != want {
+			t.Errorf("orientation %d: VP8X flags %#x, want %#x", orientation, flags, want)
+		}
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		tiff []byte
+		want int
+	}{
+		{"big endian", orientationTIFF(3), 3},
+		{"little endian", exifWithGPS(8), 8},
+		{"out of range", orientationTIFF(9), 1},
+		{"empty", nil, 1},
+		{"bad byte order", []byte("XX\x00\x2a\x00\x00\x00\x08\x00\x00"), 1},
+		{"IFD past the end", []byte("MM\x00\x2a\x7f\xff\xff\xff"), 1},
+		{"IFD inside the header", []byte("MM\x00\x2a\x00\x00\x00\x02\x00\x01"), 1},
+		{"entry count past the end", []byte("MM\x00\x2a\x00\x00\x00\x08\xff\xff\x01\x12"), 1},
+	}
+	for _, tt := range tests {
+		if got := exifOrientation(tt.tiff); got != tt.want {
+			t.Errorf("%s: exifOrientation() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	specs := []RenditionSpec{{Name: "thumb", MaxSide: 16}, {Name: "large", MaxSide: 64}}
+	builds := map[string]func(*testing.T, int) []byte{
+		"image/jpeg": testJPEG,
+		"image/png":  testPNG,
+		"image/webp": testWebP,
+	}
+	for contentType, build := range builds {
+		for _, orientation := range []int{1, 6} {
+			result, err := Process(build(t, orientation), contentType, specs, 80)
+			if err != nil {
+				t.Fatalf("%s: Process: %v", contentType, err)
+			}
+
+			w, h := 40, 20
+			if orientation == 6 {
+				w, h = h, w
+			}
+			if result.Width != w || result.Height != h {
+				t.Errorf("%s: size %dx%d, want %dx%d", contentType, result.Width, result.Height, w, h)
+			}
+			if len(result.Renditions) != 4 {
+				t.Fatalf("%s: %d renditions, want 4", contentType, len(result.Renditions))
+			}
+
+			for _, r := range result.Renditions {
+				var img image.Image
+				switch r.Format {
+				case FormatJPEG:
+					img, err = jpeg.Decode(bytes.NewReader(r.Data))
+				case FormatWebP:
+					img, err = webp.Decode(bytes.NewReader(r.Data))
+				}
+				if err != nil {
+					t.Fatalf("%s: decoding %s %s: %v", contentType, r.Name, r.Format, err)
+				}
+				if size := img.Bounds().Size(); size != image.Pt(r.Width, r.Height) {
+					t.Errorf("%s: %s %s is %v, reported %dx%d", contentType, r.Name, r.Format, size, r.Width, r.Height)
+				}
+				if r.Width > w || r.Height > h || (r.Width > r.Height) != (w > h) {
+					t.Errorf("%s: %s %s is %dx%d for a %dx%d image", contentType, r.Name, r.Format, r.Width, r.Height, w, h)
+				}
+			}
+		}
+	}
+}
+
+func TestProcessRejectsUnsupportedFormat(t *testing.T) {
+	if _, err := Process([]byte("GIF89a"), "image/gif", nil, 80); err != ErrUnsupportedFormat {
+		t.Errorf("Process() error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+// TestMalformedInput feeds truncated and corrupted files to StripMetadata
+// and Process, which have to fail cleanly rather than panic.
+func TestMalformedInput(t *testing.T) {
+	builds := map[string]func(*testing.T, int) []byte{
+		"image/jpeg": testJPEG,
+		"image/png":  testPNG,
+		"image/webp": testWebP,
+	}
+	specs := []RenditionSpec{{Name: "thumb", MaxSide: 16}}
+
+	check := func(t *testing.T, contentType string, data []byte, what string) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("%s %s: panic: %v", contentType, what, r)
+			}
+		}()
+		StripMetadata(data, contentType)
+		Process(data, contentType, specs, 80)
+	}
+
+	for contentType, build := range builds {
+		valid := build(t, 6)
+		for n := 0; n < len(valid); n++ {
+			check(t, contentType, valid[:n], "truncated")
+		}
+		for i := range valid {
+			for _, b := range []byte{0x00, 0xff, valid[i] ^ 0x80} {
+				corrupted := append([]byte(nil), valid...)
+				corrupted[i] = b
+				check(t, contentType, corrupted, "corrupted")
+			}
+		}
+	}
+}
+
+func TestHostileLengths(t *testing.T) {
+	huge := []byte{0xff, 0xff, 0xff, 0xff}
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+	}{
+		{"jpeg segment shorter than its length field", "image/jpeg", []byte{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x01, 0x00, 0x00}},
+		{"jpeg segment past the end", "image/jpeg", []byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff, 0x00, 0x00}},
+		{"jpeg without a marker", "image/jpeg", []byte{0xff, 0xd8, 0x00, 0x00, 0x00, 0x00}},
+		{"png chunk of 4 GiB", "image/png", append(append(append([]byte(nil), pngSignature...), huge...), "eXIf\x00\x00\x00\x00"...)},
+		{"png chunk past the end", "image/png", append(append([]byte(nil), pngSignature...), "\x00\x00\x01\x00eXIf\x00\x00\x00\x00"...)},
+		{"webp chunk of 4 GiB", "image/webp", append([]byte("RIFF\x00\x00\x00\x00WEBPEXIF"), huge...)},
+		{"webp chunk past the end", "image/webp", []byte("RIFF\x00\x00\x00\x00WEBPEXIF\x10\x00\x00\x00MM")},
+		{"webp chunk missing its padding", "image/webp", []byte("RIFF\x00\x00\x00\x00WEBPXMP \x01\x00\x00\x00x")},
+	}
+	for _, tt := range tests {
+		if _, _, err := StripMetadata(tt.data, tt.contentType); err == nil {
+			t.Errorf("%s: StripMetadata accepted the file", tt.name)
+		}
+	}
+}
@@ -0,0 +1,829 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+)
+
+// This file implements a baseline lossy VP8 key frame encoder (RFC 6386).
+// It is deliberately small: every macroblock uses 16x16 luma and 8x8
+// chroma intra prediction, there is a single quantizer and a single
+// token partition, and the default token probabilities are kept. That
+// is enough to produce photos somewhat smaller than JPEG at a similar
+// visual quality, without cgo.
+
+const (
+	predDC = iota
+	predTM
+	predVE
+	predHE
+	numPredModes
+)
+
+const (
+	planeYAfterY2 = iota
+	planeY2
+	planeUV
+)
+
+const maxVP8Dimension = 16383
+
+var (
+	bands  = [17]uint8{0, 1, 2, 3, 6, 4, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 0}
+	zigzag = [16]uint8{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+
+	// Extra-bit probabilities of DCT token categories 3 to 6.
+	cat3456 = [4][]uint8{
+		{173, 148, 140},
+		{176, 155, 140, 135},
+		{180, 157, 141, 134, 130},
+		{254, 254, 243, 230, 196, 177, 153, 140, 133, 130, 129},
+	}
+
+	dcTable = [128]int32{
+		4, 5, 6, 7, 8, 9, 10, 10, 11, 12, 13, 14, 15, 16, 17, 17,
+		18, 19, 20, 20, 21, 21, 22, 22, 23, 23, 24, 25, 25, 26, 27, 28,
+		29, 30, 31, 32, 33, 34, 35, 36, 37, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 46, 47, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58,
+		59, 60, 61, 62, 63, 64, 65, 66, 67, 68, 69, 70, 71, 72, 73, 74,
+		75, 76, 76, 77, 78, 79, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89,
+		91, 93, 95, 96, 98, 100, 101, 102, 104, 106, 108, 110, 112, 114, 116, 118,
+		122, 124, 126, 128, 130, 132, 134, 136, 138, 140, 143, 145, 148, 151, 154, 157,
+	}
+	acTable = [128]int32{
+		4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19,
+		20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35,
+		36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
+		52, 53, 54, 55, 56, 57, 58, 60, 62, 64, 66, 68, 70, 72, 74, 76,
+		78, 80, 82, 84, 86, 88, 90, 92, 94, 96, 98, 100, 102, 104, 106, 108,
+		110, 112, 114, 116, 119, 122, 125, 128, 131, 134, 137, 140, 143, 146, 149, 152,
+		155, 158, 161, 164, 167, 170, 173, 177, 181, 185, 189, 193, 197, 201, 205, 209,
+		213, 217, 221, 225, 229, 234, 239, 245, 249, 254, 259, 264, 269, 274, 279, 284,
+	}
+)
+
+// quantStep holds the DC and AC quantizer steps of one coefficient type.
+type quantStep [2]int32
+
+// nzContext tracks which neighbouring 4x4 blocks had non-zero
+// coefficients; token probabilities depend on it.
+type nzContext struct {
+	y  [4]uint8
+	u  [2]uint8
+	v  [2]uint8
+	y2 uint8
+}
+
+type macroblock struct {
+	yMode  int
+	uvMode int
+	skip   bool
+}
+
+type vp8Encoder struct {
+	width, height int
+	mbw, mbh      int
+
+	// Source planes padded to whole macroblocks, and the reconstruction
+	// the decoder will see, used for prediction.
+	src, rec [3][]uint8
+	stride   [3]int
+
+	qi         int
+	y1, y2, uv quantStep
+
+	mbs    []macroblock
+	tokens *boolEncoder
+	top    []nzContext
+	left   nzContext
+}
+
+// encodeVP8 returns a VP8 key frame for img. quality is 1-100.
+func encodeVP8(img image.Image, quality int) ([]byte, error) {
+	b := img.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return nil, errors.New("vp8: empty image")
+	}
+	if b.Dx() > maxVP8Dimension || b.Dy() > maxVP8Dimension {
+		return nil, errors.New("vp8: image is too large")
+	}
+
+	e := &vp8Encoder{
+		width:  b.Dx(),
+		height: b.Dy(),
+		mbw:    (b.Dx() + 15) / 16,
+		mbh:    (b.Dy() + 15) / 16,
+		tokens: newBoolEncoder(),
+	}
+	e.setQuality(quality)
+	e.loadSource(img)
+
+	e.mbs = make([]macroblock, e.mbw*e.mbh)
+	e.top = make([]nzContext, e.mbw)
+	for mby := 0; mby < e.mbh; mby++ {
+		e.left = nzContext{}
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+
+	return e.frame(), nil
+}
+
+func (e *vp8Encoder) setQuality(quality int) {
+	if quality < 1 {
+		quality = 1
+	}
+	if quality > 100 {
+		quality = 100
+	}
+	e.qi = (100 - quality) * 127 / 100
+
+	e.y1 = quantStep{dcTable[e.qi], acTable[e.qi]}
+	e.y2 = quantStep{dcTable[e.qi] * 2, acTable[e.qi] * 155 / 100}
+	if e.y2[1] < 8 {
+		e.y2[1] = 8
+	}
+	uvDC := dcTable[e.qi]
+	if uvDC > 132 {
+		uvDC = 132
+	}
+	e.uv = quantStep{uvDC, acTable[e.qi]}
+}
+
+// loadSource converts img to limited-range BT.601 YUV 4:2:0, the colour
+// space browsers assume for VP8. Transparent pixels are composited onto
+// white, as the encoder does not write an alpha channel.
+func (e *vp8Encoder) loadSource(img image.Image) {
+	yw, yh := e.mbw*16, e.mbh*16
+	cw, ch := e.mbw*8, e.mbh*8
+	e.stride = [3]int{yw, cw, cw}
+	for p, size := range []int{yw * yh, cw * ch, cw * ch} {
+		e.src[p] = make([]uint8, size)
+		e.rec[p] = make([]uint8, size)
+	}
+
+	b := img.Bounds()
+	rgba, _ := img.(*image.RGBA)
+
+	pixel := func(x, y int) (r, g, b32 int32) {
+		// Pad by repeating the last row and column.
+		if x >= e.width {
+			x = e.width - 1
+		}
+		if y >= e.height {
+			y = e.height - 1
+		}
+		var cr, cg, cb, ca uint32
+		if rgba != nil {
+			i := rgba.PixOffset(b.Min.X+x, b.Min.Y+y)
+			s := rgba.Pix[i : i+4 : i+4]
+			cr, cg, cb, ca = uint32(s[0])*0x101, uint32(s[1])*0x101, uint32(s[2])*0x101, uint32(s[3])*0x101
+		} else {
+			cr, cg, cb, ca = img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+		}
+		if ca != 0xffff {
+			cr += 0xffff - ca
+			cg += 0xffff - ca
+			cb += 0xffff - ca
+		}
+		return int32(cr >> 8), int32(cg >> 8), int32(cb >> 8)
+	}
+
+	for y := 0; y < yh; y += 2 {
+		for x := 0; x < yw; x += 2 {
+			var su, sv int32
+			for dy := 0; dy < 2; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					r, g, bl := pixel(x+dx, y+dy)
+					e.src[0][(y+dy)*yw+x+dx] = uint8((16839*r + 33059*g + 6420*bl + 16<<16 + 1<<15) >> 16)
+					su += -9719*r - 19081*g + 28800*bl
+					sv += 28800*r - 24116*g - 4684*bl
+				}
+			}
+			ci := (y/2)*cw + x/2
+			e.src[1][ci] = clamp255((su + 128<<18 + 1<<17) >> 18)
+			e.src[2][ci] = clamp255((sv + 128<<18 + 1<<17) >> 18)
+		}
+	}
+}
+
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	mb := &e.mbs[mby*e.mbw+mbx]
+
+	var yLevels [16][16]int32
+	var y2Levels [16]int32
+	var uvLevels [2][4][16]int32
+
+	// Luma: pick the 16x16 predictor closest to the source.
+	yPred := e.bestPrediction(0, 16, mbx, mby, &mb.yMode)
+
+	var dcs [16]int32
+	var coeffs [16][16]int32
+	for n := 0; n < 16; n++ {
+		bx, by := mbx*16+(n%4)*4, mby*16+(n/4)*4
+		coeffs[n] = forwardDCT(e.residual(0, bx, by, yPred[:], 16, (n%4)*4, (n/4)*4))
+		dcs[n] = coeffs[n][0]
+	}
+
+	// The luma DC coefficients go through the second-order WHT block.
+	wht := forwardWHT(dcs)
+	var y2Deq [16]int32
+	for i := range wht {
+		y2Levels[i] = quantize(wht[i], e.y2[btoi(i > 0)], i == 0)
+		y2Deq[i] = y2Levels[i] * e.y2[btoi(i > 0)]
+	}
+	dcRec := inverseWHT(y2Deq)
+
+	for n := 0; n < 16; n++ {
+		var deq [16]int32
+		deq[0] = dcRec[n]
+		for i := 1; i < 16; i++ {
+			yLevels[n][i] = quantize(coeffs[n][i], e.y1[1], false)
+			deq[i] = yLevels[n][i] * e.y1[1]
+		}
+		e.reconstruct(0, mbx*16+(n%4)*4, mby*16+(n/4)*4, yPred[:], 16, (n%4)*4, (n/4)*4, deq)
+	}
+
+	// Chroma: one predictor for both planes.
+	uvPred := e.bestChromaPrediction(mbx, mby, &mb.uvMode)
+	for p := 1; p <= 2; p++ {
+		pred := uvPred[p-1]
+		for n := 0; n < 4; n++ {
+			bx, by := mbx*8+(n%2)*4, mby*8+(n/2)*4
+			c := forwardDCT(e.residual(p, bx, by, pred[:], 8, (n%2)*4, (n/2)*4))
+			var deq [16]int32
+			for i := range c {
+				step := e.uv[btoi(i > 0)]
+				uvLevels[p-1][n][i] = quantize(c[i], step, i == 0)
+				deq[i] = uvLevels[p-1][n][i] * step
+			}
+			e.reconstruct(p, bx, by, pred[:], 8, (n%2)*4, (n/2)*4, deq)
+		}
+	}
+
+	mb.skip = allZero(y2Levels[:]) && allZero2(yLevels[:]) && allZero2(uvLevels[0][:]) && allZero2(uvLevels[1][:])
+
+	top := &e.top[mbx]
+	if mb.skip {
+		*top = nzContext{}
+		e.left = nzContext{}
+		return
+	}
+
+	nz := e.putBlock(y2Levels, 0, planeY2, top.y2+e.left.y2)
+	top.y2, e.left.y2 = nz, nz
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			nz := e.putBlock(yLevels[y*4+x], 1, planeYAfterY2, top.y[x]+e.left.y[y])
+			top.y[x], e.left.y[y] = nz, nz
+		}
+	}
+
+	for p, levels := range uvLevels {
+		topNz, leftNz := &top.u, &e.left.u
+		if p == 1 {
+			topNz, leftNz = &top.v, &e.left.v
+		}
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 2; x++ {
+				nz := e.putBlock(levels[y*2+x], 0, planeUV, topNz[x]+leftNz[y])
+				topNz[x], leftNz[y] = nz, nz
+			}
+		}
+	}
+}
+
+// edges returns the reconstructed row above, the column to the left
+// and the top-left corner of a size x size block, using the values the
+// decoder substitutes at the frame edges.
+func (e *vp8Encoder) edges(p, size, mbx, mby int) (above, left []int32, topLeft int32) {
+	above = make([]int32, size)
+	left = make([]int32, size)
+	stride := e.stride[p]
+	x0, y0 := mbx*size, mby*size
+
+	for i := 0; i < size; i++ {
+		if mby == 0 {
+			above[i] = 127
+		} else {
+			above[i] = int32(e.rec[p][(y0-1)*stride+x0+i])
+		}
+		if mbx == 0 {
+			left[i] = 129
+		} else {
+			left[i] = int32(e.rec[p][(y0+i)*stride+x0-1])
+		}
+	}
+
+	switch {
+	case mby == 0:
+		topLeft = 127
+	case mbx == 0:
+		topLeft = 129
+	default:
+		topLeft = int32(e.rec[p][(y0-1)*stride+x0-1])
+	}
+	return above, left, topLeft
+}
+
+func predict(mode, size, mbx, mby int, above, left []int32, topLeft int32, out []int32) {
+	switch mode {
+	case predDC:
+		var dc int32
+		shift := 3
+		if size == 16 {
+			shift = 4
+		}
+		switch {
+		case mbx == 0 && mby == 0:
+			dc = 128
+		case mbx == 0:
+			dc = (sum(above) + 1<<(shift-1)) >> shift
+		case mby == 0:
+			dc = (sum(left) + 1<<(shift-1)) >> shift
+		default:
+			dc = (sum(above) + sum(left) + 1<<shift) >> (shift + 1)
+		}
+		for i := range out {
+			out[i] = dc
+		}
+	case predTM:
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				out[y*size+x] = int32(clamp255(left[y] + above[x] - topLeft))
+			}
+		}
+	case predVE:
+		for y := 0; y < size; y++ {
+			copy(out[y*size:(y+1)*size], above)
+		}
+	case predHE:
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				out[y*size+x] = left[y]
+			}
+		}
+	}
+}
+
+func (e *vp8Encoder) bestPrediction(p, size, mbx, mby int, mode *int) [256]int32 {
+	above, left, topLeft := e.edges(p, size, mbx, mby)
+
+	var best [256]int32
+	bestCost := int64(-1)
+	var pred [256]int32
+	for m := 0; m < numPredModes; m++ {
+		predict(m, size, mbx, mby, above, left, topLeft, pred[:size*size])
+		cost := e.sad(p, size, mbx, mby, pred[:size*size])
+		if bestCost < 0 || cost < bestCost {
+			bestCost, best, *mode = cost, pred, m
+		}
+	}
+	return best
+}
+
+func (e *vp8Encoder) bestChromaPrediction(mbx, mby int, mode *int) [2][64]int32 {
+	var best [2][64]int32
+	bestCost := int64(-1)
+
+	au, lu, tlu := e.edges(1, 8, mbx, mby)
+	av, lv, tlv := e.edges(2, 8, mbx, mby)
+	for m := 0; m < numPredModes; m++ {
+		var pred [2][64]int32
+		predict(m, 8, mbx, mby, au, lu, tlu, pred[0][:])
+		predict(m, 8, mbx, mby, av, lv, tlv, pred[1][:])
+		cost := e.sad(1, 8, mbx, mby, pred[0][:]) + e.sad(2, 8, mbx, mby, pred[1][:])
+		if bestCost < 0 || cost < bestCost {
+			bestCost, best, *mode = cost, pred, m
+		}
+	}
+	return best
+}
+
+func (e *vp8Encoder) sad(p, size, mbx, mby int, pred []int32) int64 {
+	stride := e.stride[p]
+	var total int64
+	for y := 0; y < size; y++ {
+		row := e.src[p][(mby*size+y)*stride+mbx*size:]
+		for x := 0; x < size; x++ {
+			d := int64(row[x]) - int64(pred[y*size+x])
+			if d < 0 {
+				d = -d
+			}
+			total += d
+		}
+	}
+	return total
+}
+
+// residual returns source minus prediction for the 4x4 block at (bx, by)
+// in plane p; (px, py) is the block's offset inside pred.
+func (e *vp8Encoder) residual(p, bx, by int, pred []int32, predStride, px, py int) [16]int32 {
+	var r [16]int32
+	stride := e.stride[p]
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			r[y*4+x] = int32(e.src[p][(by+y)*stride+bx+x]) - pred[(py+y)*predStride+px+x]
+		}
+	}
+	return r
+}
+
+// reconstruct adds the inverse transform of deq to the prediction and
+// stores the result, exactly as the decoder will.
+func (e *vp8Encoder) reconstruct(p, bx, by int, pred []int32, predStride, px, py int, deq [16]int32) {
+	res := inverseDCT(deq)
+	stride := e.stride[p]
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			e.rec[p][(by+y)*stride+bx+x] = clamp255(pred[(py+y)*predStride+px+x] + res[y*4+x])
+		}
+	}
+}
+
+// putBlock writes the tokens of one 4x4 block and reports whether it had
+// any non-zero coefficient.
+func (e *vp8Encoder) putBlock(levels [16]int32, first, plane int, ctx uint8) uint8 {
+	probs := &defaultCoeffProbs[plane]
+
+	last := -1
+	for n := first; n < 16; n++ {
+		if levels[zigzag[n]] != 0 {
+			last = n
+		}
+	}
+
+	p := probs[bands[first]][ctx]
+	if last < 0 {
+		e.tokens.putBit(false, p[0])
+		return 0
+	}
+	e.tokens.putBit(true, p[0])
+
+	for n := first; n < 16; {
+		v := levels[zigzag[n]]
+		n++
+		if v == 0 {
+			e.tokens.putBit(false, p[1])
+			p = probs[bands[n]][0]
+			continue
+		}
+		e.tokens.putBit(true, p[1])
+
+		abs := v
+		if abs < 0 {
+			abs = -abs
+		}
+		if abs == 1 {
+			e.tokens.putBit(false, p[2])
+			p = probs[bands[n]][1]
+		} else {
+			e.tokens.putBit(true, p[2])
+			e.putLargeValue(abs, p)
+			p = probs[bands[n]][2]
+		}
+		e.tokens.putBit(v < 0, 128)
+
+		if n == 16 {
+			break
+		}
+		if n > last {
+			e.tokens.putBit(false, p[0])
+			break
+		}
+		e.tokens.putBit(true, p[0])
+	}
+	return 1
+}
+
+// putLargeValue writes the token for a coefficient magnitude of 2 or
+// more, after the "not one" bit.
+func (e *vp8Encoder) putLargeValue(v int32, p [numProbs]uint8) {
+	t := e.tokens
+	switch {
+	case v <= 4:
+		t.putBit(false, p[3])
+		if v == 2 {
+			t.putBit(false, p[4])
+		} else {
+			t.putBit(true, p[4])
+			t.putBit(v == 4, p[5])
+		}
+	case v <= 10:
+		t.putBit(true, p[3])
+		t.putBit(false, p[6])
+		if v <= 6 {
+			t.putBit(false, p[7])
+			t.putBit(v == 6, 159)
+		} else {
+			t.putBit(true, p[7])
+			t.putBit((v-7)&2 != 0, 165)
+			t.putBit((v-7)&1 != 0, 145)
+		}
+	default:
+		t.putBit(true, p[3])
+		t.putBit(true, p[6])
+		cat := 3
+		switch {
+		case v < 19:
+			cat = 0
+		case v < 35:
+			cat = 1
+		case v < 67:
+			cat = 2
+		}
+		t.putBit(cat >= 2, p[8])
+		t.putBit(cat&1 == 1, p[9+cat>>1])
+		extra := v - (3 + 8<<uint(cat))
+		tab := cat3456[cat]
+		for i, prob := range tab {
+			t.putBit(extra>>uint(len(tab)-1-i)&1 == 1, prob)
+		}
+	}
+}
+
+// frame assembles the key frame: frame tag, key frame header, the first
+// partition (headers and modes) and the token partition.
+func (e *vp8Encoder) frame() []byte {
+	nonSkipped := 0
+	for _, mb := range e.mbs {
+		if !mb.skip {
+			nonSkipped++
+		}
+	}
+	skipProb := nonSkipped * 256 / len(e.mbs)
+	if skipProb < 1 {
+		skipProb = 1
+	}
+	if skipProb > 255 {
+		skipProb = 255
+	}
+
+	fp := newBoolEncoder()
+	fp.putLiteral(0, 1) // colour space
+	fp.putLiteral(0, 1) // clamping required
+	fp.putLiteral(0, 1) // no segmentation
+	fp.putLiteral(0, 1) // normal loop filter
+	fp.putLiteral(uint32(loopFilterLevel(e.qi)), 6)
+	fp.putLiteral(0, 3) // sharpness
+	fp.putLiteral(0, 1) // no loop filter deltas
+	fp.putLiteral(0, 2) // one token partition
+	fp.putLiteral(uint32(e.qi), 7)
+	for i := 0; i < 5; i++ {
+		fp.putLiteral(0, 1) // no quantizer deltas
+	}
+	fp.putLiteral(0, 1) // refresh entropy probs
+	for i := range coeffUpdateProbs {
+		for j := range coeffUpdateProbs[i] {
+			for k := range coeffUpdateProbs[i][j] {
+				for l := range coeffUpdateProbs[i][j][k] {
+					fp.putBit(false, coeffUpdateProbs[i][j][k][l])
+				}
+			}
+		}
+	}
+	fp.putLiteral(1, 1) // macroblocks may be skipped
+	fp.putLiteral(uint32(skipProb), 8)
+
+	for _, mb := range e.mbs {
+		fp.putBit(mb.skip, uint8(skipProb))
+
+		fp.putBit(true, 145) // 16x16 prediction
+		switch mb.yMode {
+		case predDC:
+			fp.putBit(false, 156)
+			fp.putBit(false, 163)
+		case predVE:
+			fp.putBit(false, 156)
+			fp.putBit(true, 163)
+		case predHE:
+			fp.putBit(true, 156)
+			fp.putBit(false, 128)
+		case predTM:
+			fp.putBit(true, 156)
+			fp.putBit(true, 128)
+		}
+
+		switch mb.uvMode {
+		case predDC:
+			fp.putBit(false, 142)
+		case predVE:
+			fp.putBit(true, 142)
+			fp.putBit(false, 114)
+		case predHE:
+			fp.putBit(true, 142)
+			fp.putBit(true, 114)
+			fp.putBit(false, 183)
+		case predTM:
+			fp.putBit(true, 142)
+			fp.putBit(true, 114)
+			fp.putBit(true, 183)
+		}
+	}
+
+	first := fp.flush()
+	tokens := e.tokens.flush()
+
+	out := make([]byte, 0, 10+len(first)+len(tokens))
+	tag := uint32(len(first))<<5 | 1<<4 // key frame, version 0, shown
+	out = append(out, byte(tag), byte(tag>>8), byte(tag>>16))
+	out = append(out, 0x9d, 0x01, 0x2a)
+	out = append(out, byte(e.width), byte(e.width>>8), byte(e.height), byte(e.height>>8))
+	out = append(out, first...)
+	out = append(out, tokens...)
+	return out
+}
+
+func loopFilterLevel(qi int) int {
+	level := qi * 3 / 8
+	if level > 63 {
+		level = 63
+	}
+	return level
+}
+
+func quantize(c, step int32, isDC bool) int32 {
+	neg := c < 0
+	if neg {
+		c = -c
+	}
+	// A slightly smaller rounding offset for AC coefficients widens the
+	// dead zone, which saves bits on noise without visible loss.
+	bias := step * 3 / 8
+	if isDC {
+		bias = step / 2
+	}
+	level := (c + bias) / step
+	if level > 2048 {
+		level = 2048
+	}
+	if neg {
+		return -level
+	}
+	return level
+}
+
+// forwardDCT is the VP8 4x4 forward DCT (libvpx vp8_short_fdct4x4_c).
+func forwardDCT(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		ip := in[i*4 : i*4+4]
+		a := (ip[0] + ip[3]) * 8
+		b := (ip[1] + ip[2]) * 8
+		c := (ip[1] - ip[2]) * 8
+		d := (ip[0] - ip[3]) * 8
+		tmp[i*4+0] = a + b
+		tmp[i*4+2] = a - b
+		tmp[i*4+1] = (c*2217 + d*5352 + 14500) >> 12
+		tmp[i*4+3] = (d*2217 - c*5352 + 7500) >> 12
+	}
+	for i := 0; i < 4; i++ {
+		a := tmp[i] + tmp[12+i]
+		b := tmp[4+i] + tmp[8+i]
+		c := tmp[4+i] - tmp[8+i]
+		d := tmp[i] - tmp[12+i]
+		out[i] = (a + b + 7) >> 4
+		out[8+i] = (a - b + 7) >> 4
+		out[4+i] = (c*2217+d*5352+12000)>>16 + btoi(d != 0)
+		out[12+i] = (d*2217 - c*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// inverseDCT mirrors the decoder's inverse DCT bit for bit.
+func inverseDCT(in [16]int32) [16]int32 {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := in[i] + in[8+i]
+		b := in[i] - in[8+i]
+		c := (in[4+i]*c2)>>16 - (in[12+i]*c1)>>16
+		d := (in[4+i]*c1)>>16 + (in[12+i]*c2)>>16
+		m[i][0] = a + d
+		m[i][1] = b + c
+		m[i][2] = b - c
+		m[i][3] = a - d
+	}
+	var out [16]int32
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		out[j*4+0] = (a + d) >> 3
+		out[j*4+1] = (b + c) >> 3
+		out[j*4+2] = (b - c) >> 3
+		out[j*4+3] = (a - d) >> 3
+	}
+	return out
+}
+
+// forwardWHT is the VP8 forward Walsh-Hadamard transform of the 16 luma
+// DC coefficients (libvpx vp8_short_walsh4x4_c).
+func forwardWHT(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		ip := in[i*4 : i*4+4]
+		a := (ip[0] + ip[2]) * 4
+		d := (ip[1] + ip[3]) * 4
+		c := (ip[1] - ip[3]) * 4
+		b := (ip[0] - ip[2]) * 4
+		tmp[i*4+0] = a + d + btoi(a != 0)
+		tmp[i*4+1] = b + c
+		tmp[i*4+2] = b - c
+		tmp[i*4+3] = a - d
+	}
+	for i := 0; i < 4; i++ {
+		a := tmp[i] + tmp[8+i]
+		d := tmp[4+i] + tmp[12+i]
+		c := tmp[4+i] - tmp[12+i]
+		b := tmp[i] - tmp[8+i]
+		a2, b2, c2, d2 := a+d, b+c, b-c, a-d
+		a2 += btoi(a2 < 0)
+		b2 += btoi(b2 < 0)
+		c2 += btoi(c2 < 0)
+		d2 += btoi(d2 < 0)
+		out[i] = (a2 + 3) >> 3
+		out[4+i] = (b2 + 3) >> 3
+		out[8+i] = (c2 + 3) >> 3
+		out[12+i] = (d2 + 3) >> 3
+	}
+	return out
+}
+
+// inverseWHT mirrors the decoder's inverse WHT. Output n is the DC
+// coefficient of luma block n.
+func inverseWHT(in [16]int32) [16]int32 {
+	var m, out [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[i] + in[12+i]
+		a1 := in[4+i] + in[8+i]
+		a2 := in[4+i] - in[8+i]
+		a3 := in[i] - in[12+i]
+		m[i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[i*4] + 3
+		a0 := dc + m[i*4+3]
+		a1 := m[i*4+1] + m[i*4+2]
+		a2 := m[i*4+1] - m[i*4+2]
+		a3 := dc - m[i*4+3]
+		out[i*4+0] = (a0 + a1) >> 3
+		out[i*4+1] = (a3 + a2) >> 3
+		out[i*4+2] = (a0 - a1) >> 3
+		out[i*4+3] = (a3 - a2) >> 3
+	}
+	return out
+}
+
+func sum(v []int32) int32 {
+	var s int32
+	for _, x := range v {
+		s += x
+	}
+	return s
+}
+
+func allZero(v []int32) bool {
+	for _, x := range v {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func allZero2(blocks [][16]int32) bool {
+	for i := range blocks {
+		if !allZero(blocks[i][:]) {
+			return false
+		}
+	}
+	return true
+}
+
+func clamp255(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+func btoi(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,36 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"io"
+)
+
+// EncodeWebP writes img as a lossy WebP file. quality is 1-100.
+func EncodeWebP(w io.Writer, img image.Image, quality int) error {
+	frame, err := encodeVP8(img, quality)
+	if err != nil {
+		return err
+	}
+
+	pad := len(frame) & 1
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+len(frame)+pad))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8 ")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(frame)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(frame); err != nil {
+		return err
+	}
+	if pad == 1 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
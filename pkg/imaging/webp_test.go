@@ -0,0 +1,135 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// testImage draws a gradient with a few hard edges, which exercises
+// every prediction mode and both flat and busy macroblocks.
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{
+				R: uint8(x * 255 / max(1, w-1)),
+				G: uint8(y * 255 / max(1, h-1)),
+				B: 128,
+				A: 255,
+			}
+			if (x/8+y/8)%5 == 0 {
+				c = color.RGBA{R: 20, G: 200, B: 40, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// meanError returns the mean absolute difference between the YUV planes
+// the encoder derives from src and the planes webp.Decode returns. The
+// planes are compared directly because x/image/webp converts to RGB as
+// full-range JFIF, while VP8 is limited-range BT.601.
+func meanError(t *testing.T, src *image.RGBA, decoded image.Image) float64 {
+	t.Helper()
+	got, ok := decoded.(*image.YCbCr)
+	if !ok {
+		t.Fatalf("decoded %T, want *image.YCbCr", decoded)
+	}
+	if got.Bounds().Size() != src.Bounds().Size() {
+		t.Fatalf("size %v, want %v", got.Bounds().Size(), src.Bounds().Size())
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	e := &vp8Encoder{width: w, height: h, mbw: (w + 15) / 16, mbh: (h + 15) / 16}
+	e.loadSource(src)
+
+	var total float64
+	var n int
+	diff := func(want uint8, have uint8) {
+		total += math.Abs(float64(want) - float64(have))
+		n++
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			diff(e.src[0][y*e.stride[0]+x], got.Y[got.YOffset(x, y)])
+		}
+	}
+	for y := 0; y < (h+1)/2; y++ {
+		for x := 0; x < (w+1)/2; x++ {
+			c := got.COffset(x*2, y*2)
+			diff(e.src[1][y*e.stride[1]+x], got.Cb[c])
+			diff(e.src[2][y*e.stride[2]+x], got.Cr[c])
+		}
+	}
+	return total / float64(n)
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		w, h     int
+		quality  int
+		maxError float64
+	}{
+		{"single pixel", 1, 1, 80, 3},
+		{"one macroblock", 16, 16, 80, 3},
+		{"partial macroblocks", 17, 33, 80, 3},
+		{"wide", 200, 40, 80, 3},
+		{"tall", 40, 200, 80, 3},
+		{"low quality", 96, 64, 10, 8},
+		{"high quality", 96, 64, 100, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := testImage(tt.w, tt.h)
+
+			var buf bytes.Buffer
+			if err := EncodeWebP(&buf, src, tt.quality); err != nil {
+				t.Fatalf("EncodeWebP: %v", err)
+			}
+			if buf.Len()%2 != 0 {
+				t.Errorf("file size %d is odd", buf.Len())
+			}
+
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("webp.Decode: %v", err)
+			}
+			if e := meanError(t, src, decoded); e > tt.maxError {
+				t.Errorf("mean error %.2f, want at most %.2f", e, tt.maxError)
+			}
+		})
+	}
+}
+
+func TestEncodeWebPFlatImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 48, 48))
+	for i := range src.Pix {
+		src.Pix[i] = 200
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, src, 75); err != nil {
+		t.Fatalf("EncodeWebP: %v", err)
+	}
+	decoded, err := webp.Decode(&buf)
+	if err != nil {
+		t.Fatalf("webp.Decode: %v", err)
+	}
+	if e := meanError(t, src, decoded); e > 1 {
+		t.Errorf("mean error %.2f on a flat image", e)
+	}
+}
+
+func TestEncodeWebPRejectsEmptyImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, image.NewRGBA(image.Rect(0, 0, 0, 0)), 80); err == nil {
+		t.Error("EncodeWebP accepted an empty image")
+	}
+}
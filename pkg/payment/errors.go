@@ -0,0 +1,22 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Common payment package errors
+var (
+	ErrInvalidSignature  = errors.New("invalid callback signature")
+	ErrMalformedCallback = errors.New("malformed callback")
+)
+
+// ErrInvalidConfig creates a configuration validation error
+func ErrInvalidConfig(message string) error {
+	return fmt.Errorf("invalid payment config: %s", message)
+}
+
+// ErrProvider creates a provider error
+func ErrProvider(provider string, err error) error {
+	return fmt.Errorf("payment provider %s error: %w", provider, err)
+}
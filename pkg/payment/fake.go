@@ -0,0 +1,116 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+const (
+	fakeName = "fake"
+	// FakeSignatureHeader carries the hex HMAC-SHA256 of the callback body.
+	FakeSignatureHeader = "X-Fake-Signature"
+)
+
+// FakeProvider is a local provider for development and tests. It never
+// contacts a gateway: the checkout page is the result URL itself, and
+// callbacks are JSON bodies signed with an HMAC-SHA256 of a shared
+// secret, which SignCallback produces.
+type FakeProvider struct {
+	secret []byte
+}
+
+type fakeCallbackBody struct {
+	PaymentID  string `json:"payment_id"`
+	ExternalID string `json:"external_id,omitempty"`
+	Status     Status `json:"status"`
+	Amount     int    `json:"amount"`
+	Currency   string `json:"currency"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// exampleFakeSecret is the secret earlier versions defaulted to. It is
+// public, so it is refused like an empty one.
+const exampleFakeSecret = "fake-payment-secret"
+
+func NewFakeProvider(secret string) (*FakeProvider, error) {
+	if secret == "" {
+		return nil, ErrInvalidConfig("secret is required for the fake provider")
+	}
+	if secret == exampleFakeSecret {
+		return nil, ErrInvalidConfig("the fake provider secret must not be the public example value")
+	}
+	return &FakeProvider{secret: []byte(secret)}, nil
+}
+
+func (p *FakeProvider) Name() string {
+	return fakeName
+}
+
+func (p *FakeProvider) CreateCheckout(ctx context.Context, req CheckoutRequest) (*Checkout, error) {
+	checkoutURL, err := url.Parse(req.ResultURL)
+	if err != nil {
+		return nil, ErrProvider(fakeName, err)
+	}
+	query := checkoutURL.Query()
+	query.Set("payment_id", req.PaymentID)
+	checkoutURL.RawQuery = query.Encode()
+
+	return &Checkout{
+		URL:        checkoutURL.String(),
+		ExternalID: "fake-" + req.PaymentID,
+	}, nil
+}
+
+func (p *FakeProvider) ParseCallback(body []byte, header http.Header) (*Callback, error) {
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.mac(body)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var cb fakeCallbackBody
+	if err := json.Unmarshal(body, &cb); err != nil || cb.PaymentID == "" {
+		return nil, ErrMalformedCallback
+	}
+	switch cb.Status {
+	case StatusPending, StatusSucceeded, StatusFailed:
+	default:
+		return nil, ErrMalformedCallback
+	}
+
+	return &Callback{
+		PaymentID:  cb.PaymentID,
+		ExternalID: cb.ExternalID,
+		Status:     cb.Status,
+		Amount:     cb.Amount,
+		Currency:   cb.Currency,
+		Reason:     cb.Reason,
+	}, nil
+}
+
+// SignCallback returns the body and signature header value of a
+// callback reporting cb, as the fake gateway would send it.
+func (p *FakeProvider) SignCallback(cb Callback) ([]byte, string, error) {
+	body, err := json.Marshal(fakeCallbackBody{
+		PaymentID:  cb.PaymentID,
+		ExternalID: cb.ExternalID,
+		Status:     cb.Status,
+		Amount:     cb.Amount,
+		Currency:   cb.Currency,
+		Reason:     cb.Reason,
+	})
+	if err != nil {
+		return nil, "", ErrProvider(fakeName, err)
+	}
+	return body, hex.EncodeToString(p.mac(body)), nil
+}
+
+func (p *FakeProvider) mac(body []byte) []byte {
+	m := hmac.New(sha256.New, p.secret)
+	m.Write(body)
+	return m.Sum(nil)
+}
@@ -0,0 +1,84 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+
+	"caviar/internal/config"
+)
+
+const (
+	ProviderLiqPay = liqPayName
+	ProviderFake   = fakeName
+)
+
+// Status is the state of a payment as reported by the provider.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// CheckoutRequest describes a payment to collect from the customer.
+// Amount is in whole currency units, like order totals.
+type CheckoutRequest struct {
+	PaymentID   string
+	Amount      int
+	Currency    string
+	Description string
+	Language    string
+	// ResultURL is where the customer returns after paying.
+	ResultURL string
+	// CallbackURL receives the provider's signed status callbacks.
+	CallbackURL string
+}
+
+// Checkout is a hosted payment page the customer is sent to.
+type Checkout struct {
+	URL string
+	// ExternalID is the provider's payment ID, when it is known before
+	// the first callback.
+	ExternalID string
+}
+
+// Callback is a verified status notification from the provider.
+type Callback struct {
+	PaymentID  string
+	ExternalID string
+	Status     Status
+	Amount     int
+	Currency   string
+	// Reason explains a failed payment.
+	Reason string
+}
+
+// Provider is an adapter for a payment gateway with a hosted checkout
+// page and signed server-to-server callbacks.
+type Provider interface {
+	Name() string
+	CreateCheckout(ctx context.Context, req CheckoutRequest) (*Checkout, error)
+	// ParseCallback verifies the signature of a callback request and
+	// decodes it. It returns ErrInvalidSignature for forged requests.
+	ParseCallback(body []byte, header http.Header) (*Callback, error)
+}
+
+// New builds the provider selected in cfg. The fake provider lets
+// anyone holding its secret mark orders paid, so it is refused in
+// production.
+func New(cfg config.Payment, isProd bool) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderLiqPay:
+		return NewLiqPayProvider(cfg.LiqPayPublicKey, cfg.LiqPayPrivateKey, cfg.LiqPaySandbox)
+	case ProviderFake:
+		if isProd {
+			return nil, ErrInvalidConfig("the fake provider cannot be used in production")
+		}
+		return NewFakeProvider(cfg.FakeSecret)
+	case "":
+		return nil, ErrInvalidConfig("provider is required")
+	default:
+		return nil, ErrInvalidConfig("unknown provider " + cfg.Provider)
+	}
+}
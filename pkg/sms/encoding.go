@@ -0,0 +1,114 @@
+package sms
+
+import "strings"
+
+const (
+	gsmSingleLimit = 160
+	gsmMultiLimit  = 153
+	ucsSingleLimit = 70
+	ucsMultiLimit  = 67
+
+	ellipsis = "..."
+)
+
+// gsmBasic is the GSM 03.38 basic character set.
+const gsmBasic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsmExtended characters take two septets (escape + char).
+const gsmExtended = "^{}\\[~]|€\f"
+
+// IsGSM7 reports whether text can be sent using the GSM-7 alphabet.
+func IsGSM7(text string) bool {
+	for _, r := range text {
+		if !strings.ContainsRune(gsmBasic, r) && !strings.ContainsRune(gsmExtended, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Length returns the number of encoding units text occupies: septets for
+// GSM-7 and UTF-16 code units for UCS-2.
+func Length(text string) int {
+	if IsGSM7(text) {
+		n := 0
+		for _, r := range text {
+			n += gsmUnits(r)
+		}
+		return n
+	}
+
+	n := 0
+	for _, r := range text {
+		n += ucsUnits(r)
+	}
+	return n
+}
+
+// Segments returns the number of SMS parts needed to deliver text.
+func Segments(text string) int {
+	length := Length(text)
+	if length == 0 {
+		return 0
+	}
+
+	single, multi := limits(IsGSM7(text))
+	if length <= single {
+		return 1
+	}
+	return (length + multi - 1) / multi
+}
+
+// Truncate shortens text so that it fits into maxSegments parts,
+// marking the cut with an ellipsis.
+func Truncate(text string, maxSegments int) string {
+	if maxSegments <= 0 || Segments(text) <= maxSegments {
+		return text
+	}
+
+	gsm := IsGSM7(text)
+	single, multi := limits(gsm)
+	budget := single
+	if maxSegments > 1 {
+		budget = multi * maxSegments
+	}
+	budget -= len(ellipsis)
+
+	var sb strings.Builder
+	used := 0
+	for _, r := range text {
+		units := ucsUnits(r)
+		if gsm {
+			units = gsmUnits(r)
+		}
+		if used+units > budget {
+			break
+		}
+		used += units
+		sb.WriteRune(r)
+	}
+
+	return strings.TrimRight(sb.String(), " ") + ellipsis
+}
+
+func limits(gsm bool) (single, multi int) {
+	if gsm {
+		return gsmSingleLimit, gsmMultiLimit
+	}
+	return ucsSingleLimit, ucsMultiLimit
+}
+
+func gsmUnits(r rune) int {
+	if strings.ContainsRune(gsmExtended, r) {
+		return 2
+	}
+	return 1
+}
+
+func ucsUnits(r rune) int {
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,22 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Common sms package errors
+var (
+	ErrInvalidPhone = errors.New("invalid phone number")
+	ErrEmptyMessage = errors.New("empty message")
+)
+
+// ErrInvalidConfig creates a configuration validation error
+func ErrInvalidConfig(message string) error {
+	return fmt.Errorf("invalid sms config: %s", message)
+}
+
+// ErrProvider creates a provider delivery error
+func ErrProvider(provider string, err error) error {
+	return fmt.Errorf("sms provider %s error: %w", provider, err)
+}
@@ -0,0 +1,78 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const logProviderName = "log"
+
+// LogProvider is a fake provider for development and tests. It never
+// contacts a gateway: messages are written to the logger and, when a
+// path is configured, appended to a JSON-lines file.
+type LogProvider struct {
+	path   string
+	logger *zap.Logger
+	mu     sync.Mutex
+}
+
+type loggedMessage struct {
+	Phone    string    `json:"phone"`
+	Text     string    `json:"text"`
+	Segments int       `json:"segments"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+func NewLogProvider(path string, logger *zap.Logger) *LogProvider {
+	return &LogProvider{
+		path:   path,
+		logger: logger,
+	}
+}
+
+func (p *LogProvider) Name() string {
+	return logProviderName
+}
+
+func (p *LogProvider) Send(ctx context.Context, phone, text string) error {
+	msg := loggedMessage{
+		Phone:    phone,
+		Text:     text,
+		Segments: Segments(text),
+		SentAt:   time.Now(),
+	}
+
+	p.logger.Info("SMS message (log provider)",
+		zap.String("phone", msg.Phone),
+		zap.Int("segments", msg.Segments),
+		zap.String("text", msg.Text))
+
+	if p.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return ErrProvider(logProviderName, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return ErrProvider(logProviderName, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return ErrProvider(logProviderName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package sms
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"caviar/internal/config"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ProviderTurboSMS = turboSMSName
+	ProviderLog      = logProviderName
+)
+
+// Provider is a transport adapter for a concrete SMS gateway.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, phone, text string) error
+}
+
+// Client prepares messages (phone normalization, transliteration,
+// length limits) and hands them to the configured Provider.
+type Client struct {
+	provider      Provider
+	transliterate bool
+	maxSegments   int
+	logger        *zap.Logger
+}
+
+func NewClient(provider Provider, transliterate bool, maxSegments int, logger *zap.Logger) *Client {
+	return &Client{
+		provider:      provider,
+		transliterate: transliterate,
+		maxSegments:   maxSegments,
+		logger:        logger,
+	}
+}
+
+// New builds a Client with the provider selected in cfg.
+func New(cfg config.SMS, logger *zap.Logger) (*Client, error) {
+	var provider Provider
+
+	switch cfg.Provider {
+	case ProviderTurboSMS:
+		p, err := NewTurboSMSProvider(cfg.APIURL, cfg.Token, cfg.Sender, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	case ProviderLog, "":
+		provider = NewLogProvider(cfg.LogPath, logger)
+	default:
+		return nil, ErrInvalidConfig("unknown provider " + cfg.Provider)
+	}
+
+	return NewClient(provider, cfg.Transliterate, cfg.MaxSegments, logger), nil
+}
+
+func (c *Client) Send(ctx context.Context, phone, text string) error {
+	normalized, err := NormalizePhone(phone)
+	if err != nil {
+		return err
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrEmptyMessage
+	}
+
+	if c.transliterate {
+		text = Transliterate(text)
+	}
+	text = Truncate(text, c.maxSegments)
+
+	c.logger.Debug("Sending SMS",
+		zap.String("provider", c.provider.Name()),
+		zap.String("phone", normalized),
+		zap.Int("segments", Segments(text)))
+
+	return c.provider.Send(ctx, normalized, text)
+}
+
+// NormalizePhone converts a phone number to international format
+// without the leading plus sign. Local Ukrainian numbers (0XXXXXXXXX)
+// get the 380 country code.
+func NormalizePhone(phone string) (string, error) {
+	var sb strings.Builder
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	digits := sb.String()
+
+	if len(digits) == 10 && strings.HasPrefix(digits, "0") {
+		digits = "38" + digits
+	}
+
+	if len(digits) < 10 || len(digits) > 15 {
+		return "", ErrInvalidPhone
+	}
+
+	return digits, nil
+}
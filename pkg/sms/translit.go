@@ -0,0 +1,97 @@
+package sms
+
+import (
+	"strings"
+	"unicode"
+)
+
+// translitTable follows the official Ukrainian transliteration
+// (Cabinet of Ministers resolution No. 55, 2010) for positions
+// inside a word.
+var translitTable = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "e",
+	'є': "ie", 'ж': "zh", 'з': "z", 'и': "y", 'і': "i", 'ї': "i", 'й': "i",
+	'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "shch", 'ь': "", 'ю': "iu", 'я': "ia",
+	// Russian-only letters that show up in customer names and addresses
+	'ё': "io", 'ы': "y", 'э': "e", 'ъ': "",
+	// typographic characters outside of GSM-7
+	'’': "", 'ʼ': "", '«': "\"", '»': "\"", '—': "-", '–': "-", '№': "N",
+}
+
+// translitWordStart holds the letters that are spelled differently
+// at the beginning of a word.
+var translitWordStart = map[rune]string{
+	'є': "ye", 'ї': "yi", 'й': "y", 'ю': "yu", 'я': "ya",
+}
+
+// Transliterate converts Cyrillic text to Latin so that it can be sent
+// using the cheaper GSM-7 encoding.
+func Transliterate(text string) string {
+	runes := []rune(text)
+
+	var sb strings.Builder
+	sb.Grow(len(text))
+
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+
+		// "зг" is spelled "zgh" to distinguish it from "ж"
+		if lower == 'г' && i > 0 && unicode.ToLower(runes[i-1]) == 'з' {
+			sb.WriteString(matchCase("gh", r, runes, i))
+			continue
+		}
+
+		latin, ok := translitTable[lower]
+		if !ok {
+			if r == '\'' {
+				continue
+			}
+			sb.WriteRune(r)
+			continue
+		}
+
+		if start, ok := translitWordStart[lower]; ok && isWordStart(runes, i) {
+			latin = start
+		}
+
+		sb.WriteString(matchCase(latin, r, runes, i))
+	}
+
+	return sb.String()
+}
+
+func isWordStart(runes []rune, i int) bool {
+	return i == 0 || !unicode.IsLetter(runes[i-1]) && runes[i-1] != '\'' && runes[i-1] != '’' && runes[i-1] != 'ʼ'
+}
+
+// matchCase capitalizes latin the way the original rune was written:
+// "Щ" becomes "Shch" inside mixed-case words and "SHCH" inside all-caps ones.
+func matchCase(latin string, original rune, runes []rune, i int) string {
+	if latin == "" || !unicode.IsUpper(original) {
+		return latin
+	}
+
+	if isAllCaps(runes, i) {
+		return strings.ToUpper(latin)
+	}
+	return strings.ToUpper(latin[:1]) + latin[1:]
+}
+
+func isAllCaps(runes []rune, i int) bool {
+	neighbour := func(j int) (rune, bool) {
+		if j < 0 || j >= len(runes) || !unicode.IsLetter(runes[j]) {
+			return 0, false
+		}
+		return runes[j], true
+	}
+
+	if r, ok := neighbour(i + 1); ok {
+		return unicode.IsUpper(r)
+	}
+	if r, ok := neighbour(i - 1); ok {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
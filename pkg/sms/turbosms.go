@@ -0,0 +1,107 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	turboSMSName     = "turbosms"
+	turboSMSSendPath = "/message/send.json"
+)
+
+// TurboSMS response codes that mean the message was accepted
+// (see https://turbosms.ua/api.html).
+var turboSMSAccepted = map[int]bool{0: true, 800: true, 801: true, 802: true, 803: true}
+
+// TurboSMSProvider sends messages through the TurboSMS HTTP API.
+type TurboSMSProvider struct {
+	baseURL    string
+	token      string
+	sender     string
+	httpClient *http.Client
+}
+
+type turboSMSRequest struct {
+	Recipients []string        `json:"recipients"`
+	SMS        turboSMSMessage `json:"sms"`
+}
+
+type turboSMSMessage struct {
+	Sender string `json:"sender"`
+	Text   string `json:"text"`
+}
+
+type turboSMSResponse struct {
+	ResponseCode   int    `json:"response_code"`
+	ResponseStatus string `json:"response_status"`
+}
+
+func NewTurboSMSProvider(baseURL, token, sender string, timeout time.Duration) (*TurboSMSProvider, error) {
+	if baseURL == "" {
+		return nil, ErrInvalidConfig("api url is required for turbosms")
+	}
+	if token == "" {
+		return nil, ErrInvalidConfig("token is required for turbosms")
+	}
+	if sender == "" {
+		return nil, ErrInvalidConfig("sender is required for turbosms")
+	}
+
+	return &TurboSMSProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		sender:     sender,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (p *TurboSMSProvider) Name() string {
+	return turboSMSName
+}
+
+func (p *TurboSMSProvider) Send(ctx context.Context, phone, text string) error {
+	body, err := json.Marshal(turboSMSRequest{
+		Recipients: []string{phone},
+		SMS: turboSMSMessage{
+			Sender: p.sender,
+			Text:   text,
+		},
+	})
+	if err != nil {
+		return ErrProvider(turboSMSName, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+turboSMSSendPath, bytes.NewReader(body))
+	if err != nil {
+		return ErrProvider(turboSMSName, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return ErrProvider(turboSMSName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrProvider(turboSMSName, fmt.Errorf("unexpected HTTP status %d", resp.StatusCode))
+	}
+
+	var result turboSMSResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ErrProvider(turboSMSName, fmt.Errorf("cannot decode response: %w", err))
+	}
+
+	if !turboSMSAccepted[result.ResponseCode] {
+		return ErrProvider(turboSMSName, fmt.Errorf("code %d: %s", result.ResponseCode, result.ResponseStatus))
+	}
+
+	return nil
+}
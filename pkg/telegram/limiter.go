@@ -0,0 +1,321 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v4"
+)
+
+// Priority controls the order in which queued messages are sent.
+// Values mirror the notification service priorities.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+
+	priorityLevels = int(PriorityUrgent) + 1
+)
+
+const (
+	chatBucketIdleTTL = 10 * time.Minute
+	evictInterval     = time.Minute
+	maxIdleWait       = time.Second
+)
+
+// tokenBucket is a classic token bucket refilled continuously at rate
+// tokens per second up to burst.
+type tokenBucket struct {
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	lastUsed time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     now,
+		lastUsed: now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns how long to wait until a token is available.
+func (b *tokenBucket) wait(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+	b.lastUsed = now
+}
+
+type sendJob struct {
+	ctx      context.Context
+	chatID   int64
+	message  string
+	opts     *tele.SendOptions
+	attempts int
+	done     chan error
+}
+
+// LimiterConfig describes Telegram Bot API limits.
+type LimiterConfig struct {
+	GlobalRate   float64
+	GlobalBurst  int
+	PerChatRate  float64
+	PerChatBurst int
+	MaxRetries   int
+}
+
+// validate rejects limits the token buckets cannot work with: a rate of
+// zero would wait forever for a token, and a burst below one never holds
+// a whole token.
+func (c LimiterConfig) validate() error {
+	if !(c.GlobalRate > 0) || math.IsInf(c.GlobalRate, 0) {
+		return ErrInvalidConfig("global rate must be greater than 0")
+	}
+	if !(c.PerChatRate > 0) || math.IsInf(c.PerChatRate, 0) {
+		return ErrInvalidConfig("per chat rate must be greater than 0")
+	}
+	if c.GlobalBurst < 1 || c.PerChatBurst < 1 {
+		return ErrInvalidConfig("bursts must be at least 1")
+	}
+	if c.MaxRetries < 0 {
+		return ErrInvalidConfig("max retries cannot be negative")
+	}
+	return nil
+}
+
+// dispatcher serializes all outgoing messages through a global and a
+// per-chat token bucket. Jobs are taken from the highest priority queue
+// whose chat is ready, and flood-wait (429) responses pause sending for
+// the interval Telegram asks for before the job is retried.
+type dispatcher struct {
+	send   func(chatID int64, message string, opts *tele.SendOptions) error
+	cfg    LimiterConfig
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	queues      [priorityLevels][]*sendJob
+	global      *tokenBucket
+	chats       map[int64]*tokenBucket
+	lastEvict   time.Time
+	pausedUntil time.Time
+
+	wake chan struct{}
+	stop chan struct{}
+	once sync.Once
+}
+
+func newDispatcher(
+	send func(chatID int64, message string, opts *tele.SendOptions) error,
+	cfg LimiterConfig,
+	logger *zap.Logger,
+) *dispatcher {
+	now := time.Now()
+	d := &dispatcher{
+		send:   send,
+		cfg:    cfg,
+		logger: logger,
+		global: newTokenBucket(cfg.GlobalRate, cfg.GlobalBurst, now),
+		chats:  make(map[int64]*tokenBucket),
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+
+	go d.run()
+
+	return d
+}
+
+// enqueue adds a message to the queue and blocks until it is sent,
+// fails permanently, or ctx is cancelled.
+func (d *dispatcher) enqueue(ctx context.Context, chatID int64, message string, opts *tele.SendOptions, priority Priority) error {
+	if priority < PriorityLow {
+		priority = PriorityLow
+	}
+	if priority > PriorityUrgent {
+		priority = PriorityUrgent
+	}
+
+	job := &sendJob{
+		ctx:     ctx,
+		chatID:  chatID,
+		message: message,
+		opts:    opts,
+		done:    make(chan error, 1),
+	}
+
+	d.mu.Lock()
+	d.queues[priority] = append(d.queues[priority], job)
+	d.mu.Unlock()
+	d.signal()
+
+	select {
+	case err := <-job.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.stop:
+		return errors.New("telegram dispatcher stopped")
+	}
+}
+
+func (d *dispatcher) close() {
+	d.once.Do(func() { close(d.stop) })
+}
+
+func (d *dispatcher) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *dispatcher) run() {
+	for {
+		job, wait := d.next(time.Now())
+		if job == nil {
+			if wait <= 0 || wait > maxIdleWait {
+				wait = maxIdleWait
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-d.stop:
+				timer.Stop()
+				return
+			case <-d.wake:
+			case <-timer.C:
+			}
+			timer.Stop()
+			continue
+		}
+
+		d.deliver(job)
+	}
+}
+
+// next pops the first ready job, scanning queues from the highest
+// priority down. When nothing is ready it returns how long to wait.
+func (d *dispatcher) next(now time.Time) (*sendJob, time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.evictIdleChats(now)
+
+	if now.Before(d.pausedUntil) {
+		return nil, d.pausedUntil.Sub(now)
+	}
+
+	if wait := d.global.wait(now); wait > 0 {
+		return nil, wait
+	}
+
+	minWait := time.Duration(0)
+	for p := priorityLevels - 1; p >= 0; p-- {
+		queue := d.queues[p]
+		for i, job := range queue {
+			if job.ctx.Err() != nil {
+				d.queues[p] = append(queue[:i:i], queue[i+1:]...)
+				job.done <- job.ctx.Err()
+				return nil, 0
+			}
+
+			bucket := d.chatBucket(job.chatID, now)
+			if wait := bucket.wait(now); wait > 0 {
+				if minWait == 0 || wait < minWait {
+					minWait = wait
+				}
+				continue
+			}
+
+			d.queues[p] = append(queue[:i:i], queue[i+1:]...)
+			d.global.take(now)
+			bucket.take(now)
+			return job, 0
+		}
+	}
+
+	return nil, minWait
+}
+
+func (d *dispatcher) deliver(job *sendJob) {
+	err := d.send(job.chatID, job.message, job.opts)
+	if err == nil {
+		job.done <- nil
+		return
+	}
+
+	var flood tele.FloodError
+	if errors.As(err, &flood) && job.attempts < d.cfg.MaxRetries {
+		retryAfter := time.Duration(flood.RetryAfter) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+
+		d.logger.Warn("Telegram rate limit hit, pausing sends",
+			zap.Int64("chat_id", job.chatID),
+			zap.Duration("retry_after", retryAfter),
+			zap.Int("attempt", job.attempts+1))
+
+		job.attempts++
+
+		d.mu.Lock()
+		if until := time.Now().Add(retryAfter); until.After(d.pausedUntil) {
+			d.pausedUntil = until
+		}
+		// Retried jobs go back to the front of the most urgent queue so
+		// they keep their place ahead of messages queued after them.
+		d.queues[PriorityUrgent] = append([]*sendJob{job}, d.queues[PriorityUrgent]...)
+		d.mu.Unlock()
+		return
+	}
+
+	job.done <- ErrTelegramAPI("send message", err)
+}
+
+func (d *dispatcher) chatBucket(chatID int64, now time.Time) *tokenBucket {
+	bucket, ok := d.chats[chatID]
+	if !ok {
+		bucket = newTokenBucket(d.cfg.PerChatRate, d.cfg.PerChatBurst, now)
+		d.chats[chatID] = bucket
+	}
+	return bucket
+}
+
+func (d *dispatcher) evictIdleChats(now time.Time) {
+	if now.Sub(d.lastEvict) < evictInterval {
+		return
+	}
+	d.lastEvict = now
+
+	for chatID, bucket := range d.chats {
+		if now.Sub(bucket.lastUsed) > chatBucketIdleTTL {
+			delete(d.chats, chatID)
+		}
+	}
+}
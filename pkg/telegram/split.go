@@ -0,0 +1,184 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxMessageLength is the Bot API limit for a single text message, in
+// UTF-16 code units.
+const maxMessageLength = 4096
+
+// messageLength returns the length of text as the Bot API counts it: in
+// UTF-16 code units, so characters outside the BMP such as most emoji
+// count twice.
+func messageLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// htmlToken is a piece of an HTML message that cannot be cut: a tag, an
+// entity such as &amp;, or a single character.
+type htmlToken struct {
+	text   string
+	length int
+	// open is the name of the element a start tag opens, close the name
+	// of the element an end tag closes.
+	open, close string
+}
+
+// openTag is an element left open at a cut, with the start tag that
+// reopens it in the next part.
+type openTag struct {
+	name  string
+	start string
+}
+
+// splitMessage breaks an HTML message into parts of at most limit UTF-16
+// code units. Parts end on a line break when they can, on a space
+// otherwise, and only mid-word as a last resort; tags and entities are
+// never cut. Elements open at a cut are closed at the end of the part
+// and reopened at the start of the next, so every part parses on its
+// own.
+func splitMessage(text string, limit int) []string {
+	if messageLength(text) <= limit {
+		return []string{text}
+	}
+
+	tokens := tokenizeHTML(text)
+	var parts []string
+	var stack []openTag
+	for i := 0; i < len(tokens); {
+		var head strings.Builder
+		for _, tag := range stack {
+			head.WriteString(tag.start)
+		}
+		length := messageLength(head.String())
+
+		current := append([]openTag(nil), stack...)
+		lineCut, lineStack := -1, current
+		spaceCut, spaceStack := -1, current
+		j := i
+		for ; j < len(tokens); j++ {
+			next := pushToken(current, tokens[j])
+			if j > i && length+tokens[j].length+closingLength(next) > limit {
+				break
+			}
+			current = next
+			length += tokens[j].length
+			switch tokens[j].text {
+			case "\n":
+				lineCut, lineStack = j+1, current
+			case " ":
+				spaceCut, spaceStack = j+1, current
+			}
+		}
+
+		var cut int
+		var cutStack []openTag
+		switch {
+		case j == len(tokens):
+			cut, cutStack = j, current
+		case lineCut > i:
+			cut, cutStack = lineCut, lineStack
+		case spaceCut > i:
+			cut, cutStack = spaceCut, spaceStack
+		default:
+			cut, cutStack = j, current
+		}
+
+		var body strings.Builder
+		for _, t := range tokens[i:cut] {
+			body.WriteString(t.text)
+		}
+		if content := strings.TrimSpace(body.String()); content != "" {
+			head.WriteString(content)
+			for k := len(cutStack) - 1; k >= 0; k-- {
+				head.WriteString("</" + cutStack[k].name + ">")
+			}
+			parts = append(parts, head.String())
+		}
+
+		stack, i = cutStack, cut
+	}
+	return parts
+}
+
+// tokenizeHTML splits text into tags, entities and characters. A '<' or
+// '&' that does not start a well-formed tag or entity is a character.
+func tokenizeHTML(text string) []htmlToken {
+	var tokens []htmlToken
+	for i := 0; i < len(text); {
+		var t htmlToken
+		switch text[i] {
+		case '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				t = parseTag(text[i : i+end+1])
+			}
+		case '&':
+			if end := strings.IndexByte(text[i:], ';'); end > 1 && end <= 10 && isEntityName(text[i+1:i+end]) {
+				t = htmlToken{text: text[i : i+end+1]}
+			}
+		}
+		if t.text == "" {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			t = htmlToken{text: text[i : i+size]}
+		}
+		t.length = messageLength(t.text)
+		tokens = append(tokens, t)
+		i += len(t.text)
+	}
+	return tokens
+}
+
+func parseTag(tag string) htmlToken {
+	t := htmlToken{text: tag}
+	inner := strings.TrimSuffix(strings.TrimPrefix(tag, "<"), ">")
+	if name, ok := strings.CutPrefix(inner, "/"); ok {
+		t.close = strings.ToLower(strings.TrimSpace(name))
+		return t
+	}
+	if strings.HasSuffix(inner, "/") {
+		return t
+	}
+	name, _, _ := strings.Cut(inner, " ")
+	t.open = strings.ToLower(strings.TrimSpace(name))
+	return t
+}
+
+func isEntityName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '#' && i == 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// pushToken returns the elements open after t.
+func pushToken(stack []openTag, t htmlToken) []openTag {
+	switch {
+	case t.open != "":
+		next := append([]openTag(nil), stack...)
+		return append(next, openTag{name: t.open, start: t.text})
+	case t.close != "":
+		for k := len(stack) - 1; k >= 0; k-- {
+			if stack[k].name == t.close {
+				return append([]openTag(nil), stack[:k]...)
+			}
+		}
+	}
+	return stack
+}
+
+// closingLength returns the length of the end tags closing stack.
+func closingLength(stack []openTag) int {
+	var length int
+	for _, tag := range stack {
+		length += len("</" + tag.name + ">")
+	}
+	return length
+}
@@ -0,0 +1,125 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessageLength(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"привіт", 6},
+		{"😀", 2},
+		{"a😀b", 4},
+	}
+	for _, tt := range tests {
+		if got := messageLength(tt.text); got != tt.want {
+			t.Errorf("messageLength(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "fits",
+			text:  "<b>hello</b>\nworld",
+			limit: 100,
+			want:  []string{"<b>hello</b>\nworld"},
+		},
+		{
+			name:  "cuts on line breaks",
+			text:  "first line\nsecond line\nthird line",
+			limit: 24,
+			want:  []string{"first line\nsecond line", "third line"},
+		},
+		{
+			name:  "cuts on spaces in a long line",
+			text:  "one two three four",
+			limit: 9,
+			want:  []string{"one two", "three", "four"},
+		},
+		{
+			name:  "closes and reopens elements",
+			text:  "<b>bold text here</b>",
+			limit: 17,
+			want:  []string{"<b>bold text</b>", "<b>here</b>"},
+		},
+		{
+			name:  "reopens links with their attributes",
+			text:  `<a href="https://x.io">aa bb</a>`,
+			limit: 30,
+			want:  []string{`<a href="https://x.io">aa</a>`, `<a href="https://x.io">bb</a>`},
+		},
+		{
+			name:  "keeps entities whole",
+			text:  "&amp;&amp;&amp;",
+			limit: 11,
+			want:  []string{"&amp;&amp;", "&amp;"},
+		},
+		{
+			name:  "counts emoji twice",
+			text:  "😀😀😀",
+			limit: 4,
+			want:  []string{"😀😀", "😀"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.text, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitMessageParts(t *testing.T) {
+	texts := []string{
+		strings.Repeat("<b>Замовлення</b> &amp; <code>ORD-1</code> 😀 ", 300),
+		"<pre><code>" + strings.Repeat("x", 10000) + "</code></pre>",
+		strings.Repeat("<i>line</i>\n", 2000),
+		"<b>" + strings.Repeat("a <u>b</u> c\n", 1500) + "</b>",
+	}
+	for _, text := range texts {
+		for _, part := range splitMessage(text, maxMessageLength) {
+			if n := messageLength(part); n > maxMessageLength {
+				t.Fatalf("part of %d units exceeds the limit", n)
+			}
+			if err := checkBalanced(part); err != "" {
+				t.Fatalf("part is not well formed: %s", err)
+			}
+		}
+	}
+}
+
+// checkBalanced reports the first unbalanced tag or cut entity of part.
+func checkBalanced(part string) string {
+	var open []string
+	for _, tok := range tokenizeHTML(part) {
+		switch {
+		case tok.open != "":
+			open = append(open, tok.open)
+		case tok.close != "":
+			if len(open) == 0 || open[len(open)-1] != tok.close {
+				return "unexpected </" + tok.close + ">"
+			}
+			open = open[:len(open)-1]
+		case tok.text == "<" || tok.text == ">" || tok.text == "&":
+			return "stray " + tok.text
+		}
+	}
+	if len(open) > 0 {
+		return "unclosed <" + open[len(open)-1] + ">"
+	}
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,10 +23,13 @@ type otpStore struct {
 
 type UserStorage interface {
     GetByTelegramID(ctx context.Context, telegramID string) (*models.User, error)
+    UpdateLanguage(ctx context.Context, telegramID string, language string) error
 }
 
 type Service struct {
     bot         *tele.Bot
+    dispatcher  *dispatcher
+    webhook     *WebhookConfig
     store       *otpStore
     userStorage UserStorage
     logger      *zap.Logger
@@ -51,12 +55,26 @@ func newOTPStore() *otpStore {
 
 func NewService(
 	token string,
+	limits LimiterConfig,
+	webhook *WebhookConfig,
 	storage UserStorage,
 	logger *zap.Logger,
 ) (*Service, error) {
+    if err := limits.validate(); err != nil {
+        return nil, err
+    }
+
+    var poller tele.Poller = &tele.LongPoller{Timeout: 10 * time.Second}
+    if webhook != nil {
+        if err := webhook.validate(); err != nil {
+            return nil, err
+        }
+        poller = &webhookPoller{cfg: *webhook, logger: logger}
+    }
+
     bot, err := tele.NewBot(tele.Settings{
         Token:  token,
-        Poller: &tele.LongPoller{Timeout: 10 * time.Second},
+        Poller: poller,
     })
     if err != nil {
         return nil, err
@@ -64,10 +82,12 @@ func NewService(
 
     s := &Service{
         bot:         bot,
+        webhook:     webhook,
         store:       newOTPStore(),
         userStorage: storage,
         logger:      logger,
     }
+    s.dispatcher = newDispatcher(s.send, limits, logger)
 
     s.setupHandlers()
 
@@ -119,29 +139,47 @@ func (s *otpStore) cleanup() {
 func (s *Service) setupHandlers() {
     s.bot.Handle("/start", s.handleStart)
     s.bot.Handle("/login", s.handleLogin)
+    s.bot.Handle("/language", s.handleLanguage)
 }
 
 func (s *Service) Start(ctx context.Context) {
     go s.bot.Start()
     <-ctx.Done()
     s.bot.Stop()
+    s.dispatcher.close()
 }
 
 func (s *Service) handleStart(c tele.Context) error {
-    return c.Send("👋 Welcome! Use /login to get your login code.")
+    return s.dispatcher.enqueue(context.Background(), c.Chat().ID, "👋 Welcome! Use /login to get your login code.", nil, PriorityNormal)
 }
 
 func (s *Service) handleLogin(c tele.Context) error {
     id := c.Sender().ID
 
     if _, err := s.userStorage.GetByTelegramID(context.Background(), strconv.FormatInt(id, 10)); err != nil {
-        return c.Send("❌ You're not registered. Contact admin to link your account.")
+        return s.dispatcher.enqueue(context.Background(), c.Chat().ID, "❌ You're not registered. Contact admin to link your account.", nil, PriorityHigh)
     }
 
     code := s.generateCode(strconv.FormatInt(id, 10))
     message := fmt.Sprintf("🔐 Your login code: <code>%s</code>\n⏰ Expires in 1 hour", code)
 
-    return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeHTML})
+    return s.dispatcher.enqueue(context.Background(), c.Chat().ID, message, &tele.SendOptions{ParseMode: tele.ModeHTML}, PriorityUrgent)
+}
+
+func (s *Service) handleLanguage(c tele.Context) error {
+    id := strconv.FormatInt(c.Sender().ID, 10)
+    args := c.Args()
+
+    if len(args) != 1 || !models.IsSupportedLocale(args[0]) {
+        message := fmt.Sprintf("🌐 Usage: /language <code>\nAvailable: %s", strings.Join(models.SupportedLocales, ", "))
+        return s.dispatcher.enqueue(context.Background(), c.Chat().ID, message, nil, PriorityNormal)
+    }
+
+    if err := s.userStorage.UpdateLanguage(context.Background(), id, args[0]); err != nil {
+        return s.dispatcher.enqueue(context.Background(), c.Chat().ID, "❌ You're not registered. Contact admin to link your account.", nil, PriorityNormal)
+    }
+
+    return s.dispatcher.enqueue(context.Background(), c.Chat().ID, "✅ Notification language set to "+args[0], nil, PriorityNormal)
 }
 
 func (s *Service) RequestOTP(ctx context.Context, telegramID string) error {
@@ -153,8 +191,7 @@ func (s *Service) RequestOTP(ctx context.Context, telegramID string) error {
     message := fmt.Sprintf("🔐 Your login code: <code>%s</code>\n⏰ Expires in 1 hour", code)
 
     telegramIDInt, _ := strconv.ParseInt(telegramID, 10, 64)
-    _, err := s.bot.Send(&tele.User{ID: telegramIDInt}, message, &tele.SendOptions{ParseMode: tele.ModeHTML})
-    return err
+    return s.dispatcher.enqueue(ctx, telegramIDInt, message, &tele.SendOptions{ParseMode: tele.ModeHTML}, PriorityUrgent)
 }
 
 func (s *Service) ValidateOTP(ctx context.Context, telegramID string, code string) (*models.User, error) {
@@ -199,30 +236,64 @@ func (s *Service) GetUserByTelegramID(ctx context.Context, telegramID string) (*
     }, nil
 }
 
-// SendMessage sends a message to a specific Telegram user
+// SendMessage sends a message to a specific Telegram user with normal priority
 func (s *Service) SendMessage(ctx context.Context, telegramID int64, message string) error {
-	_, err := s.bot.Send(&tele.User{ID: telegramID}, message, &tele.SendOptions{
-		ParseMode: tele.ModeHTML,
-	})
+	return s.SendMessageWithPriority(ctx, telegramID, message, int(PriorityNormal))
+}
+
+// SendMessageWithPriority queues a message behind the shared rate limiter;
+// higher priorities are sent before lower ones waiting in the queue.
+// Messages over the Bot API length limit are sent as several parts.
+func (s *Service) SendMessageWithPriority(ctx context.Context, telegramID int64, message string, priority int) error {
+	for _, part := range splitMessage(message, maxMessageLength) {
+		err := s.dispatcher.enqueue(ctx, telegramID, part, &tele.SendOptions{
+			ParseMode: tele.ModeHTML,
+		}, Priority(priority))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send performs the actual Bot API call; only the dispatcher calls it
+func (s *Service) send(chatID int64, message string, opts *tele.SendOptions) error {
+	var err error
+	if opts != nil {
+		_, err = s.bot.Send(tele.ChatID(chatID), message, opts)
+	} else {
+		_, err = s.bot.Send(tele.ChatID(chatID), message)
+	}
 	return err
 }
 
-// SendMessageToMultiple sends a message to multiple Telegram users
+// SendMessageToMultiple sends a message to multiple Telegram users;
+// pacing is left to the dispatcher
 func (s *Service) SendMessageToMultiple(ctx context.Context, telegramIDs []int64, message string) error {
-	var errors []error
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
 	
 	for _, telegramID := range telegramIDs {
-		err := s.SendMessage(ctx, telegramID, message)
-		if err != nil {
-			s.logger.Warn("Failed to send message to user",
-				zap.Int64("telegram_id", telegramID),
-				zap.Error(err))
-			errors = append(errors, err)
-		}
+		wg.Add(1)
+		go func(telegramID int64) {
+			defer wg.Done()
+			if err := s.SendMessage(ctx, telegramID, message); err != nil {
+				s.logger.Warn("Failed to send message to user",
+					zap.Int64("telegram_id", telegramID),
+					zap.Error(err))
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}(telegramID)
 	}
+	wg.Wait()
 	
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to send to %d users", len(errors))
+	if failed > 0 {
+		return fmt.Errorf("failed to send to %d users", failed)
 	}
 	
 	return nil
@@ -0,0 +1,100 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v4"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookConfig switches the bot from long polling to webhook mode.
+// Updates are then received through WebhookHandler, which the caller
+// mounts on its own HTTP server.
+type WebhookConfig struct {
+	// PublicURL is the full URL Telegram posts updates to.
+	PublicURL string
+	// SecretToken is echoed back by Telegram in every update request.
+	SecretToken string
+	// Register makes this instance call setWebhook on start. Only one
+	// replica should have it set, the others just serve the handler.
+	Register bool
+}
+
+func (c *WebhookConfig) validate() error {
+	if c.PublicURL == "" {
+		return ErrInvalidConfig("webhook public url is required")
+	}
+	if !strings.HasPrefix(c.PublicURL, "https://") {
+		return ErrInvalidConfig("webhook public url must use https")
+	}
+	if c.SecretToken == "" {
+		return ErrInvalidConfig("webhook secret token is required")
+	}
+	return nil
+}
+
+// webhookPoller registers the webhook (when configured to) and then
+// idles until the bot is stopped. Updates never pass through it: the
+// HTTP handler hands them to the bot directly.
+type webhookPoller struct {
+	cfg    WebhookConfig
+	logger *zap.Logger
+}
+
+func (p *webhookPoller) Poll(b *tele.Bot, _ chan tele.Update, stop chan struct{}) {
+	if p.cfg.Register {
+		err := b.SetWebhook(&tele.Webhook{
+			SecretToken:    p.cfg.SecretToken,
+			AllowedUpdates: []string{"message"},
+			Endpoint:       &tele.WebhookEndpoint{PublicURL: p.cfg.PublicURL},
+		})
+		if err != nil {
+			p.logger.Error("Failed to register Telegram webhook", zap.Error(ErrTelegramAPI("set webhook", err)))
+		} else {
+			p.logger.Info("Telegram webhook registered")
+		}
+	}
+
+	<-stop
+}
+
+// WebhookHandler returns the HTTP handler for Telegram updates, or nil
+// when the service runs in long polling mode. Requests without the
+// configured secret token are rejected.
+func (s *Service) WebhookHandler() http.Handler {
+	if s.webhook == nil {
+		return nil
+	}
+
+	secret := []byte(s.webhook.SecretToken)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := []byte(r.Header.Get(secretTokenHeader))
+		if subtle.ConstantTimeCompare(token, secret) != 1 {
+			s.logger.Warn("Rejected Telegram webhook request with invalid secret token",
+				zap.String("ip", r.RemoteAddr))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var update tele.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			s.logger.Warn("Failed to decode Telegram update", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.bot.ProcessUpdate(update)
+		w.WriteHeader(http.StatusOK)
+	})
+}